@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"tradingViewWebhookBot/internal/api/bybit"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/orders"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// go run cmd/backfillFees/main.go
+// Fetches real execution fees and funding of closed round trips from Bybit and recalculates their profit.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	viper.AddConfigPath("internal/configs")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		logger.Fatal("Error loading config", zap.Error(err))
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	repos := repository.NewRepositories(db)
+	exchangeApi := bybit.NewBybitApi(os.Getenv("BYBIT_API_KEY"), os.Getenv("BYBIT_API_SECRET"))
+
+	updated, err := orders.NewFeeBackfillService(repos.Transaction, repos.Coin, exchangeApi).BackfillFees()
+	if err != nil {
+		logger.Fatal("Failed to backfill fees", zap.Int("updated", updated), zap.Error(err))
+	}
+
+	logger.Info("Fees backfilled", zap.Int("updated", updated))
+}
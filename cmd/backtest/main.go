@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+	"tradingViewWebhookBot/internal/api/bybit"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/repository/memory"
+	"tradingViewWebhookBot/internal/service/backtest"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// go run cmd/backtest/main.go -alerts alerts.jsonl -interval 15 -balance 1000
+// go run cmd/backtest/main.go -alerts alerts.jsonl -from 2024-01-01 -to 2024-02-01 -source exchange -out result.json
+// Replays alerts of the file (one alert json with "time" per line) against candles of the local store (see cmd/downloadKlines)
+// or candles downloaded from Bybit on the run.
+// Strategies, coins and aliases are copied from the database, trades are kept in memory only.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	alertsFile := flag.String("alerts", "", "file with alerts, one json per line")
+	interval := flag.String("interval", "15", "candle interval in minutes")
+	from := flag.String("from", "", "start date, inclusive (2006-01-02), the day of the first alert by default")
+	to := flag.String("to", "", "end date, exclusive (2006-01-02), the day after the last alert by default")
+	balance := flag.Float64("balance", 1000, "initial balance in USD")
+	source := flag.String("source", "db", "candles of the local store (db) or downloaded from Bybit (exchange)")
+	out := flag.String("out", "", "output file, stdout by default")
+	flag.Parse()
+
+	if *alertsFile == "" {
+		logger.Fatal("-alerts is required")
+	}
+	if *source != "db" && *source != "exchange" {
+		logger.Fatal("Invalid candle source", zap.String("source", *source))
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	viper.AddConfigPath("internal/configs")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		logger.Fatal("Error loading config", zap.Error(err))
+	}
+
+	file, err := os.Open(*alertsFile)
+	if err != nil {
+		logger.Fatal("Failed to open alerts file", zap.Error(err))
+	}
+	alerts, err := backtest.LoadAlerts(file)
+	file.Close()
+	if err != nil {
+		logger.Fatal("Failed to load alerts", zap.Error(err))
+	}
+	if len(alerts) == 0 {
+		logger.Fatal("No alerts in the file")
+	}
+
+	settings := backtest.Settings{
+		Interval:   *interval,
+		From:       parseDate(logger, *from, alerts[0].Time.Truncate(24*time.Hour)),
+		To:         parseDate(logger, *to, alerts[len(alerts)-1].Time.Truncate(24*time.Hour).AddDate(0, 0, 1)),
+		Balance:    *balance,
+		Commission: viper.GetFloat64("api.bybit.commission"),
+		Leverage:   viper.GetInt64("default.leverage"),
+		HedgeMode:  viper.GetBool("api.bybit.hedgeMode"),
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+	dbRepos := repository.NewRepositories(db)
+
+	var candleSource candles.CandleSource = candles.NewDatabaseCandleSource(dbRepos.Candle)
+	if *source == "exchange" {
+		candleSource = candles.NewExchangeCandleSource(candles.NewCandleDownloadService(dbRepos.Candle, bybit.NewBybitKlinesApi(),
+			date.GetClock(), viper.GetDuration("candles.requestDelay")))
+	}
+
+	repos, err := memory.CopyRepositories(dbRepos)
+	if err != nil {
+		logger.Fatal("Failed to copy repositories", zap.Error(err))
+	}
+	backtestService, err := backtest.NewBacktestService(repos, candleSource, date.NewClockMock(settings.From), settings)
+	if err != nil {
+		logger.Fatal("Invalid backtest settings", zap.Error(err))
+	}
+	result, err := backtestService.Run(alerts)
+	if err != nil {
+		logger.Fatal("Backtest failed", zap.Error(err))
+	}
+
+	writer := os.Stdout
+	if *out != "" {
+		outFile, err := os.Create(*out)
+		if err != nil {
+			logger.Fatal("Failed to create output file", zap.Error(err))
+		}
+		defer outFile.Close()
+		writer = outFile
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		logger.Fatal("Failed to write result", zap.Error(err))
+	}
+
+	logger.Info("Backtest finished", zap.Int("alerts", result.AlertsCount), zap.Int("trades", len(result.Trades)),
+		zap.Float64("finalBalance", result.FinalBalance))
+}
+
+func parseDate(logger *zap.Logger, value string, defaultValue time.Time) time.Time {
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.Parse(constants.DATE_FORMAT, value)
+	if err != nil {
+		logger.Fatal("Invalid date", zap.String("date", value), zap.Error(err))
+	}
+	return parsed
+}
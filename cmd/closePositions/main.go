@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/api/bybit"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/dto/trade"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/notification"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/orders"
+	"tradingViewWebhookBot/internal/telegram"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// notificationCloseTimeout max time of delivering notifications of the closed positions to the asynchronous channels
+const notificationCloseTimeout = 30 * time.Second
+
+// go run cmd/closePositions/main.go -transaction 42
+// go run cmd/closePositions/main.go -strategy 2 -dry-run
+// go run cmd/closePositions/main.go -all -yes
+// Closes positions with the "manual" exit reason. Without -yes the preview is shown and "yes" is asked.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	transactionId := flag.Int64("transaction", 0, "id of the opened transaction to close")
+	strategyId := flag.Int64("strategy", 0, "close all positions of the strategy")
+	all := flag.Bool("all", false, "flatten all positions of all strategies")
+	dryRun := flag.Bool("dry-run", false, "only show what would be closed")
+	confirmed := flag.Bool("yes", false, "close without confirmation")
+	flag.Parse()
+
+	if countSet(*transactionId != 0, *strategyId != 0, *all) != 1 {
+		logger.Fatal("Exactly one of -transaction, -strategy or -all is required")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	viper.AddConfigPath("internal/configs")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		logger.Fatal("Error loading config", zap.Error(err))
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	repos := repository.NewRepositories(db)
+	exchangeApi := bybit.NewBybitApi(os.Getenv("BYBIT_API_KEY"), os.Getenv("BYBIT_API_SECRET"))
+	telegramClient := telegram.NewTelegramClient(repos.FailedMessage)
+	defer telegramClient.Close()
+	notifier := notification.NewRouterFromConfig(telegramClient)
+	orderManagerService := orders.NewOrderManagerService(
+		repos.Transaction,
+		repos.TradingStrategy,
+		exchangeApi,
+		date.GetClock(),
+		notifier,
+		viper.GetInt64("default.leverage"))
+	manualCloseService := orders.NewManualCloseService(repos.TradingStrategy, repos.Transaction, repos.Coin, exchangeApi, orderManagerService)
+
+	closePositions := func(dryRun bool) (*trade.ManualCloseResultDto, error) {
+		switch {
+		case *transactionId != 0:
+			return manualCloseService.ClosePosition(*transactionId, dryRun)
+		case *strategyId != 0:
+			return manualCloseService.CloseStrategyPositions(*strategyId, dryRun)
+		default:
+			return manualCloseService.FlattenAll(dryRun)
+		}
+	}
+
+	preview, err := closePositions(true)
+	if err != nil {
+		logger.Fatal("Failed to preview positions", zap.Error(err))
+	}
+	printResult(preview)
+	if *dryRun || len(preview.Positions) == 0 {
+		return
+	}
+
+	if !*confirmed && !askConfirmation(len(preview.Positions)) {
+		logger.Info("Cancelled")
+		return
+	}
+
+	result, err := closePositions(false)
+	// Fatal exits without deferred calls, notifications of closed positions are delivered before
+	notifier.Close(notificationCloseTimeout)
+	telegramClient.Close()
+	if err != nil {
+		logger.Fatal("Failed to close positions", zap.Error(err))
+	}
+	printResult(result)
+	if result.Failed > 0 {
+		logger.Fatal("Some positions were not closed", zap.Int("closed", result.Closed), zap.Int("failed", result.Failed))
+	}
+	logger.Info("Positions closed", zap.Int("closed", result.Closed), zap.Int("skipped", result.Skipped))
+}
+
+func askConfirmation(positionsCount int) bool {
+	fmt.Printf("Close %d position(s)? Type \"yes\" to confirm: ", positionsCount)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
+
+func printResult(result *trade.ManualCloseResultDto) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(result)
+}
+
+func countSet(values ...bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
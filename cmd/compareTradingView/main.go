@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/comparison"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+// go run cmd/compareTradingView/main.go -csv trades.csv -strategy 2 -symbol BTCUSDT
+// go run cmd/compareTradingView/main.go -csv trades.csv -strategy 2 -timezone Europe/Berlin -window 10m -out comparison.json
+// Matches trades of the TradingView strategy tester "List of trades" export to the recorded round trips of the strategy
+// and shows missed and extra trades, entry/exit slippage and the profit gap.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	csvFile := flag.String("csv", "", "TradingView strategy tester \"List of trades\" export")
+	strategyId := flag.Int64("strategy", 0, "trading strategy id")
+	symbol := flag.String("symbol", "", "coin symbol, all coins of the strategy by default")
+	timezone := flag.String("timezone", "UTC", "timezone of the chart the export was made from")
+	window := flag.Duration("window", 5*time.Minute, "max difference of entry times of matched trades")
+	includeFake := flag.Bool("fake", false, "include fake transactions")
+	out := flag.String("out", "", "output file, stdout by default")
+	flag.Parse()
+
+	if *csvFile == "" || *strategyId == 0 {
+		logger.Fatal("-csv and -strategy are required")
+	}
+
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		logger.Fatal("Invalid timezone", zap.String("timezone", *timezone), zap.Error(err))
+	}
+
+	file, err := os.Open(*csvFile)
+	if err != nil {
+		logger.Fatal("Failed to open csv file", zap.Error(err))
+	}
+	trades, err := comparison.ParseStrategyTesterTrades(file, location)
+	file.Close()
+	if err != nil {
+		logger.Fatal("Failed to parse csv file", zap.Error(err))
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	filter := transaction.RoundTripFilter{TradingStrategyId: *strategyId}
+	if *symbol != "" {
+		coin, err := repos.Coin.FindBySymbol(strings.ToUpper(*symbol))
+		if err != nil {
+			logger.Fatal("Coin not found", zap.String("symbol", *symbol), zap.Error(err))
+		}
+		filter.CoinId = coin.Id
+	}
+	if !*includeFake {
+		isFake := false
+		filter.IsFake = &isFake
+	}
+
+	result, err := comparison.NewTradingViewComparisonService(repos.Transaction).Compare(trades, filter, *window)
+	if err != nil {
+		logger.Fatal("Failed to compare trades", zap.Error(err))
+	}
+
+	writer := os.Stdout
+	if *out != "" {
+		outFile, err := os.Create(*out)
+		if err != nil {
+			logger.Fatal("Failed to create output file", zap.Error(err))
+		}
+		defer outFile.Close()
+		writer = outFile
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		logger.Fatal("Failed to write result", zap.Error(err))
+	}
+
+	logger.Info("Trades compared", zap.Int("tradingView", result.TradingViewTradesCount), zap.Int("matched", result.MatchedCount),
+		zap.Int("missed", len(result.MissedTrades)), zap.Int("extra", len(result.ExtraTrades)))
+}
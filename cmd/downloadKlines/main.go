@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/api/bybit"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// go run cmd/downloadKlines/main.go
+// go run cmd/downloadKlines/main.go -coins BTCUSDT,ETHUSDT -intervals 15 -from 2024-01-01
+// go run cmd/downloadKlines/main.go -watch
+// Downloads closed candles of enabled coins after the latest stored one and fills gaps between stored candles.
+// Coins without stored candles are backfilled from -from or candles.backfillDays ago.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	coinSymbols := flag.String("coins", "", "comma separated symbols, all enabled coins by default")
+	intervals := flag.String("intervals", "", "comma separated intervals in minutes, candles.intervals by default")
+	from := flag.String("from", "", "backfill start of coins without candles (2006-01-02), candles.backfillDays ago by default")
+	watch := flag.Bool("watch", false, "keep downloading new candles every candles.updateInterval")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	viper.AddConfigPath("internal/configs")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		logger.Fatal("Error loading config", zap.Error(err))
+	}
+
+	intervalList := viper.GetStringSlice("candles.intervals")
+	if *intervals != "" {
+		intervalList = splitList(*intervals)
+	}
+	if len(intervalList) == 0 {
+		logger.Fatal("No intervals to download")
+	}
+
+	backfillFrom := time.Now().UTC().AddDate(0, 0, -viper.GetInt("candles.backfillDays"))
+	if *from != "" {
+		parsed, err := time.Parse(constants.DATE_FORMAT, *from)
+		if err != nil {
+			logger.Fatal("Invalid date", zap.String("date", *from), zap.Error(err))
+		}
+		backfillFrom = parsed
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	repos := repository.NewRepositories(db)
+	coins := findCoins(logger, repos.Coin, *coinSymbols)
+	candleDownloadService := candles.NewCandleDownloadService(repos.Candle, bybit.NewBybitKlinesApi(), date.GetClock(),
+		viper.GetDuration("candles.requestDelay"))
+
+	for {
+		failed := 0
+		for i := range coins {
+			for _, interval := range intervalList {
+				downloaded, err := candleDownloadService.Update(&coins[i], interval, backfillFrom)
+				if err != nil {
+					failed++
+					logger.Error("Failed to download candles", zap.String("coin", coins[i].Symbol), zap.String("interval", interval),
+						zap.Int("downloaded", downloaded), zap.Error(err))
+					continue
+				}
+				logger.Info("Candles downloaded", zap.String("coin", coins[i].Symbol), zap.String("interval", interval),
+					zap.Int("downloaded", downloaded))
+			}
+		}
+
+		if !*watch {
+			if failed > 0 {
+				logger.Fatal("Some candles were not downloaded", zap.Int("failed", failed))
+			}
+			return
+		}
+		time.Sleep(viper.GetDuration("candles.updateInterval"))
+	}
+}
+
+func findCoins(logger *zap.Logger, coinRepo repository.Coin, coinSymbols string) []domain.Coin {
+	if coinSymbols == "" {
+		allCoins, err := coinRepo.FindAll()
+		if err != nil {
+			logger.Fatal("Failed to load coins", zap.Error(err))
+		}
+		var enabledCoins []domain.Coin
+		for _, coin := range allCoins {
+			if coin.Enabled {
+				enabledCoins = append(enabledCoins, coin)
+			}
+		}
+		return enabledCoins
+	}
+
+	var coins []domain.Coin
+	for _, symbol := range splitList(coinSymbols) {
+		coin, err := coinRepo.FindBySymbol(strings.ToUpper(symbol))
+		if err != nil {
+			logger.Fatal("Coin not found", zap.String("symbol", symbol), zap.Error(err))
+		}
+		coins = append(coins, *coin)
+	}
+	return coins
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/exportFormat"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/export"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+// go run cmd/exportTrades/main.go -format koinly -from 2024-01-01 -to 2025-01-01 -out trades.csv
+// Exports closed round trips of all strategies (or one with -strategy) for the accountant.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	format := flag.String("format", string(exportFormat.CSV), "csv, jsonl, koinly or cointracking")
+	strategyId := flag.Int64("strategy", 0, "trading strategy id, all strategies by default")
+	from := flag.String("from", "", "close date from, inclusive (2006-01-02)")
+	to := flag.String("to", "", "close date to, exclusive (2006-01-02)")
+	includeFake := flag.Bool("fake", false, "include fake transactions")
+	out := flag.String("out", "", "output file, stdout by default")
+	flag.Parse()
+
+	if !exportFormat.IsValid(exportFormat.ExportFormat(*format)) {
+		logger.Fatal("Invalid format", zap.String("format", *format))
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	filter := transaction.RoundTripFilter{TradingStrategyId: *strategyId}
+	filter.From = parseDate(logger, *from)
+	filter.To = parseDate(logger, *to)
+	if !*includeFake {
+		isFake := false
+		filter.IsFake = &isFake
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	writer := os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			logger.Fatal("Failed to create output file", zap.Error(err))
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	repos := repository.NewRepositories(db)
+	exported, err := export.NewTradeExportService(repos.Transaction).Export(writer, filter, exportFormat.ExportFormat(*format))
+	if err != nil {
+		logger.Fatal("Failed to export trades", zap.Error(err))
+	}
+
+	logger.Info("Trades exported", zap.Int("count", exported))
+}
+
+func parseDate(logger *zap.Logger, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(constants.DATE_FORMAT, value)
+	if err != nil {
+		logger.Fatal("Invalid date", zap.String("date", value), zap.Error(err))
+	}
+	return &parsed
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+	"tradingViewWebhookBot/internal/api/bybit"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/database"
+	"tradingViewWebhookBot/internal/logger"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/repository/memory"
+	"tradingViewWebhookBot/internal/service/backtest"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// go run cmd/replayAlerts/main.go -from "2024-05-01 10:00:00" -to "2024-05-01 18:00:00"
+// go run cmd/replayAlerts/main.go -from 2024-05-01 -to 2024-05-02 -source exchange -all -out replay.json
+// Replays stored alerts of the period (UTC) against the paper exchange with historical prices
+// and shows alerts where the replayed transactions differ from the live ones. Nothing is written to the database.
+func main() {
+	logger := logger.InitLogger()
+	defer logger.Sync()
+
+	from := flag.String("from", "", "start, inclusive (2006-01-02 or \"2006-01-02 15:04:05\")")
+	to := flag.String("to", "", "end, exclusive (2006-01-02 or \"2006-01-02 15:04:05\")")
+	interval := flag.String("interval", "1", "candle interval in minutes, an alert is filled at the open of the candle after it")
+	source := flag.String("source", "db", "candles of the local store (db) or downloaded from Bybit (exchange)")
+	balance := flag.Float64("balance", 1000, "initial balance in USD")
+	all := flag.Bool("all", false, "show matched alerts too")
+	out := flag.String("out", "", "output file, stdout by default")
+	flag.Parse()
+
+	if *from == "" || *to == "" {
+		logger.Fatal("-from and -to are required")
+	}
+	if *source != "db" && *source != "exchange" {
+		logger.Fatal("Invalid candle source", zap.String("source", *source))
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	viper.AddConfigPath("internal/configs")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		logger.Fatal("Error loading config", zap.Error(err))
+	}
+
+	settings := backtest.Settings{
+		Interval:   *interval,
+		From:       parseTime(logger, *from),
+		To:         parseTime(logger, *to),
+		Balance:    *balance,
+		Commission: viper.GetFloat64("api.bybit.commission"),
+		Leverage:   viper.GetInt64("default.leverage"),
+		HedgeMode:  viper.GetBool("api.bybit.hedgeMode"),
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+	liveRepos := repository.NewRepositories(db)
+
+	var candleSource candles.CandleSource = candles.NewDatabaseCandleSource(liveRepos.Candle)
+	if *source == "exchange" {
+		candleSource = candles.NewExchangeCandleSource(candles.NewCandleDownloadService(liveRepos.Candle, bybit.NewBybitKlinesApi(),
+			date.GetClock(), viper.GetDuration("candles.requestDelay")))
+	}
+
+	replayRepos, err := memory.CopyRepositories(liveRepos)
+	if err != nil {
+		logger.Fatal("Failed to copy repositories", zap.Error(err))
+	}
+	alertReplayService, err := backtest.NewAlertReplayService(liveRepos, replayRepos, candleSource, date.NewClockMock(settings.From), settings)
+	if err != nil {
+		logger.Fatal("Invalid replay settings", zap.Error(err))
+	}
+	result, err := alertReplayService.Replay(*all)
+	if err != nil {
+		logger.Fatal("Replay failed", zap.Error(err))
+	}
+
+	writer := os.Stdout
+	if *out != "" {
+		outFile, err := os.Create(*out)
+		if err != nil {
+			logger.Fatal("Failed to create output file", zap.Error(err))
+		}
+		defer outFile.Close()
+		writer = outFile
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		logger.Fatal("Failed to write result", zap.Error(err))
+	}
+
+	logger.Info("Alerts replayed", zap.Int("alerts", result.AlertsCount), zap.Int("mismatched", result.MismatchedAlerts),
+		zap.Bool("withoutAlertMatched", result.WithoutAlert.Matched))
+}
+
+func parseTime(logger *zap.Logger, value string) time.Time {
+	if parsed, err := time.Parse(constants.DATE_TIME_FORMAT, value); err == nil {
+		return parsed
+	}
+	parsed, err := time.Parse(constants.DATE_FORMAT, value)
+	if err != nil {
+		logger.Fatal("Invalid time", zap.String("time", value), zap.Error(err))
+	}
+	return parsed
+}
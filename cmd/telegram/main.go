@@ -28,9 +28,10 @@ func main() {
 	}
 	defer logger.Sync()
 
-	client := telegram.NewTelegramClient()
+	client := telegram.NewTelegramClient(nil)
 
 	client.SendMessage(*message)
+	client.Close()
 
 	logger.Info("Message sent successfully",
 		zap.String("message", *message),
@@ -6,14 +6,30 @@ import (
 	"log"
 	"net/http"
 	"os"
+	_ "time/tzdata" // alpine image has no zoneinfo, needed for timezone of equity buckets
+	"tradingViewWebhookBot/internal/api"
 	"tradingViewWebhookBot/internal/api/bybit"
 	"tradingViewWebhookBot/internal/controller"
 	"tradingViewWebhookBot/internal/database"
 	"tradingViewWebhookBot/internal/logger"
+	authMiddleware "tradingViewWebhookBot/internal/middleware"
+	"tradingViewWebhookBot/internal/notification"
 	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/alerts"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/coins"
 	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/export"
+	"tradingViewWebhookBot/internal/service/filters"
 	"tradingViewWebhookBot/internal/service/orders"
+	"tradingViewWebhookBot/internal/service/positions"
+	"tradingViewWebhookBot/internal/service/report"
+	"tradingViewWebhookBot/internal/service/statistics"
+	"tradingViewWebhookBot/internal/service/strategy"
+	"tradingViewWebhookBot/internal/service/symbol"
+	"tradingViewWebhookBot/internal/service/watchdog"
 	"tradingViewWebhookBot/internal/telegram"
+	"tradingViewWebhookBot/internal/telegram/commands"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -77,19 +93,81 @@ func initializeRouter(db *sqlx.DB) *chi.Mux {
 
 	exchangeApi := bybit.NewBybitApi(os.Getenv("BYBIT_API_KEY"), os.Getenv("BYBIT_API_SECRET"))
 
-	telegramClient := telegram.NewTelegramClient()
+	telegramClient := telegram.NewTelegramClient(repos.FailedMessage)
+	notifier := notification.NewRouterFromConfig(telegramClient)
+
+	if viper.GetBool("api.bybit.hedgeMode") {
+		switchToHedgeMode(repos.Coin, exchangeApi)
+	}
+	coinService := coins.NewCoinService(repos.Coin, repos.Transaction, exchangeApi)
+	backfillInstrumentInfo(coinService)
 
 	orderManagerService := orders.NewOrderManagerService(
 		repos.Transaction,
+		repos.TradingStrategy,
 		exchangeApi,
 		date.GetClock(),
-		telegramClient,
+		notifier,
 		viper.GetInt64("default.leverage"))
 
+	positionWatchdogService := watchdog.NewPositionWatchdogService(
+		repos.TradingStrategy,
+		repos.Transaction,
+		repos.Coin,
+		exchangeApi,
+		orderManagerService,
+		date.GetClock(),
+		viper.GetDuration("watchdog.interval"))
+	positionWatchdogService.Start()
+
+	// Initialize services
+	symbolMapperService := symbol.NewSymbolMapperService(repos.Coin, repos.CoinAlias)
+	positionService := positions.NewPositionService(repos.TradingStrategy, repos.Transaction, repos.Coin, repos.Alert, exchangeApi, orderManagerService, date.GetClock())
+	tradingStrategyService := strategy.NewTradingStrategyService(repos.TradingStrategy, repos.Transaction, repos.Coin)
+	// candles of the filters are read from the local candle store, the ones after the latest stored candle are downloaded
+	alertFilterService := filters.NewAlertFilterService(
+		repos.Alert,
+		candles.NewStoreCandleSource(repos.Candle, candles.NewCandleDownloadService(repos.Candle, bybit.NewBybitKlinesApi(), date.GetClock(), viper.GetDuration("candles.requestDelay"))),
+		exchangeApi,
+		bybit.NewBybitTickerApi(),
+		date.GetClock())
+	go warmUpAlertFilters(alertFilterService, repos.TradingStrategy, repos.Coin)
+	alertProcessorService := alerts.NewAlertProcessorService(repos.TradingStrategy, repos.Transaction, symbolMapperService, orderManagerService, alertFilterService, notifier, viper.GetBool("api.bybit.hedgeMode"))
+	manualCloseService := orders.NewManualCloseService(repos.TradingStrategy, repos.Transaction, repos.Coin, exchangeApi, orderManagerService)
+
+	report.NewPerformanceReportService(
+		repos.TradingStrategy,
+		repos.Transaction,
+		exchangeApi,
+		positionService,
+		notifier,
+		date.GetClock(),
+		viper.GetString("report.daily"),
+		viper.GetString("report.weekly")).Start()
+
+	telegramClient.StartMessageHandler(commands.NewCommandRouter(
+		repos.Transaction,
+		exchangeApi,
+		tradingStrategyService,
+		positionService,
+		manualCloseService,
+		symbolMapperService,
+		date.GetClock()))
+
 	// Initialize controllers
-	healthController := controller.NewHealthController()
-	coinController := controller.NewCoinController(repos.Coin, exchangeApi, telegramClient)
-	webhookController := controller.NewAlertWebhookController(repos.TradingStrategy, repos.Transaction, repos.Coin, exchangeApi, telegramClient, orderManagerService)
+	appControllers := &controllers{
+		health:            controller.NewHealthController(),
+		coin:              controller.NewCoinController(repos.Coin, exchangeApi, notifier, coinService),
+		coinAlias:         controller.NewCoinAliasController(symbolMapperService),
+		webhook:           controller.NewAlertWebhookController(repos.Alert, notifier, alertProcessorService),
+		strategyStatistic: controller.NewStrategyStatisticController(repos.TradingStrategy, repos.Coin, statistics.NewStrategyStatisticService(repos.Transaction)),
+		equityCurve:       controller.NewEquityCurveController(repos.TradingStrategy, repos.Coin, statistics.NewEquityCurveService(repos.Transaction)),
+		tradeExport:       controller.NewTradeExportController(repos.Coin, export.NewTradeExportService(repos.Transaction)),
+		tradingStrategy:   controller.NewTradingStrategyController(tradingStrategyService),
+		position:          controller.NewPositionController(repos.Coin, positionService, os.Getenv("API_AUTH_TOKEN")),
+		manualClose:       controller.NewManualCloseController(manualCloseService),
+		failedMessage:     controller.NewFailedMessageController(repos.FailedMessage, telegramClient),
+	}
 
 	// Initialize router
 	r := chi.NewRouter()
@@ -99,24 +177,123 @@ func initializeRouter(db *sqlx.DB) *chi.Mux {
 	r.Use(middleware.Recoverer)
 
 	// Routes
-	setupRoutes(r, healthController, coinController, webhookController)
+	setupRoutes(r, appControllers)
 
 	return r
 }
 
-func setupRoutes(r *chi.Mux, healthController *controller.HealthController, coinController *controller.CoinController,
-	webhookController *controller.AlertWebhookController) {
-	r.Get("/health", healthController.HealthCheck)
+// switchToHedgeMode Bybit position mode is set per symbol and can't be changed while a position is opened
+func switchToHedgeMode(coinRepo repository.Coin, exchangeApi api.ExchangeApi) {
+	coins, err := coinRepo.FindAll()
+	if err != nil {
+		zap.S().Errorf("Error during coins FindAll: %s", err.Error())
+		return
+	}
+	for i := range coins {
+		if err := exchangeApi.SwitchPositionMode(&coins[i], true); err != nil {
+			zap.S().Warnf("Hedge mode was not switched for %s: %s", coins[i].Symbol, err.Error())
+		}
+	}
+}
+
+func backfillInstrumentInfo(coinService *coins.CoinService) {
+	failed, err := coinService.BackfillInstrumentInfo()
+	if err != nil {
+		zap.S().Errorf("Error during BackfillInstrumentInfo: %s", err.Error())
+		return
+	}
+	for _, message := range failed {
+		zap.S().Warnf("Instrument info was not backfilled for %s", message)
+	}
+}
+
+func warmUpAlertFilters(alertFilterService *filters.AlertFilterService, strategyRepo repository.TradingStrategy, coinRepo repository.Coin) {
+	strategies, err := strategyRepo.List()
+	if err != nil {
+		zap.S().Errorf("Error during warm up of alert filters: %s", err.Error())
+		return
+	}
+	coinList, err := coinRepo.FindAll()
+	if err != nil {
+		zap.S().Errorf("Error during warm up of alert filters: %s", err.Error())
+		return
+	}
+	alertFilterService.WarmUp(strategies, coinList)
+}
+
+type controllers struct {
+	health            *controller.HealthController
+	coin              *controller.CoinController
+	coinAlias         *controller.CoinAliasController
+	webhook           *controller.AlertWebhookController
+	strategyStatistic *controller.StrategyStatisticController
+	equityCurve       *controller.EquityCurveController
+	tradeExport       *controller.TradeExportController
+	tradingStrategy   *controller.TradingStrategyController
+	position          *controller.PositionController
+	manualClose       *controller.ManualCloseController
+	failedMessage     *controller.FailedMessageController
+}
+
+func setupRoutes(r *chi.Mux, c *controllers) {
+	authenticated := authMiddleware.TokenAuth(os.Getenv("API_AUTH_TOKEN"))
+
+	r.Get("/health", c.health.HealthCheck)
 
 	// Coin routes
 	r.Route("/coins", func(r chi.Router) {
-		r.Get("/symbol/{symbol}", coinController.GetCoinBySymbol)
-		r.Get("/id/{id}", coinController.GetCoinByID)
-		r.Get("/price/{symbol}", coinController.GetCurrentPrice)
+		r.Get("/symbol/{symbol}", c.coin.GetCoinBySymbol)
+		r.Get("/id/{id}", c.coin.GetCoinByID)
+		r.Get("/price/{symbol}", c.coin.GetCurrentPrice)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authenticated)
+			r.Get("/", c.coin.List)
+			r.Post("/", c.coin.Add)
+			r.Post("/{id}/refresh", c.coin.RefreshInstrumentInfo)
+			r.Post("/{id}/enable", c.coin.Enable)
+			r.Post("/{id}/disable", c.coin.Disable)
+			r.Delete("/{id}", c.coin.Remove)
+
+			r.Get("/aliases", c.coinAlias.List)
+			r.Post("/aliases", c.coinAlias.Add)
+			r.Delete("/aliases/{id}", c.coinAlias.Remove)
+		})
+	})
+
+	// Strategy routes
+	r.Route("/strategies", func(r chi.Router) {
+		r.Use(authenticated)
+		r.Get("/", c.tradingStrategy.List)
+		r.Post("/", c.tradingStrategy.Create)
+		r.Get("/{id}", c.tradingStrategy.Get)
+		r.Put("/{id}", c.tradingStrategy.Update)
+		r.Delete("/{id}", c.tradingStrategy.Delete)
+		r.Post("/{id}/enable", c.tradingStrategy.Enable)
+		r.Post("/{id}/disable", c.tradingStrategy.Disable)
+		r.Post("/{id}/close-positions", c.manualClose.CloseStrategyPositions)
+		r.Get("/{id}/stats", c.strategyStatistic.GetStatistic)
+		r.Get("/{id}/equity", c.equityCurve.GetEquityCurve)
+	})
+
+	// Position and transaction routes
+	r.Group(func(r chi.Router) {
+		r.Use(authenticated)
+		r.Get("/positions", c.position.GetOpenedPositions)
+		r.Post("/positions/flatten", c.manualClose.FlattenAll)
+		r.Post("/positions/{id}/close", c.manualClose.ClosePosition)
+		r.Get("/transactions", c.position.GetTransactions)
+		r.Get("/transactions/{id}/round-trip", c.position.GetRoundTrip)
+		r.Get("/alerts/{id}", c.position.GetAlert)
+		r.Get("/notifications/failed", c.failedMessage.List)
+		r.Post("/notifications/failed/replay", c.failedMessage.Replay)
+		r.Get("/export/trades", c.tradeExport.ExportTrades)
 	})
 
-	r.HandleFunc("/webhook/alert", webhookController.HandleAlert)
+	// links of trade notifications, checked by the signature instead of the token
+	r.Get("/alerts/{id}/view", c.position.ViewAlert)
 
+	r.HandleFunc("/webhook/alert", c.webhook.HandleAlert)
 }
 
 func (a *App) run() error {
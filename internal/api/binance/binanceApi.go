@@ -152,6 +152,30 @@ func (api *BinanceApi) SetIsolatedMargin(coin *domain.Coin, leverage int) error
 	return nil
 }
 
+func (api *BinanceApi) SwitchPositionMode(coin *domain.Coin, hedgeMode bool) error {
+	return errors.New("Futures api is not implemented")
+}
+
+func (api *BinanceApi) GetNetPositionSize(coin *domain.Coin) (float64, error) {
+	return 0, errors.New("Futures api is not implemented")
+}
+
+func (api *BinanceApi) GetOrderExecFee(coin *domain.Coin, orderId string) (float64, error) {
+	return 0, errors.New("Not implemented for Binance API")
+}
+
+func (api *BinanceApi) GetFundingFee(coin *domain.Coin, futuresType futureType.FuturesType, amount float64, from time.Time, to time.Time) (float64, error) {
+	return 0, errors.New("Futures api is not implemented")
+}
+
+func (api *BinanceApi) GetInstrumentInfo(symbol string) (api.InstrumentInfoDto, error) {
+	return nil, errors.New("Futures api is not implemented")
+}
+
+func (api *BinanceApi) GetOrder(coin *domain.Coin, orderId string) (api.OrderResponseDto, error) {
+	return nil, errors.New("Futures api is not implemented")
+}
+
 func (api *BinanceApi) IsFuturesPositionOpened(coin *domain.Coin, openedOrder *domain.Transaction) bool {
 	return true
 }
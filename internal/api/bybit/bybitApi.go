@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	bybit "github.com/bybit-exchange/bybit.go.api"
+	"github.com/spf13/viper"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"tradingViewWebhookBot/internal/constants/futureType"
 	"tradingViewWebhookBot/internal/domain"
 	bybitDto "tradingViewWebhookBot/internal/dto/bybit"
+	"tradingViewWebhookBot/internal/dto/bybit/market"
 	"tradingViewWebhookBot/internal/dto/bybit/order"
 	"tradingViewWebhookBot/internal/dto/bybit/position"
 	"tradingViewWebhookBot/internal/dto/bybit/wallet"
@@ -29,18 +31,40 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	orderExecutionTimeoutSeconds = 60
+	orderCancelTimeoutSeconds    = 10
+
+	// transaction log can be requested for 7 days at most
+	transactionLogMaxPeriod = 7 * 24 * time.Hour
+)
+
 func NewBybitApi(apiKey string, secretKey string) api.ExchangeApi {
 	return &BybitApi{
 		apiKey:    apiKey,
 		secretKey: secretKey,
 		client:    bybit.NewBybitHttpClient(apiKey, secretKey, bybit.WithBaseURL(bybit.MAINNET)),
+		hedgeMode: viper.GetBool("api.bybit.hedgeMode"),
 	}
 }
 
+// NewBybitKlinesApi candles are public, so api keys are not needed
+func NewBybitKlinesApi() api.KlinesApi {
+	return &BybitApi{}
+}
+
+// NewBybitTickerApi tickers are public, so api keys are not needed
+func NewBybitTickerApi() api.TickerApi {
+	return &BybitApi{}
+}
+
 type BybitApi struct {
 	apiKey    string
 	secretKey string
 	client    *bybit.Client
+
+	/* Hedge mode allows simultaneous long and short positions of the same symbol */
+	hedgeMode bool
 }
 
 func (bybitApi *BybitApi) GetKlines(coin *domain.Coin, interval string, limit int, fromTime time.Time) (api.KlinesDto, error) {
@@ -81,10 +105,37 @@ func (bybitApi *BybitApi) GetKlinesFutures(coin *domain.Coin, interval string, l
 	if err := json.NewDecoder(resp.Body).Decode(&dto); err != nil {
 		return nil, err
 	}
+	if dto.RetCode != 0 {
+		return nil, errors.New(dto.RetMsg)
+	}
 
 	return dto, nil
 }
 
+func (bybitApi *BybitApi) GetTicker(coin *domain.Coin) (api.TickerDto, error) {
+	resp, err := http.Get("https://api.bytick.com/v5/market/tickers?category=linear&symbol=" + coin.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+
+	var dto market.TickersDto
+	if err := json.NewDecoder(resp.Body).Decode(&dto); err != nil {
+		return nil, err
+	}
+	if dto.RetCode != 0 {
+		return nil, errors.New(dto.RetMsg)
+	}
+	if len(dto.Result.List) == 0 {
+		return nil, fmt.Errorf("ticker %s not found", coin.Symbol)
+	}
+
+	return &dto.Result.List[0], nil
+}
+
 func (api *BybitApi) GetCurrentCoinPriceForFutures(coin *domain.Coin) (float64, error) {
 	resp, err := http.Get("https://api.bytick.com/derivatives/v3/public/tickers?symbol=" + coin.Symbol)
 	if err != nil {
@@ -268,13 +319,33 @@ func (api *BybitApi) SetIsolatedMargin(coin *domain.Coin, leverage int) error {
 	return err
 }
 
+func (api *BybitApi) SwitchPositionMode(coin *domain.Coin, hedgeMode bool) error {
+	mode := 0 // 0: one-way mode. 3: hedge mode
+	if hedgeMode {
+		mode = 3
+	}
+	params := map[string]interface{}{
+		"category": "linear",
+		"symbol":   coin.Symbol,
+		"mode":     mode,
+	}
+	response, err := api.client.NewUtaBybitServiceWithParams(params).SwitchPositionMode(context.Background())
+	if err != nil {
+		return err
+	}
+	if response.RetCode != 0 {
+		return errors.New(response.RetMsg)
+	}
+	return nil
+}
+
 func (api *BybitApi) OpenFuturesOrder(coin *domain.Coin, amount float64, price float64, futuresType futureType.FuturesType, stopLossPriceInCents float64) (api.OrderResponseDto, error) {
 	side := "Buy"
 	if futuresType == futureType.SHORT {
 		side = "Sell"
 	}
 
-	return api.makeFutureOrderByMarket(coin, amount, side)
+	return api.makeFutureOrderByMarket(coin, amount, side, api.getPositionIdx(futuresType), false)
 }
 
 func (api *BybitApi) CloseFuturesOrder(coin *domain.Coin, openedTransaction *domain.Transaction, price float64) (api.OrderResponseDto, error) {
@@ -282,17 +353,40 @@ func (api *BybitApi) CloseFuturesOrder(coin *domain.Coin, openedTransaction *dom
 	if openedTransaction.FuturesType == futureType.SHORT {
 		side = "Buy"
 	}
-	return api.makeFutureOrderByMarket(coin, openedTransaction.Amount, side)
+	return api.makeFutureOrderByMarket(coin, openedTransaction.Amount, side, api.getPositionIdx(openedTransaction.FuturesType), true)
+}
+
+// getPositionIdx 0 - one-way mode; hedge mode: 1 - long position, 2 - short position
+func (api *BybitApi) getPositionIdx(futuresType futureType.FuturesType) int {
+	if !api.hedgeMode {
+		return 0
+	}
+	if futuresType == futureType.LONG {
+		return 1
+	}
+	return 2
 }
 
-func (api *BybitApi) makeFutureOrderByMarket(coin *domain.Coin, quantity float64, side string) (api.OrderResponseDto, error) {
+func (api *BybitApi) makeFutureOrderByMarket(coin *domain.Coin, quantity float64, side string, positionIdx int, reduceOnly bool) (api.OrderResponseDto, error) {
+	qty := util.FormatQuantityByStep(quantity, coin.QtyStep)
+	// reduce-only orders close what is opened, the remainder of a partially closed position may be less than the min qty
+	if roundedQuantity, _ := strconv.ParseFloat(qty, 64); !reduceOnly && coin.MinOrderQty > 0 && roundedQuantity < coin.MinOrderQty {
+		return nil, fmt.Errorf("qty %s of %s is less than min order qty %v", qty, coin.Symbol, coin.MinOrderQty)
+	}
+	if coin.MaxOrderQty > 0 && quantity > coin.MaxOrderQty {
+		return nil, fmt.Errorf("qty %s of %s is more than max order qty %v", qty, coin.Symbol, coin.MaxOrderQty)
+	}
+
 	params := map[string]interface{}{
 		"category":    "linear",
 		"symbol":      coin.Symbol,
 		"side":        side,
-		"positionIdx": "0",
+		"positionIdx": strconv.Itoa(positionIdx),
 		"orderType":   "Market",
-		"qty":         fmt.Sprintf("%.3f", quantity),
+		"qty":         qty,
+	}
+	if reduceOnly {
+		params["reduceOnly"] = true
 	}
 	response, err := api.client.NewUtaBybitServiceWithParams(params).PlaceOrder(context.Background())
 	if err != nil {
@@ -308,21 +402,101 @@ func (api *BybitApi) makeFutureOrderByMarket(coin *domain.Coin, quantity float64
 		return nil, errors.New(dto.RetMsg)
 	}
 
-	for i := 0; i < 60; i++ {
+	return api.waitForOrderExecution(coin, dto.Result.OrderId)
+}
+
+// waitForOrderExecution polls the order until it reaches a final status.
+// Partially executed orders are returned as well, so the executed part is always tracked.
+// If the order is still not final after the timeout, the rest is canceled. When even the cancel is not confirmed,
+// OrderStatusUnknownError with the order id and its last state is returned, so the caller can record the order.
+func (bybitApi *BybitApi) waitForOrderExecution(coin *domain.Coin, orderId string) (api.OrderResponseDto, error) {
+	var lastDetails *order.OrderDetails
+	for i := 0; i < orderExecutionTimeoutSeconds; i++ {
 		time.Sleep(time.Second)
 
-		orderHistory, err := api.getOrderById(dto.Result.OrderId)
+		orderDetails, err := bybitApi.getOrderDetails(orderId)
 		if err != nil {
 			zap.S().Error("Failed to get order status", err)
 			continue
 		}
+		lastDetails = orderDetails
 
-		if orderHistory.Result.List[0].OrderStatus == "Filled" {
-			return &orderHistory.Result.List[0], nil
+		if final, result, err := bybitApi.checkFinalOrderStatus(orderDetails); final {
+			return result, err
 		}
 	}
 
-	return nil, errors.New("order not filled after 60 seconds")
+	zap.S().Warnf("Order %s of %s is not filled after %d seconds, cancel it", orderId, coin.Symbol, orderExecutionTimeoutSeconds)
+	if err := bybitApi.cancelOrder(coin, orderId); err != nil {
+		zap.S().Errorf("Failed to cancel order %s: %s", orderId, err.Error())
+	}
+
+	for i := 0; i < orderCancelTimeoutSeconds; i++ {
+		orderDetails, err := bybitApi.getOrderDetails(orderId)
+		if err == nil {
+			lastDetails = orderDetails
+			if final, result, err := bybitApi.checkFinalOrderStatus(orderDetails); final {
+				return result, err
+			}
+		}
+		time.Sleep(time.Second)
+	}
+
+	statusErr := &api.OrderStatusUnknownError{OrderId: orderId}
+	if lastDetails != nil {
+		statusErr.Order = lastDetails
+	}
+	return nil, statusErr
+}
+
+// checkFinalOrderStatus PartiallyFilled is not final: the rest is still executing,
+// it becomes PartiallyFilledCanceled when the rest is canceled after the timeout.
+func (api *BybitApi) checkFinalOrderStatus(orderDetails *order.OrderDetails) (bool, api.OrderResponseDto, error) {
+	switch orderDetails.OrderStatus {
+	case order.STATUS_FILLED:
+		return true, orderDetails, nil
+	case order.STATUS_PARTIALLY_FILLED:
+		zap.S().Debugf("Order %s is %s, executed %v of %v", orderDetails.OrderId, orderDetails.OrderStatus, orderDetails.GetAmount(), orderDetails.GetRequestedAmount())
+		return false, nil, nil
+	case order.STATUS_PARTIALLY_FILLED_CANCELED, order.STATUS_CANCELLED, order.STATUS_REJECTED, order.STATUS_DEACTIVATED:
+		if orderDetails.GetAmount() > 0 {
+			zap.S().Warnf("Order %s is %s, executed %v of %v", orderDetails.OrderId, orderDetails.OrderStatus, orderDetails.GetAmount(), orderDetails.GetRequestedAmount())
+			return true, orderDetails, nil
+		}
+		return true, nil, fmt.Errorf("order %s is %s without execution: %s", orderDetails.OrderId, orderDetails.OrderStatus, orderDetails.RejectReason)
+	}
+	return false, nil, nil
+}
+
+func (api *BybitApi) GetOrder(coin *domain.Coin, orderId string) (api.OrderResponseDto, error) {
+	return api.getOrderDetails(orderId)
+}
+
+func (api *BybitApi) getOrderDetails(orderId string) (*order.OrderDetails, error) {
+	orderHistory, err := api.getOrderById(orderId)
+	if err != nil {
+		return nil, err
+	}
+	if len(orderHistory.Result.List) == 0 {
+		return nil, fmt.Errorf("order %s not found", orderId)
+	}
+	return &orderHistory.Result.List[0], nil
+}
+
+func (api *BybitApi) cancelOrder(coin *domain.Coin, orderId string) error {
+	params := map[string]interface{}{
+		"category": "linear",
+		"symbol":   coin.Symbol,
+		"orderId":  orderId,
+	}
+	response, err := api.client.NewUtaBybitServiceWithParams(params).CancelOrder(context.Background())
+	if err != nil {
+		return err
+	}
+	if response.RetCode != 0 {
+		return errors.New(response.RetMsg)
+	}
+	return nil
 }
 
 func (api *BybitApi) futuresOrderByMarket(queryParams map[string]interface{}) (*order.FuturesOrderResponseDto, error) {
@@ -364,6 +538,138 @@ func (api *BybitApi) IsFuturesPositionOpened(coin *domain.Coin, openedOrder *dom
 	return false
 }
 
+// GetNetPositionSize signed size of the symbol position: long is positive, short is negative.
+// In hedge mode both positions are summed up.
+func (api *BybitApi) GetNetPositionSize(coin *domain.Coin) (float64, error) {
+	positionDto, err := api.GetPosition(coin)
+	if err != nil {
+		return 0, err
+	}
+	if positionDto.RetCode != 0 {
+		return 0, errors.New(positionDto.RetMsg)
+	}
+
+	netSize := float64(0)
+	for _, position := range positionDto.Result.List {
+		size, _ := strconv.ParseFloat(position.Size, 64)
+		if position.Side == "Sell" {
+			netSize -= size
+		} else if position.Side == "Buy" {
+			netSize += size
+		}
+	}
+	return netSize, nil
+}
+
+func (api *BybitApi) GetInstrumentInfo(symbol string) (api.InstrumentInfoDto, error) {
+	params := map[string]interface{}{
+		"category": "linear",
+		"symbol":   symbol,
+	}
+	response, err := api.client.NewUtaBybitServiceWithParams(params).GetInstrumentInfo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	dto := market.InstrumentsInfoDto{}
+	if err := mapstructure.Decode(response, &dto); err != nil {
+		return nil, err
+	}
+	if dto.RetCode != 0 {
+		return nil, errors.New(dto.RetMsg)
+	}
+	if len(dto.Result.List) == 0 {
+		return nil, fmt.Errorf("instrument %s not found", symbol)
+	}
+
+	return &dto.Result.List[0], nil
+}
+
+// GetOrderExecFee real fee of all executions of the order
+func (api *BybitApi) GetOrderExecFee(coin *domain.Coin, orderId string) (float64, error) {
+	params := map[string]interface{}{
+		"category": "linear",
+		"symbol":   coin.Symbol,
+		"orderId":  orderId,
+	}
+	response, err := api.client.NewUtaBybitServiceWithParams(params).GetTradeHistory(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	dto := order.ExecutionListDto{}
+	if err := mapstructure.Decode(response, &dto); err != nil {
+		return 0, err
+	}
+	if dto.RetCode != 0 {
+		return 0, errors.New(dto.RetMsg)
+	}
+	if len(dto.Result.List) == 0 {
+		return 0, fmt.Errorf("no executions of order %s", orderId)
+	}
+
+	return dto.CalculateExecFee(), nil
+}
+
+// GetFundingFee funding settled for the position side of the symbol between from and to, related to the amount of the position.
+// Positive value is paid funding, negative value is received funding.
+func (api *BybitApi) GetFundingFee(coin *domain.Coin, futuresType futureType.FuturesType, amount float64, from time.Time, to time.Time) (float64, error) {
+	side := "Buy"
+	if futuresType == futureType.SHORT {
+		side = "Sell"
+	}
+
+	funding := float64(0)
+	for periodStart := from; periodStart.Before(to); periodStart = periodStart.Add(transactionLogMaxPeriod) {
+		periodEnd := periodStart.Add(transactionLogMaxPeriod)
+		if periodEnd.After(to) {
+			periodEnd = to
+		}
+
+		cursor := ""
+		for {
+			params := map[string]interface{}{
+				"accountType": "UNIFIED",
+				"category":    "linear",
+				"currency":    "USDT",
+				"type":        wallet.TYPE_SETTLEMENT,
+				"startTime":   util.GetMillisByTime(periodStart),
+				"endTime":     util.GetMillisByTime(periodEnd),
+				"limit":       50,
+			}
+			if cursor != "" {
+				params["cursor"] = cursor
+			}
+
+			response, err := api.client.NewUtaBybitServiceWithParams(params).GetTransactionLog(context.Background())
+			if err != nil {
+				return 0, err
+			}
+
+			dto := wallet.TransactionLogDto{}
+			if err := mapstructure.Decode(response, &dto); err != nil {
+				return 0, err
+			}
+			if dto.RetCode != 0 {
+				return 0, errors.New(dto.RetMsg)
+			}
+
+			for i := range dto.Result.List {
+				if dto.Result.List[i].IsSettlementOf(coin.Symbol, side) {
+					funding += dto.Result.List[i].GetFundingForAmount(amount)
+				}
+			}
+
+			if dto.Result.NextPageCursor == "" || len(dto.Result.List) == 0 {
+				break
+			}
+			cursor = dto.Result.NextPageCursor
+		}
+	}
+
+	return funding, nil
+}
+
 func (api *BybitApi) GetLastFuturesOrder(coin *domain.Coin, clientOrderId string) (api.OrderResponseDto, error) {
 	requestParams := map[string]interface{}{
 		"api_key":   api.apiKey,
@@ -483,7 +789,7 @@ func (api *BybitApi) GetConditionalOrder(coin *domain.Coin) (*order.GetCondition
 }
 
 func (api *BybitApi) GetPosition(coin *domain.Coin) (*position.GetPositionDto, error) {
-	params := map[string]interface{}{"category": "linear", "symbol": coin.Symbol, "limit": 1}
+	params := map[string]interface{}{"category": "linear", "symbol": coin.Symbol}
 	response, err := api.client.NewUtaBybitServiceWithParams(params).GetPositionList(context.Background())
 	if err != nil {
 		return nil, err
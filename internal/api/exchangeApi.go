@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"time"
 	"tradingViewWebhookBot/internal/constants/futureType"
 	"tradingViewWebhookBot/internal/domain"
@@ -24,17 +25,55 @@ type ExchangeApi interface {
 	GetWalletBalance() (WalletBalanceDto, error)
 	SetFuturesLeverage(coin *domain.Coin, leverage int) error
 	SetIsolatedMargin(coin *domain.Coin, leverage int) error
+	SwitchPositionMode(coin *domain.Coin, hedgeMode bool) error
+	GetNetPositionSize(coin *domain.Coin) (float64, error)
+	GetOrderExecFee(coin *domain.Coin, orderId string) (float64, error)
+	// GetFundingFee funding of the position side, hedge mode keeps long and short positions of the symbol at once
+	GetFundingFee(coin *domain.Coin, futuresType futureType.FuturesType, amount float64, from time.Time, to time.Time) (float64, error)
+	GetInstrumentInfo(symbol string) (InstrumentInfoDto, error)
+	// GetOrder current state of the order, used to resolve orders which ended with OrderStatusUnknownError
+	GetOrder(coin *domain.Coin, orderId string) (OrderResponseDto, error)
 	//
 	//SetApiKey(apiKey string)
 	//SetSecretKey(secretKey string)
 }
 
+// KlinesApi historical candles, public market data which doesn't need api keys
+type KlinesApi interface {
+	GetKlinesFutures(coin *domain.Coin, interval string, limit int, fromTime time.Time) (KlinesDto, error)
+}
+
 type OrderResponseDto interface {
 	CalculateAvgPrice() float64
 	CalculateTotalCost() float64
 	CalculateCommissionInUsd() float64
 	GetAmount() float64
 	GetCreatedAt() *time.Time
+	GetOrderId() string
+}
+
+// ORDER_STATUS_UNKNOWN order status of the transaction whose order was sent, but its final status was not received
+const ORDER_STATUS_UNKNOWN = "Unknown"
+
+// OrderStatusUnknownError the order was sent, but it was not final after the timeout and the cancel, so it may be executed later.
+// Order is the last received state of the order, nil if no state was received.
+type OrderStatusUnknownError struct {
+	OrderId string
+	Order   OrderResponseDto
+}
+
+func (e *OrderStatusUnknownError) Error() string {
+	return fmt.Sprintf("order %s is not final and its status is unknown", e.OrderId)
+}
+
+// OrderStatusDto is implemented by order responses which know the final exchange order status
+type OrderStatusDto interface {
+	GetOrderStatus() string
+}
+
+// TickerApi best prices and funding of the symbol, public market data which doesn't need api keys
+type TickerApi interface {
+	GetTicker(coin *domain.Coin) (TickerDto, error)
 }
 
 type KlinesDto interface {
@@ -51,6 +90,28 @@ type KlineDto interface {
 	GetHigh() float64
 	GetLow() float64
 	GetClose() float64
+	GetVolume() float64
+	GetTurnover() float64
+}
+
+// TickerDto FundingRate is a fraction of the position value, e.g. 0.0001 is 0.01%
+type TickerDto interface {
+	GetBidPrice() float64
+	GetAskPrice() float64
+	GetLastPrice() float64
+	GetFundingRate() float64
+}
+
+// InstrumentInfoDto trading rules of the symbol on the exchange
+type InstrumentInfoDto interface {
+	GetSymbol() string
+	GetBaseCoin() string
+	IsTrading() bool
+	GetTickSize() float64
+	GetQtyStep() float64
+	GetMinOrderQty() float64
+	GetMaxOrderQty() float64
+	GetMinNotional() float64
 }
 
 type WalletBalanceDto interface {
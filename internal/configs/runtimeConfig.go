@@ -15,7 +15,7 @@ func NewRuntimeConfig(logger *zap.Logger) *config {
 
 	RuntimeConfig = &config{
 		TradingEnabled: true,
-		telegramClient: telegramApi.NewTelegramClient(),
+		telegramClient: telegramApi.NewTelegramClient(nil),
 	}
 	return RuntimeConfig
 }
@@ -0,0 +1,13 @@
+package bucketInterval
+
+type BucketInterval string
+
+const (
+	DAY   BucketInterval = "day"
+	WEEK  BucketInterval = "week"
+	MONTH BucketInterval = "month"
+)
+
+func IsValid(interval BucketInterval) bool {
+	return interval == DAY || interval == WEEK || interval == MONTH
+}
@@ -0,0 +1,24 @@
+package eventType
+
+// EventType kind of the notification event, used by channels to subscribe to the part of events
+type EventType string
+
+const (
+	ALERT_RECEIVED EventType = "alert_received"
+	ORDER_OPENED   EventType = "order_opened"
+	ORDER_CLOSED   EventType = "order_closed"
+	ERROR          EventType = "error"
+	RISK_REJECTION EventType = "risk_rejection"
+	DAILY_SUMMARY  EventType = "daily_summary"
+	WEEKLY_SUMMARY EventType = "weekly_summary"
+	// MESSAGE informational message without a specific type, e.g. trading switched off
+	MESSAGE EventType = "message"
+)
+
+func IsValid(eventType EventType) bool {
+	switch eventType {
+	case ALERT_RECEIVED, ORDER_OPENED, ORDER_CLOSED, ERROR, RISK_REJECTION, DAILY_SUMMARY, WEEKLY_SUMMARY, MESSAGE:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package exitReason
+
+// ExitReason describes why a position was closed, stored on the close transaction
+type ExitReason string
+
+const (
+	SIGNAL             ExitReason = "signal"
+	MAX_HOLDING_PERIOD ExitReason = "max_holding_period"
+	SESSION_END        ExitReason = "session_end"
+	REVERSE            ExitReason = "reverse"
+	MANUAL             ExitReason = "manual"
+)
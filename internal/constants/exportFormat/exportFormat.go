@@ -0,0 +1,28 @@
+package exportFormat
+
+type ExportFormat string
+
+const (
+	CSV          ExportFormat = "csv"
+	JSONL        ExportFormat = "jsonl"
+	KOINLY       ExportFormat = "koinly"
+	COINTRACKING ExportFormat = "cointracking"
+)
+
+func IsValid(format ExportFormat) bool {
+	return format == CSV || format == JSONL || format == KOINLY || format == COINTRACKING
+}
+
+func GetContentType(format ExportFormat) string {
+	if format == JSONL {
+		return "application/jsonl"
+	}
+	return "text/csv"
+}
+
+func GetFileExtension(format ExportFormat) string {
+	if format == JSONL {
+		return "jsonl"
+	}
+	return "csv"
+}
@@ -0,0 +1,22 @@
+package filterType
+
+// FilterType check of the strategy alert filter chain
+type FilterType string
+
+const (
+	// EMA_TREND longs only above the EMA of the interval, shorts only below it
+	EMA_TREND FilterType = "ema_trend"
+	// ATR_PERCENT ATR of the interval in percents of the close within min and max
+	ATR_PERCENT FilterType = "atr_percent"
+	// SPREAD bid-ask spread in percents of the mid price not above max
+	SPREAD FilterType = "spread"
+	// FUNDING_RATE absolute funding rate in percents not above max
+	FUNDING_RATE FilterType = "funding_rate"
+	// PRICE_DEVIATION deviation of the alert price from the exchange price in percents not above max
+	PRICE_DEVIATION FilterType = "price_deviation"
+)
+
+// IsCandleBased the filter is calculated from the candles of its interval and needs a period
+func IsCandleBased(filterType FilterType) bool {
+	return filterType == EMA_TREND || filterType == ATR_PERCENT
+}
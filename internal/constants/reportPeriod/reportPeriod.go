@@ -0,0 +1,16 @@
+package reportPeriod
+
+type ReportPeriod string
+
+const (
+	DAILY  ReportPeriod = "daily"
+	WEEKLY ReportPeriod = "weekly"
+)
+
+// GetDays whole UTC days covered by the report
+func GetDays(period ReportPeriod) int {
+	if period == WEEKLY {
+		return 7
+	}
+	return 1
+}
@@ -0,0 +1,43 @@
+package severity
+
+import "strings"
+
+// Severity of the notification event, channels receive events of their minimal severity and above
+type Severity string
+
+const (
+	INFO     Severity = "info"
+	WARNING  Severity = "warning"
+	ERROR    Severity = "error"
+	CRITICAL Severity = "critical"
+)
+
+func IsValid(severity Severity) bool {
+	return severity == INFO || severity == WARNING || severity == ERROR || severity == CRITICAL
+}
+
+// Parse returns severity by case-insensitive name, INFO for an empty or unknown value
+func Parse(value string) Severity {
+	severity := Severity(strings.ToLower(strings.TrimSpace(value)))
+	if !IsValid(severity) {
+		return INFO
+	}
+	return severity
+}
+
+func GetLevel(severity Severity) int {
+	switch severity {
+	case WARNING:
+		return 1
+	case ERROR:
+		return 2
+	case CRITICAL:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func IsAtLeast(severity Severity, minSeverity Severity) bool {
+	return GetLevel(severity) >= GetLevel(minSeverity)
+}
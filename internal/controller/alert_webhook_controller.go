@@ -2,43 +2,32 @@ package controller
 
 import (
 	"encoding/json"
-	"fmt"
-	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
-	"tradingViewWebhookBot/internal/api"
-	"tradingViewWebhookBot/internal/constants"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
 	"tradingViewWebhookBot/internal/dto/tradingview"
+	"tradingViewWebhookBot/internal/notification"
 	"tradingViewWebhookBot/internal/repository"
-	"tradingViewWebhookBot/internal/service/orders"
-	"tradingViewWebhookBot/internal/telegram"
+	"tradingViewWebhookBot/internal/service/alerts"
 )
 
 type AlertWebhookController struct {
-	strategyRepo        repository.TradingStrategy
-	transactionRepo     repository.Transaction
-	coinRepository      repository.Coin
-	exchangeApi         api.ExchangeApi
-	telegramClient      *telegram.TelegramClient
-	orderManagerService *orders.OrderManagerService
+	alertRepo             repository.Alert
+	notifier              notification.Notifier
+	alertProcessorService *alerts.AlertProcessorService
 }
 
 func NewAlertWebhookController(
-	strategyRepo repository.TradingStrategy,
-	transactionRepo repository.Transaction,
-	coinRepo repository.Coin,
-	exchangeApi api.ExchangeApi,
-	telegramClient *telegram.TelegramClient,
-	orderManagerService *orders.OrderManagerService,
+	alertRepo repository.Alert,
+	notifier notification.Notifier,
+	alertProcessorService *alerts.AlertProcessorService,
 ) *AlertWebhookController {
 	return &AlertWebhookController{
-		strategyRepo:        strategyRepo,
-		transactionRepo:     transactionRepo,
-		coinRepository:      coinRepo,
-		exchangeApi:         exchangeApi,
-		telegramClient:      telegramClient,
-		orderManagerService: orderManagerService,
+		alertRepo:             alertRepo,
+		notifier:              notifier,
+		alertProcessorService: alertProcessorService,
 	}
 }
 
@@ -51,7 +40,7 @@ func (c *AlertWebhookController) HandleAlert(w http.ResponseWriter, r *http.Requ
 	}
 	defer r.Body.Close()
 
-	c.telegramClient.SendMessage(fmt.Sprintf("Alert triggered: %s", body))
+	c.notifier.Notify(notification.AlertReceived(string(body)))
 
 	var alertRequest tradingview.AlertRequestDto
 	if err := json.Unmarshal(body, &alertRequest); err != nil {
@@ -60,50 +49,18 @@ func (c *AlertWebhookController) HandleAlert(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	if err := validate.Struct(alertRequest); err != nil {
-		c.telegramClient.SendMessage(fmt.Sprintf("AlertRequest is not valid: %s", alertRequest.String()))
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	strategy, err := c.strategyRepo.FindByTag(alertRequest.Tag)
-	if err != nil || strategy == nil {
-		c.telegramClient.SendMessage(fmt.Sprintf("Trading strategy not found: %s", alertRequest.Tag))
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	coin, err := c.coinRepository.FindBySymbol(alertRequest.Ticker)
-	if err != nil || coin == nil {
-		c.telegramClient.SendMessage(fmt.Sprintf("Coin not found: %s", alertRequest.Ticker))
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	alert := &domain.Alert{Tag: alertRequest.Tag, Ticker: alertRequest.Ticker, Payload: string(body), CreatedAt: time.Now().UTC()}
+	if err := c.alertRepo.Create(alert); err != nil {
+		// the alert is processed anyway, transactions are just not linked to it
+		zap.S().Errorf("Error during save of alert: %s", err.Error())
+		alert = nil
 	}
 
-	openedTransaction, err := c.transactionRepo.FindOpenedTransactionByCoin(strategy.Id, coin.Id)
-	if err != nil {
-		c.telegramClient.SendMessage(fmt.Sprintf("Error during FindOpenedTransactionByCoin: %s", coin.Id))
-		w.WriteHeader(http.StatusBadRequest)
+	if err := c.alertProcessorService.Process(alertRequest, alert); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if openedTransaction == nil {
-		c.orderManagerService.OpenOrderAllIn(
-			strategy,
-			coin,
-			alertRequest.GetFuturesType(),
-		)
-	} else {
-		c.orderManagerService.CloseOrder(
-			strategy,
-			openedTransaction,
-			coin,
-			alertRequest.GetPriceFloat(),
-			constants.FUTURES,
-		)
-	}
-
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Alert processed successfully"))
 }
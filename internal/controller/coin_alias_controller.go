@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	coinDto "tradingViewWebhookBot/internal/dto/coin"
+	"tradingViewWebhookBot/internal/service/symbol"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type CoinAliasController struct {
+	symbolMapperService *symbol.SymbolMapperService
+}
+
+func NewCoinAliasController(symbolMapperService *symbol.SymbolMapperService) *CoinAliasController {
+	return &CoinAliasController{
+		symbolMapperService: symbolMapperService,
+	}
+}
+
+func (c *CoinAliasController) List(w http.ResponseWriter, r *http.Request) {
+	aliases, err := c.symbolMapperService.ListAliases()
+	if err != nil {
+		zap.S().Errorf("Error during ListAliases: %s", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, http.StatusOK, aliases)
+}
+
+func (c *CoinAliasController) Add(w http.ResponseWriter, r *http.Request) {
+	var request coinDto.CoinAliasRequestDto
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coinAlias, err := c.symbolMapperService.AddAlias(request.Exchange, request.Alias, request.CoinSymbol)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJson(w, http.StatusCreated, coinAlias)
+}
+
+func (c *CoinAliasController) Remove(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.symbolMapperService.RemoveAlias(id); err != nil {
+		http.Error(w, "alias not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
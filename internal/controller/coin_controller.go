@@ -2,34 +2,41 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"tradingViewWebhookBot/internal/api"
+	coinDto "tradingViewWebhookBot/internal/dto/coin"
+	"tradingViewWebhookBot/internal/notification"
 	"tradingViewWebhookBot/internal/repository"
-	"tradingViewWebhookBot/internal/telegram"
+	"tradingViewWebhookBot/internal/service/coins"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
 type CoinController struct {
-	repo           repository.Coin
-	exchangeApi    api.ExchangeApi
-	telegramClient *telegram.TelegramClient
-	logger         *zap.Logger
+	repo        repository.Coin
+	exchangeApi api.ExchangeApi
+	notifier    notification.Notifier
+	coinService *coins.CoinService
+	logger      *zap.Logger
 }
 
 func NewCoinController(
 	repo repository.Coin,
 	exchangeApi api.ExchangeApi,
-	telegramClient *telegram.TelegramClient,
+	notifier notification.Notifier,
+	coinService *coins.CoinService,
 ) *CoinController {
 	return &CoinController{
-		repo:           repo,
-		exchangeApi:    exchangeApi,
-		telegramClient: telegramClient,
-		logger:         zap.L(),
+		repo:        repo,
+		exchangeApi: exchangeApi,
+		notifier:    notifier,
+		coinService: coinService,
+		logger:      zap.L(),
 	}
 }
 
@@ -87,7 +94,7 @@ func (c *CoinController) GetCurrentPrice(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	c.telegramClient.SendMessage(fmt.Sprintf("Current %s price: $%.2f", coin.Symbol, price))
+	c.notifier.Notify(notification.Message(coin.Symbol+" price", fmt.Sprintf("Current %s price: $%.2f", coin.Symbol, price)))
 
 	response := struct {
 		Symbol string  `json:"symbol"`
@@ -100,3 +107,103 @@ func (c *CoinController) GetCurrentPrice(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+func (c *CoinController) List(w http.ResponseWriter, r *http.Request) {
+	result, err := c.coinService.List()
+	if err != nil {
+		writeCoinError(w, err)
+		return
+	}
+	writeJson(w, http.StatusOK, result)
+}
+
+func (c *CoinController) Add(w http.ResponseWriter, r *http.Request) {
+	var request coinDto.CoinRequestDto
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coin, err := c.coinService.Add(request)
+	if err != nil {
+		writeCoinError(w, err)
+		return
+	}
+	c.logger.Info("Coin added", zap.String("symbol", coin.Symbol))
+	writeJson(w, http.StatusCreated, coin)
+}
+
+func (c *CoinController) RefreshInstrumentInfo(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coin, err := c.coinService.RefreshInstrumentInfo(id)
+	if err != nil {
+		writeCoinError(w, err)
+		return
+	}
+	writeJson(w, http.StatusOK, coin)
+}
+
+func (c *CoinController) Enable(w http.ResponseWriter, r *http.Request) {
+	c.setEnabled(w, r, true)
+}
+
+func (c *CoinController) Disable(w http.ResponseWriter, r *http.Request) {
+	c.setEnabled(w, r, false)
+}
+
+func (c *CoinController) setEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coin, err := c.coinService.SetEnabled(id, enabled)
+	if err != nil {
+		writeCoinError(w, err)
+		return
+	}
+	c.logger.Info("Coin enabled changed", zap.String("symbol", coin.Symbol), zap.Bool("enabled", enabled))
+	writeJson(w, http.StatusOK, coin)
+}
+
+func (c *CoinController) Remove(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.coinService.Remove(id); err != nil {
+		writeCoinError(w, err)
+		return
+	}
+	c.logger.Info("Coin removed", zap.Int64("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeCoinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, coins.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, coins.ErrNotTradable):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, coins.ErrAlreadyExists), errors.Is(err, coins.ErrHasTransactions):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, coins.ErrInstrumentRefresh):
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	default:
+		zap.S().Errorf("Coin request failed: %s", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
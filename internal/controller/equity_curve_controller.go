@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"tradingViewWebhookBot/internal/constants/bucketInterval"
+	"tradingViewWebhookBot/internal/dto/statistic"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/statistics"
+
+	"go.uber.org/zap"
+)
+
+type EquityCurveController struct {
+	strategyRepo       repository.TradingStrategy
+	coinRepo           repository.Coin
+	equityCurveService *statistics.EquityCurveService
+}
+
+func NewEquityCurveController(
+	strategyRepo repository.TradingStrategy,
+	coinRepo repository.Coin,
+	equityCurveService *statistics.EquityCurveService,
+) *EquityCurveController {
+	return &EquityCurveController{
+		strategyRepo:       strategyRepo,
+		coinRepo:           coinRepo,
+		equityCurveService: equityCurveService,
+	}
+}
+
+// GetEquityCurve query params: interval (day, week, month), tz (IANA name, UTC by default), groupBy=coin, format=csv
+// and the round trip filter params.
+func (c *EquityCurveController) GetEquityCurve(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseRoundTripFilter(r, c.coinRepo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	interval := bucketInterval.BucketInterval(query.Get("interval"))
+	if interval == "" {
+		interval = bucketInterval.DAY
+	}
+	if !bucketInterval.IsValid(interval) {
+		http.Error(w, fmt.Sprintf("invalid interval: %s", interval), http.StatusBadRequest)
+		return
+	}
+
+	// tz is valid, parseRoundTripFilter has read the bounds in it
+	location, _ := parseLocationParam(r)
+
+	strategy, err := c.strategyRepo.GetByID(filter.TradingStrategyId)
+	if err != nil || strategy == nil {
+		http.Error(w, "strategy not found", http.StatusNotFound)
+		return
+	}
+
+	points, err := c.equityCurveService.GetEquityCurve(filter, interval, location, query.Get("groupBy") == "coin")
+	if err != nil {
+		zap.S().Errorf("Error during GetEquityCurve of strategy %d: %s", filter.TradingStrategyId, err.Error())
+		http.Error(w, "failed to calculate equity curve", http.StatusInternalServerError)
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		writeEquityCurveCsv(w, points)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(points)
+}
+
+func writeEquityCurveCsv(w http.ResponseWriter, points []statistic.EquityPointDto) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=equity.csv")
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"period", "coin_symbol", "trades_count", "profit", "equity", "drawdown"})
+	for _, point := range points {
+		writer.Write([]string{
+			point.Period.Format(time.RFC3339),
+			point.CoinSymbol,
+			strconv.Itoa(point.TradesCount),
+			strconv.FormatFloat(point.Profit, 'f', 2, 64),
+			strconv.FormatFloat(point.Equity, 'f', 2, 64),
+			strconv.FormatFloat(point.Drawdown, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/telegram"
+
+	"go.uber.org/zap"
+)
+
+type FailedMessageController struct {
+	failedMessageRepo repository.FailedMessage
+	telegramClient    *telegram.TelegramClient
+}
+
+func NewFailedMessageController(failedMessageRepo repository.FailedMessage, telegramClient *telegram.TelegramClient) *FailedMessageController {
+	return &FailedMessageController{
+		failedMessageRepo: failedMessageRepo,
+		telegramClient:    telegramClient,
+	}
+}
+
+// List not replayed telegram messages, optional limit query param
+func (c *FailedMessageController) List(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := parsePageParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	messages, err := c.failedMessageRepo.FindNotReplayed("telegram", limit)
+	if err != nil {
+		zap.S().Errorf("Error during FindNotReplayed: %s", err.Error())
+		http.Error(w, "failed to get messages", http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, http.StatusOK, messages)
+}
+
+// Replay queues not replayed messages again, oldest first, optional limit query param
+func (c *FailedMessageController) Replay(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := parsePageParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	replayed, err := c.telegramClient.ReplayFailedMessages(limit)
+	if err != nil {
+		zap.S().Errorf("Error during ReplayFailedMessages: %s", err.Error())
+		writeJson(w, http.StatusInternalServerError, map[string]interface{}{"replayed": replayed, "error": err.Error()})
+		return
+	}
+	writeJson(w, http.StatusOK, map[string]interface{}{"replayed": replayed})
+}
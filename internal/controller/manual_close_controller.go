@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tradingViewWebhookBot/internal/dto/trade"
+	"tradingViewWebhookBot/internal/service/orders"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// confirmationValue closing is irreversible, so it must be requested explicitly with confirm=yes
+const confirmationValue = "yes"
+
+type ManualCloseController struct {
+	manualCloseService *orders.ManualCloseService
+}
+
+func NewManualCloseController(manualCloseService *orders.ManualCloseService) *ManualCloseController {
+	return &ManualCloseController{
+		manualCloseService: manualCloseService,
+	}
+}
+
+// ClosePosition closes the opened transaction by id
+func (c *ManualCloseController) ClosePosition(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, func(id int64, dryRun bool) (*trade.ManualCloseResultDto, error) {
+		return c.manualCloseService.ClosePosition(id, dryRun)
+	})
+}
+
+// CloseStrategyPositions closes all opened positions of the strategy by id
+func (c *ManualCloseController) CloseStrategyPositions(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, func(id int64, dryRun bool) (*trade.ManualCloseResultDto, error) {
+		return c.manualCloseService.CloseStrategyPositions(id, dryRun)
+	})
+}
+
+// FlattenAll closes all opened positions of all strategies
+func (c *ManualCloseController) FlattenAll(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, func(_ int64, dryRun bool) (*trade.ManualCloseResultDto, error) {
+		return c.manualCloseService.FlattenAll(dryRun)
+	})
+}
+
+// handle query params: dryRun=true to preview, confirm=yes to close
+func (c *ManualCloseController) handle(w http.ResponseWriter, r *http.Request, closeFunc func(id int64, dryRun bool) (*trade.ManualCloseResultDto, error)) {
+	var id int64
+	if chi.URLParam(r, "id") != "" {
+		parsed, err := parseIdParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id = parsed
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	if !dryRun && r.URL.Query().Get("confirm") != confirmationValue {
+		http.Error(w, "confirmation required: preview with dryRun=true, then repeat with confirm=yes", http.StatusPreconditionRequired)
+		return
+	}
+
+	result, err := closeFunc(id, dryRun)
+	switch {
+	case errors.Is(err, orders.ErrPositionNotFound), errors.Is(err, orders.ErrStrategyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		zap.S().Errorf("Manual close failed: %s", err.Error())
+		http.Error(w, "manual close failed", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	writeJson(w, status, result)
+}
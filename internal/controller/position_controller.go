@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/positions"
+	"tradingViewWebhookBot/internal/util"
+
+	"go.uber.org/zap"
+)
+
+type PositionController struct {
+	coinRepo        repository.Coin
+	positionService *positions.PositionService
+	/* key of the signatures of read-only alert links, the links are refused without it */
+	linkSecret string
+}
+
+func NewPositionController(coinRepo repository.Coin, positionService *positions.PositionService, linkSecret string) *PositionController {
+	return &PositionController{
+		coinRepo:        coinRepo,
+		positionService: positionService,
+		linkSecret:      linkSecret,
+	}
+}
+
+// GetOpenedPositions optional strategyId query param
+func (c *PositionController) GetOpenedPositions(w http.ResponseWriter, r *http.Request) {
+	var strategyId int64
+	if value := r.URL.Query().Get("strategyId"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid strategy id", http.StatusBadRequest)
+			return
+		}
+		strategyId = parsed
+	}
+
+	result, err := c.positionService.GetOpenedPositions(strategyId)
+	if err != nil {
+		zap.S().Errorf("Error during GetOpenedPositions: %s", err.Error())
+		http.Error(w, "failed to get positions", http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, http.StatusOK, result)
+}
+
+func (c *PositionController) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r, c.coinRepo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := parsePageParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.positionService.GetTransactions(filter, limit, offset)
+	if err != nil {
+		zap.S().Errorf("Error during GetTransactions: %s", err.Error())
+		http.Error(w, "failed to get transactions", http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, http.StatusOK, result)
+}
+
+// GetRoundTrip by id of the open or the close transaction
+func (c *PositionController) GetRoundTrip(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.positionService.GetRoundTrip(id)
+	if errors.Is(err, positions.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		zap.S().Errorf("Error during GetRoundTrip %d: %s", id, err.Error())
+		http.Error(w, "failed to get round trip", http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, http.StatusOK, result)
+}
+
+// GetAlert received alert with transactions opened or closed by it
+func (c *PositionController) GetAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.writeAlert(w, id)
+}
+
+// ViewAlert read-only GetAlert without the API token, target of the links of trade notifications signed by the signature query param
+func (c *PositionController) ViewAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !util.IsValidIdSignature(c.linkSecret, id, r.URL.Query().Get("signature")) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	c.writeAlert(w, id)
+}
+
+func (c *PositionController) writeAlert(w http.ResponseWriter, id int64) {
+	result, err := c.positionService.GetAlert(id)
+	if errors.Is(err, positions.ErrAlertNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		zap.S().Errorf("Error during GetAlert %d: %s", id, err.Error())
+		http.Error(w, "failed to get alert", http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, http.StatusOK, result)
+}
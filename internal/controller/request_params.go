@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// parseRoundTripFilter reads strategy id from the path (or optional strategyId query param on routes without it)
+// and from, to (RFC3339 or 2006-01-02 at midnight of tz, UTC by default), coin (symbol) and fake from the query
+func parseRoundTripFilter(r *http.Request, coinRepo repository.Coin) (transaction.RoundTripFilter, error) {
+	filter := transaction.RoundTripFilter{}
+	query := r.URL.Query()
+
+	strategyIdStr := chi.URLParam(r, "id")
+	if strategyIdStr == "" {
+		strategyIdStr = query.Get("strategyId")
+	}
+	if strategyIdStr != "" {
+		strategyId, err := strconv.ParseInt(strategyIdStr, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid strategy id")
+		}
+		filter.TradingStrategyId = strategyId
+	}
+
+	location, err := parseLocationParam(r)
+	if err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseTimeParam(query.Get("from"), location); err != nil {
+		return filter, fmt.Errorf("invalid from: %s", err.Error())
+	}
+	if filter.To, err = parseTimeParam(query.Get("to"), location); err != nil {
+		return filter, fmt.Errorf("invalid to: %s", err.Error())
+	}
+
+	if symbol := query.Get("coin"); symbol != "" {
+		coin, err := coinRepo.FindBySymbol(symbol)
+		if err != nil || coin == nil {
+			return filter, fmt.Errorf("coin not found: %s", symbol)
+		}
+		filter.CoinId = coin.Id
+	}
+
+	if fake := query.Get("fake"); fake != "" {
+		isFake, err := strconv.ParseBool(fake)
+		if err != nil {
+			return filter, fmt.Errorf("invalid fake: %s", fake)
+		}
+		filter.IsFake = &isFake
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam RFC3339 keeps its offset, a date only bound starts at midnight of the location
+func parseTimeParam(value string, location *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+	parsed, err := time.ParseInLocation(constants.DATE_FORMAT, value, location)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseLocationParam tz query param (IANA name), UTC by default
+func parseLocationParam(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tz: %s", tz)
+	}
+	return location, nil
+}
+
+func parseIdParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id")
+	}
+	return id, nil
+}
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// parseTransactionFilter query params: strategyId, coin (symbol), from, to, side (long, short) and fake
+func parseTransactionFilter(r *http.Request, coinRepo repository.Coin) (transaction.TransactionFilter, error) {
+	roundTripFilter, err := parseRoundTripFilter(r, coinRepo)
+	if err != nil {
+		return transaction.TransactionFilter{}, err
+	}
+
+	filter := transaction.TransactionFilter{
+		TradingStrategyId: roundTripFilter.TradingStrategyId,
+		CoinId:            roundTripFilter.CoinId,
+		From:              roundTripFilter.From,
+		To:                roundTripFilter.To,
+		IsFake:            roundTripFilter.IsFake,
+	}
+
+	switch strings.ToLower(r.URL.Query().Get("side")) {
+	case "":
+	case "long":
+		side := futureType.LONG
+		filter.FuturesType = &side
+	case "short":
+		side := futureType.SHORT
+		filter.FuturesType = &side
+	default:
+		return filter, fmt.Errorf("invalid side: %s", r.URL.Query().Get("side"))
+	}
+	return filter, nil
+}
+
+// parsePageParams limit and offset, limit is capped to protect the database
+func parsePageParams(r *http.Request) (int, int, error) {
+	limit, offset := defaultPageLimit, 0
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit: %s", value)
+		}
+		limit = parsed
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("invalid offset: %s", value)
+		}
+		offset = parsed
+	}
+	return limit, offset, nil
+}
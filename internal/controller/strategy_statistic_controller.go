@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/statistics"
+
+	"go.uber.org/zap"
+)
+
+type StrategyStatisticController struct {
+	strategyRepo     repository.TradingStrategy
+	coinRepo         repository.Coin
+	statisticService *statistics.StrategyStatisticService
+}
+
+func NewStrategyStatisticController(
+	strategyRepo repository.TradingStrategy,
+	coinRepo repository.Coin,
+	statisticService *statistics.StrategyStatisticService,
+) *StrategyStatisticController {
+	return &StrategyStatisticController{
+		strategyRepo:     strategyRepo,
+		coinRepo:         coinRepo,
+		statisticService: statisticService,
+	}
+}
+
+func (c *StrategyStatisticController) GetStatistic(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseRoundTripFilter(r, c.coinRepo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	strategy, err := c.strategyRepo.GetByID(filter.TradingStrategyId)
+	if err != nil || strategy == nil {
+		http.Error(w, "strategy not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := c.statisticService.GetStatistic(filter)
+	if err != nil {
+		zap.S().Errorf("Error during GetStatistic of strategy %d: %s", filter.TradingStrategyId, err.Error())
+		http.Error(w, "failed to calculate statistic", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
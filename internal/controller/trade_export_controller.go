@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"tradingViewWebhookBot/internal/constants/exportFormat"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/export"
+
+	"go.uber.org/zap"
+)
+
+type TradeExportController struct {
+	coinRepo           repository.Coin
+	tradeExportService *export.TradeExportService
+}
+
+func NewTradeExportController(
+	coinRepo repository.Coin,
+	tradeExportService *export.TradeExportService,
+) *TradeExportController {
+	return &TradeExportController{
+		coinRepo:           coinRepo,
+		tradeExportService: tradeExportService,
+	}
+}
+
+// ExportTrades query params: format (csv, jsonl, koinly, cointracking), strategyId and the round trip filter params
+func (c *TradeExportController) ExportTrades(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseRoundTripFilter(r, c.coinRepo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := exportFormat.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = exportFormat.CSV
+	}
+	if !exportFormat.IsValid(format) {
+		http.Error(w, fmt.Sprintf("invalid format: %s", format), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", exportFormat.GetContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=trades_%s_%s.%s",
+		format, time.Now().UTC().Format("20060102"), exportFormat.GetFileExtension(format)))
+
+	if _, err := c.tradeExportService.Export(w, filter, format); err != nil {
+		zap.S().Errorf("Error during trades export: %s", err.Error())
+		http.Error(w, "failed to export trades", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	strategyDto "tradingViewWebhookBot/internal/dto/strategy"
+	"tradingViewWebhookBot/internal/service/strategy"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type TradingStrategyController struct {
+	strategyService *strategy.TradingStrategyService
+}
+
+func NewTradingStrategyController(strategyService *strategy.TradingStrategyService) *TradingStrategyController {
+	return &TradingStrategyController{
+		strategyService: strategyService,
+	}
+}
+
+func (c *TradingStrategyController) List(w http.ResponseWriter, r *http.Request) {
+	strategies, err := c.strategyService.List()
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+	writeJson(w, http.StatusOK, strategies)
+}
+
+func (c *TradingStrategyController) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.strategyService.Get(id)
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+	writeJson(w, http.StatusOK, result)
+}
+
+func (c *TradingStrategyController) Create(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeStrategyRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := c.strategyService.Create(request)
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+	zap.S().Infof("Trading strategy %d [%s] created", result.Id, result.Tag)
+	writeJson(w, http.StatusCreated, result)
+}
+
+func (c *TradingStrategyController) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request, ok := decodeStrategyRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := c.strategyService.Update(id, request)
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+	zap.S().Infof("Trading strategy %d [%s] updated", result.Id, result.Tag)
+	writeJson(w, http.StatusOK, result)
+}
+
+func (c *TradingStrategyController) Enable(w http.ResponseWriter, r *http.Request) {
+	c.setEnabled(w, r, true)
+}
+
+func (c *TradingStrategyController) Disable(w http.ResponseWriter, r *http.Request) {
+	c.setEnabled(w, r, false)
+}
+
+func (c *TradingStrategyController) setEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.strategyService.SetEnabled(id, enabled)
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+	zap.S().Infof("Trading strategy %d [%s] enabled: %v", result.Id, result.Tag, enabled)
+	writeJson(w, http.StatusOK, result)
+}
+
+func (c *TradingStrategyController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIdParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.strategyService.Delete(id); err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+	zap.S().Infof("Trading strategy %d deleted", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeStrategyRequest(w http.ResponseWriter, r *http.Request) (strategyDto.TradingStrategyRequestDto, bool) {
+	var request strategyDto.TradingStrategyRequestDto
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return request, false
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return request, false
+	}
+	return request, true
+}
+
+func writeStrategyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, strategy.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, strategy.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, strategy.ErrTagAlreadyExists), errors.Is(err, strategy.ErrOpenedPositions),
+		errors.Is(err, strategy.ErrHasTransactions):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		zap.S().Errorf("Trading strategy request failed: %s", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
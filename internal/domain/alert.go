@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type Alert struct {
+	Id int64 `db:"id" json:"id"`
+
+	/* Tag and ticker of the parsed alert, empty if the payload is not valid */
+	Tag    string `db:"tag" json:"tag"`
+	Ticker string `db:"ticker" json:"ticker"`
+
+	/* Raw request body as it was received */
+	Payload string `db:"payload" json:"payload"`
+
+	/* JSON array of the verdicts of the strategy alert filters, empty if the alert was not filtered */
+	FilterVerdicts string `db:"filter_verdicts" json:"filter_verdicts"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
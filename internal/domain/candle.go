@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Candle closed OHLCV candle of the coin
+type Candle struct {
+	CoinId int64 `db:"coin_id" json:"coin_id"`
+
+	/* Bybit interval in minutes, e.g. 15 */
+	Interval string    `db:"candle_interval" json:"interval"`
+	StartAt  time.Time `db:"start_at" json:"start_at"`
+
+	Open     float64 `db:"open" json:"open"`
+	High     float64 `db:"high" json:"high"`
+	Low      float64 `db:"low" json:"low"`
+	Close    float64 `db:"close" json:"close"`
+	Volume   float64 `db:"volume" json:"volume"`
+	Turnover float64 `db:"turnover" json:"turnover"`
+}
@@ -4,4 +4,14 @@ type Coin struct {
 	Id     int64  `db:"id" json:"id"`
 	Name   string `db:"coin_name" json:"name"`
 	Symbol string `db:"symbol" json:"symbol"`
+
+	/* Disabled coins are not opened by alerts, opened positions are still closed */
+	Enabled bool `db:"enabled" json:"enabled"`
+
+	/* Instrument trading rules of the exchange, 0 - unknown */
+	TickSize    float64 `db:"tick_size" json:"tick_size"`
+	QtyStep     float64 `db:"qty_step" json:"qty_step"`
+	MinOrderQty float64 `db:"min_order_qty" json:"min_order_qty"`
+	MaxOrderQty float64 `db:"max_order_qty" json:"max_order_qty"`
+	MinNotional float64 `db:"min_notional" json:"min_notional"`
 }
@@ -0,0 +1,10 @@
+package domain
+
+type CoinAlias struct {
+	Id int64 `db:"id" json:"id"`
+
+	/* TradingView exchange prefix (BYBIT, BINANCE), empty - alias of any exchange */
+	Exchange string `db:"exchange" json:"exchange"`
+	Alias    string `db:"alias" json:"alias"`
+	CoinId   int64  `db:"coin_id" json:"coin_id"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// FailedMessage notification which was not delivered after all retries
+type FailedMessage struct {
+	Id int64 `db:"id" json:"id"`
+
+	/* Delivery channel, e.g. telegram */
+	Channel string `db:"channel" json:"channel"`
+	ChatId  string `db:"chat_id" json:"chat_id"`
+	Text    string `db:"text" json:"text"`
+
+	/* Last delivery error */
+	Error    string `db:"error" json:"error"`
+	Attempts int    `db:"attempts" json:"attempts"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	/* Set when the message is queued again */
+	ReplayedAt sql.NullTime `db:"replayed_at" json:"-"`
+}
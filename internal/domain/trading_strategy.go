@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type TradingStrategy struct {
 	Id          int64     `json:"id" db:"id"`
@@ -10,4 +13,46 @@ type TradingStrategy struct {
 	Enabled     bool      `json:"enabled" db:"enabled"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	/* Close positions opened longer than this period. 0 - without limit */
+	MaxHoldingMinutes int `json:"max_holding_minutes" db:"max_holding_minutes"`
+
+	/* Comma separated UTC times (HH:MM) when all positions of the strategy must be closed */
+	SessionEndTimes string `json:"session_end_times" db:"session_end_times"`
+
+	/* Comma separated week days (Mon,Tue,...) for SessionEndTimes, empty - every day */
+	SessionEndDays string `json:"session_end_days" db:"session_end_days"`
+
+	/* Comma separated coin symbols the strategy may trade, empty - any coin */
+	AllowedSymbols string `json:"allowed_symbols" db:"allowed_symbols"`
+
+	/* JSON array of the filters which must pass before an alert opens a position, empty - alerts are not filtered */
+	AlertFilters string `json:"alert_filters" db:"alert_filters"`
+}
+
+func (s *TradingStrategy) HasTimeExits() bool {
+	return s.MaxHoldingMinutes > 0 || s.SessionEndTimes != ""
+}
+
+func (s *TradingStrategy) GetAllowedSymbols() []string {
+	var result []string
+	for _, symbol := range strings.Split(s.AllowedSymbols, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			result = append(result, symbol)
+		}
+	}
+	return result
+}
+
+func (s *TradingStrategy) IsSymbolAllowed(symbol string) bool {
+	allowedSymbols := s.GetAllowedSymbols()
+	if len(allowedSymbols) == 0 {
+		return true
+	}
+	for _, allowedSymbol := range allowedSymbols {
+		if strings.EqualFold(allowedSymbol, symbol) {
+			return true
+		}
+	}
+	return false
 }
@@ -40,9 +40,18 @@ type Transaction struct {
 	/* SELL transaction must contain link to BUY transaction and the opposite */
 	RelatedTransactionId sql.NullInt64 `db:"related_transaction_id"`
 
-	/* SELL.TotalCost - BUY.TotalCost - 2 commissions */
+	/* GrossProfit - fees of both legs - FundingFee, in cents */
 	Profit sql.NullInt64
 
+	/* SELL.TotalCost - BUY.TotalCost, in cents */
+	GrossProfit sql.NullInt64 `db:"gross_profit"`
+
+	/* Real fee of the order executions in USD, null if it was not fetched from the exchange */
+	ExecFee sql.NullFloat64 `db:"exec_fee"`
+
+	/* Funding settled while the position was opened in USD: positive - paid, negative - received */
+	FundingFee sql.NullFloat64 `db:"funding_fee"`
+
 	/* (Profit)/BUY.TotalCost * 100% */
 	PercentProfit sql.NullFloat64 `db:"percent_profit"`
 
@@ -53,6 +62,35 @@ type Transaction struct {
 	IsFake bool `db:"fake"`
 
 	TradingKey string `db:"trading_key"`
+
+	/* Why the position was closed, set on the close transaction only */
+	ExitReason sql.NullString `db:"exit_reason"`
+
+	/* Open transaction of a reversed position contains link to the close transaction of the previous side */
+	ReversedTransactionId sql.NullInt64 `db:"reversed_transaction_id"`
+
+	/* Final status of the exchange order, e.g. Filled or PartiallyFilledCanceled */
+	OrderStatus sql.NullString `db:"order_status"`
+
+	/* Amount sent to the exchange, Amount is the executed part of it */
+	RequestedAmount sql.NullFloat64 `db:"requested_amount"`
+
+	/* Remainder of a partially closed position contains link to the original open transaction */
+	ParentTransactionId sql.NullInt64 `db:"parent_transaction_id"`
+
+	/* Alert which opened or closed the position, empty for watchdog and manual closes */
+	AlertId sql.NullInt64 `db:"alert_id"`
+
+	/* Moment the close was claimed by ClaimForClose, stays set after the close */
+	ClosingAt sql.NullTime `db:"closing_at"`
+}
+
+// GetFee real execution fee when it's known, otherwise the estimated commission
+func (t *Transaction) GetFee() float64 {
+	if t.ExecFee.Valid {
+		return t.ExecFee.Float64
+	}
+	return t.Commission
 }
 
 func (t *Transaction) String() string {
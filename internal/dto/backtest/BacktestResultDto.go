@@ -0,0 +1,25 @@
+package backtest
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/statistic"
+	"tradingViewWebhookBot/internal/dto/trade"
+)
+
+// BacktestResultDto money values are in USD
+type BacktestResultDto struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Interval string    `json:"interval"`
+
+	InitialBalance float64 `json:"initial_balance"`
+	FinalBalance   float64 `json:"final_balance"`
+
+	AlertsCount    int `json:"alerts_count"`
+	RejectedAlerts int `json:"rejected_alerts"`
+
+	Statistic       *statistic.StrategyStatisticDto `json:"statistic"`
+	Trades          []transaction.RoundTripDto      `json:"trades"`
+	OpenedPositions []trade.TransactionDto          `json:"opened_positions"`
+}
@@ -0,0 +1,57 @@
+package backtest
+
+import "time"
+
+// ReplayResultDto live and replayed transactions of the stored alerts of the period
+type ReplayResultDto struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Interval string    `json:"interval"`
+
+	AlertsCount      int `json:"alerts_count"`
+	MismatchedAlerts int `json:"mismatched_alerts"`
+	SeededPositions  int `json:"seeded_positions"`
+
+	/* Mismatched alerts only unless all alerts are requested */
+	Alerts []AlertReplayDto `json:"alerts"`
+
+	/* Transactions without alert: watchdog and manual closes */
+	WithoutAlert AlertReplayDto `json:"without_alert"`
+}
+
+type AlertReplayDto struct {
+	AlertId int64      `json:"alert_id,omitempty"`
+	Time    *time.Time `json:"time,omitempty"`
+	Tag     string     `json:"tag,omitempty"`
+	Ticker  string     `json:"ticker,omitempty"`
+
+	Matched     bool             `json:"matched"`
+	Live        []TradeActionDto `json:"live"`
+	Replay      []TradeActionDto `json:"replay"`
+	Differences []string         `json:"differences,omitempty"`
+}
+
+// TradeActionDto one transaction, Action is open or close
+type TradeActionDto struct {
+	TransactionId int64     `json:"transaction_id"`
+	Action        string    `json:"action"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	TradingKey    string    `json:"trading_key,omitempty"`
+	Amount        float64   `json:"amount"`
+	Price         float64   `json:"price"`
+	ExitReason    string    `json:"exit_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// String what is compared between live and replay, amounts and prices of the paper exchange differ anyway
+func (d TradeActionDto) String() string {
+	result := d.Action + " " + d.Side + " " + d.Symbol
+	if d.TradingKey != "" {
+		result += " [" + d.TradingKey + "]"
+	}
+	if d.ExitReason != "" {
+		result += " by " + d.ExitReason
+	}
+	return result
+}
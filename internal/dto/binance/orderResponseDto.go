@@ -72,3 +72,7 @@ func (d OrderResponseBinanceDto) GetAmount() float64 {
 func (d OrderResponseBinanceDto) GetCreatedAt() *time.Time {
 	return nil
 }
+
+func (d OrderResponseBinanceDto) GetOrderId() string {
+	return strconv.Itoa(d.OrderId)
+}
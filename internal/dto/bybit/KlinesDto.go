@@ -81,3 +81,11 @@ func (dto *KlineDto) GetLow() float64 {
 func (dto *KlineDto) GetClose() float64 {
 	return dto.Close
 }
+
+func (dto *KlineDto) GetVolume() float64 {
+	return dto.Volume
+}
+
+func (dto *KlineDto) GetTurnover() float64 {
+	return dto.Turnover
+}
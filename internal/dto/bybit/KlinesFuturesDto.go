@@ -99,3 +99,11 @@ func (dto *KlineFuturesDto) GetLow() float64 {
 func (dto *KlineFuturesDto) GetClose() float64 {
 	return dto.Close
 }
+
+func (dto *KlineFuturesDto) GetVolume() float64 {
+	return dto.Volume
+}
+
+func (dto *KlineFuturesDto) GetTurnover() float64 {
+	return dto.Turnover
+}
@@ -0,0 +1,83 @@
+package market
+
+import "strconv"
+
+const STATUS_TRADING = "Trading"
+
+type InstrumentsInfoDto struct {
+	RetCode int    `mapstructure:"retCode"`
+	RetMsg  string `mapstructure:"retMsg"`
+	Result  struct {
+		Category       string              `mapstructure:"category"`
+		List           []InstrumentInfoDto `mapstructure:"list"`
+		NextPageCursor string              `mapstructure:"nextPageCursor"`
+	} `mapstructure:"result"`
+	Time int64 `mapstructure:"time"`
+}
+
+type InstrumentInfoDto struct {
+	Symbol       string `mapstructure:"symbol"`
+	ContractType string `mapstructure:"contractType"`
+	Status       string `mapstructure:"status"`
+	BaseCoin     string `mapstructure:"baseCoin"`
+	QuoteCoin    string `mapstructure:"quoteCoin"`
+	SettleCoin   string `mapstructure:"settleCoin"`
+	PriceScale   string `mapstructure:"priceScale"`
+	PriceFilter  struct {
+		MinPrice string `mapstructure:"minPrice"`
+		MaxPrice string `mapstructure:"maxPrice"`
+		TickSize string `mapstructure:"tickSize"`
+	} `mapstructure:"priceFilter"`
+	LotSizeFilter struct {
+		MaxOrderQty         string `mapstructure:"maxOrderQty"`
+		MaxMktOrderQty      string `mapstructure:"maxMktOrderQty"`
+		MinOrderQty         string `mapstructure:"minOrderQty"`
+		QtyStep             string `mapstructure:"qtyStep"`
+		MinNotionalValue    string `mapstructure:"minNotionalValue"`
+		PostOnlyMaxOrderQty string `mapstructure:"postOnlyMaxOrderQty"`
+	} `mapstructure:"lotSizeFilter"`
+}
+
+func (d *InstrumentInfoDto) GetSymbol() string {
+	return d.Symbol
+}
+
+func (d *InstrumentInfoDto) GetBaseCoin() string {
+	return d.BaseCoin
+}
+
+func (d *InstrumentInfoDto) IsTrading() bool {
+	return d.Status == STATUS_TRADING
+}
+
+func (d *InstrumentInfoDto) GetTickSize() float64 {
+	return parseFloat(d.PriceFilter.TickSize)
+}
+
+func (d *InstrumentInfoDto) GetQtyStep() float64 {
+	return parseFloat(d.LotSizeFilter.QtyStep)
+}
+
+func (d *InstrumentInfoDto) GetMinOrderQty() float64 {
+	return parseFloat(d.LotSizeFilter.MinOrderQty)
+}
+
+// GetMaxOrderQty market orders have a lower limit than limit orders, the bot places only market ones
+func (d *InstrumentInfoDto) GetMaxOrderQty() float64 {
+	if maxMarketQty := parseFloat(d.LotSizeFilter.MaxMktOrderQty); maxMarketQty > 0 {
+		return maxMarketQty
+	}
+	return parseFloat(d.LotSizeFilter.MaxOrderQty)
+}
+
+func (d *InstrumentInfoDto) GetMinNotional() float64 {
+	return parseFloat(d.LotSizeFilter.MinNotionalValue)
+}
+
+func parseFloat(value string) float64 {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
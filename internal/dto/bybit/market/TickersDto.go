@@ -0,0 +1,36 @@
+package market
+
+type TickersDto struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		Category string      `json:"category"`
+		List     []TickerDto `json:"list"`
+	} `json:"result"`
+	Time int64 `json:"time"`
+}
+
+type TickerDto struct {
+	Symbol      string `json:"symbol"`
+	LastPrice   string `json:"lastPrice"`
+	MarkPrice   string `json:"markPrice"`
+	Bid1Price   string `json:"bid1Price"`
+	Ask1Price   string `json:"ask1Price"`
+	FundingRate string `json:"fundingRate"`
+}
+
+func (d *TickerDto) GetBidPrice() float64 {
+	return parseFloat(d.Bid1Price)
+}
+
+func (d *TickerDto) GetAskPrice() float64 {
+	return parseFloat(d.Ask1Price)
+}
+
+func (d *TickerDto) GetLastPrice() float64 {
+	return parseFloat(d.LastPrice)
+}
+
+func (d *TickerDto) GetFundingRate() float64 {
+	return parseFloat(d.FundingRate)
+}
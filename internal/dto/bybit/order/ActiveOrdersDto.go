@@ -63,3 +63,7 @@ func (d *ActiveOrderDto) GetAmount() float64 {
 func (d *ActiveOrderDto) GetCreatedAt() *time.Time {
 	return &d.CreatedTime
 }
+
+func (d *ActiveOrderDto) GetOrderId() string {
+	return d.OrderId
+}
@@ -0,0 +1,38 @@
+package order
+
+import "strconv"
+
+type ExecutionListDto struct {
+	RetCode int    `mapstructure:"retCode"`
+	RetMsg  string `mapstructure:"retMsg"`
+	Result  struct {
+		Category       string         `mapstructure:"category"`
+		List           []ExecutionDto `mapstructure:"list"`
+		NextPageCursor string         `mapstructure:"nextPageCursor"`
+	} `mapstructure:"result"`
+	Time int64 `mapstructure:"time"`
+}
+
+type ExecutionDto struct {
+	Symbol    string `mapstructure:"symbol"`
+	OrderId   string `mapstructure:"orderId"`
+	Side      string `mapstructure:"side"`
+	ExecId    string `mapstructure:"execId"`
+	ExecPrice string `mapstructure:"execPrice"`
+	ExecQty   string `mapstructure:"execQty"`
+	ExecValue string `mapstructure:"execValue"`
+	ExecFee   string `mapstructure:"execFee"`
+	FeeRate   string `mapstructure:"feeRate"`
+	ExecType  string `mapstructure:"execType"`
+	ExecTime  string `mapstructure:"execTime"`
+}
+
+// CalculateExecFee sum of fees of all executions, negative fee is a maker rebate
+func (d *ExecutionListDto) CalculateExecFee() float64 {
+	sum := float64(0)
+	for _, execution := range d.Result.List {
+		fee, _ := strconv.ParseFloat(execution.ExecFee, 64)
+		sum += fee
+	}
+	return sum
+}
@@ -59,3 +59,7 @@ func (d *FuturesOrderResponseDto) GetAmount() float64 {
 func (d *FuturesOrderResponseDto) GetCreatedAt() *time.Time {
 	return &d.Result.CreatedAt
 }
+
+func (d *FuturesOrderResponseDto) GetOrderId() string {
+	return d.Result.OrderId
+}
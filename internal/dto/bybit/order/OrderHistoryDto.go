@@ -6,6 +6,17 @@ import (
 	"time"
 )
 
+// Order statuses of Bybit v5 API
+const (
+	STATUS_NEW                       = "New"
+	STATUS_PARTIALLY_FILLED          = "PartiallyFilled"
+	STATUS_FILLED                    = "Filled"
+	STATUS_PARTIALLY_FILLED_CANCELED = "PartiallyFilledCanceled"
+	STATUS_CANCELLED                 = "Cancelled"
+	STATUS_REJECTED                  = "Rejected"
+	STATUS_DEACTIVATED               = "Deactivated"
+)
+
 type OrderHistoryDto struct {
 	RetCode int    `mapstructure:"retCode"`
 	RetMsg  string `mapstructure:"retMsg"`
@@ -35,6 +46,7 @@ type OrderDetails struct {
 	OrderType          string `mapstructure:"orderType"`
 	Price              string `mapstructure:"price"`
 	Qty                string `mapstructure:"qty"`
+	RejectReason       string `mapstructure:"rejectReason"`
 	Side               string `mapstructure:"side"`
 	Symbol             string `mapstructure:"symbol"`
 	TimeInForce        string `mapstructure:"timeInForce"`
@@ -47,6 +59,9 @@ func (d *OrderDetails) CalculateAvgPrice() float64 {
 }
 
 func (d *OrderDetails) CalculateTotalCost() float64 {
+	if cumExecValue, err := strconv.ParseFloat(d.CumExecValue, 64); err == nil && cumExecValue > 0 {
+		return cumExecValue
+	}
 	return float64(d.CalculateAvgPrice()) * d.GetAmount()
 }
 
@@ -54,11 +69,29 @@ func (d *OrderDetails) CalculateCommissionInUsd() float64 {
 	return (float64(d.CalculateTotalCost()) * viper.GetFloat64("api.bybit.commission"))
 }
 
+// GetAmount executed quantity, it is less than Qty for partially filled orders
 func (d *OrderDetails) GetAmount() float64 {
+	amount, _ := strconv.ParseFloat(d.CumExecQty, 64)
+	return amount
+}
+
+func (d *OrderDetails) GetRequestedAmount() float64 {
 	amount, _ := strconv.ParseFloat(d.Qty, 64)
 	return amount
 }
 
+func (d *OrderDetails) GetOrderId() string {
+	return d.OrderId
+}
+
+func (d *OrderDetails) GetOrderStatus() string {
+	return d.OrderStatus
+}
+
+func (d *OrderDetails) IsPartiallyFilled() bool {
+	return d.GetAmount() < d.GetRequestedAmount()
+}
+
 func (d *OrderDetails) GetCreatedAt() *time.Time {
 	return nil
 }
@@ -84,3 +84,10 @@ func (d *TradeHistoryDto) String() string {
 	return fmt.Sprintf("TradeHistoryDto {CalculateAvgPrice: %v, CalculateTotalCost: %v, CalculateCommissionInUsd: %v, GetAmount: %v, GetCreatedAt: %v}",
 		d.CalculateAvgPrice(), d.CalculateTotalCost(), d.CalculateCommissionInUsd(), d.GetAmount(), d.GetCreatedAt())
 }
+
+func (d *TradeHistoryDto) GetOrderId() string {
+	if len(d.Result) == 0 {
+		return ""
+	}
+	return d.Result[0].OrderId
+}
@@ -81,3 +81,10 @@ func (dto *TradesSummaryDto) GetCreatedAt() *time.Time {
 	timeByMillis := util.GetTimeByMillis(dto.Trades[0].TradeTimeMs)
 	return &timeByMillis
 }
+
+func (dto *TradesSummaryDto) GetOrderId() string {
+	if len(dto.Trades) == 0 {
+		return ""
+	}
+	return dto.Trades[0].OrderId
+}
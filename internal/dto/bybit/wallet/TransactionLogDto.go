@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"math"
+	"strconv"
+)
+
+// Transaction log types of Bybit v5 API
+const (
+	TYPE_TRADE      = "TRADE"
+	TYPE_SETTLEMENT = "SETTLEMENT"
+)
+
+type TransactionLogDto struct {
+	RetCode int    `mapstructure:"retCode"`
+	RetMsg  string `mapstructure:"retMsg"`
+	Result  struct {
+		List           []TransactionLogRecordDto `mapstructure:"list"`
+		NextPageCursor string                    `mapstructure:"nextPageCursor"`
+	} `mapstructure:"result"`
+	Time int64 `mapstructure:"time"`
+}
+
+type TransactionLogRecordDto struct {
+	Id              string `mapstructure:"id"`
+	Symbol          string `mapstructure:"symbol"`
+	Category        string `mapstructure:"category"`
+	Side            string `mapstructure:"side"`
+	TransactionTime string `mapstructure:"transactionTime"`
+	Type            string `mapstructure:"type"`
+	Qty             string `mapstructure:"qty"`
+	Size            string `mapstructure:"size"`
+	Currency        string `mapstructure:"currency"`
+	TradePrice      string `mapstructure:"tradePrice"`
+	Funding         string `mapstructure:"funding"`
+	Fee             string `mapstructure:"fee"`
+	CashFlow        string `mapstructure:"cashFlow"`
+	Change          string `mapstructure:"change"`
+	OrderId         string `mapstructure:"orderId"`
+}
+
+// IsSettlementOf side is Buy for the long position and Sell for the short one
+func (d *TransactionLogRecordDto) IsSettlementOf(symbol string, side string) bool {
+	return d.Symbol == symbol && d.Side == side
+}
+
+// GetFundingForAmount funding of the settlement related to the amount of the position.
+// Positive value is paid funding, negative value is received funding.
+func (d *TransactionLogRecordDto) GetFundingForAmount(amount float64) float64 {
+	funding, _ := strconv.ParseFloat(d.Funding, 64)
+	size, _ := strconv.ParseFloat(d.Size, 64)
+	size = math.Abs(size)
+	if size == 0 || amount >= size {
+		return funding
+	}
+	return funding * amount / size
+}
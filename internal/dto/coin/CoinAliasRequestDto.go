@@ -0,0 +1,8 @@
+package coin
+
+type CoinAliasRequestDto struct {
+	// Exchange TradingView exchange prefix, empty - alias of any exchange
+	Exchange   string `json:"exchange" validate:"max=50"`
+	Alias      string `json:"alias" validate:"required,max=100,excludes=:"`
+	CoinSymbol string `json:"coin_symbol" validate:"required"`
+}
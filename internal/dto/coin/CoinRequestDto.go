@@ -0,0 +1,7 @@
+package coin
+
+type CoinRequestDto struct {
+	Symbol string `json:"symbol" validate:"required,max=50"`
+	// Name is the base coin of the instrument when omitted
+	Name string `json:"name" validate:"max=255"`
+}
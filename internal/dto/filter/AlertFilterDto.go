@@ -0,0 +1,16 @@
+package filter
+
+import "tradingViewWebhookBot/internal/constants/filterType"
+
+// AlertFilterDto one filter of the strategy chain, e.g. {"type": "ema_trend", "interval": "240", "period": 200}.
+// Interval and Period are used by candle based filters, Max 0 means no upper bound of atr_percent.
+type AlertFilterDto struct {
+	Type     filterType.FilterType `json:"type" validate:"required,oneof=ema_trend atr_percent spread funding_rate price_deviation"`
+	Interval string                `json:"interval,omitempty"`
+	Period   int                   `json:"period,omitempty" validate:"min=0"`
+	Min      float64               `json:"min,omitempty" validate:"min=0"`
+	Max      float64               `json:"max,omitempty" validate:"min=0"`
+
+	/* The alert passes when the filter can't be checked, e.g. the exchange doesn't respond. Rejected by default */
+	FailOpen bool `json:"fail_open,omitempty"`
+}
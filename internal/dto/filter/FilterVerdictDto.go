@@ -0,0 +1,12 @@
+package filter
+
+import "tradingViewWebhookBot/internal/constants/filterType"
+
+// FilterVerdictDto result of one filter for the alert, Value is what the filter measured, e.g. the spread in percents
+type FilterVerdictDto struct {
+	Type   filterType.FilterType `json:"type"`
+	Passed bool                  `json:"passed"`
+	Value  float64               `json:"value"`
+	Reason string                `json:"reason,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
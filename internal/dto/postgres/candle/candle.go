@@ -0,0 +1,9 @@
+package candle
+
+import "time"
+
+// CandleGapDto missing candles between two stored ones, From and To are starts of the stored candles
+type CandleGapDto struct {
+	From time.Time `db:"gap_from" json:"from"`
+	To   time.Time `db:"gap_to" json:"to"`
+}
@@ -1,7 +1,9 @@
 package transaction
 
 import (
+	"database/sql"
 	"time"
+	"tradingViewWebhookBot/internal/constants/futureType"
 )
 
 // TransactionProfitPercentsDto represents profit percentages for transactions
@@ -17,3 +19,51 @@ type PairTransactionProfitPercentsDto struct {
 	ProfitSum                  int64   `db:"profit_sum"`
 	OrdersSize                 int     `db:"orders_size"`
 }
+
+// RoundTripDto open and close transactions of one closed position
+type RoundTripDto struct {
+	OpenTransactionId   int64                  `db:"open_transaction_id" json:"open_transaction_id"`
+	CloseTransactionId  int64                  `db:"close_transaction_id" json:"close_transaction_id"`
+	TradingStrategyId   int64                  `db:"trading_strategy_id" json:"trading_strategy_id"`
+	TradingStrategyName string                 `db:"trading_strategy_name" json:"trading_strategy_name"`
+	CoinId              int64                  `db:"coin_id" json:"coin_id"`
+	CoinSymbol          string                 `db:"coin_symbol" json:"coin_symbol"`
+	FuturesType         futureType.FuturesType `db:"futures_type" json:"futures_type"`
+	TradingKey          string                 `db:"trading_key" json:"trading_key"`
+	Amount              float64                `db:"amount" json:"amount"`
+	OpenPrice           float64                `db:"open_price" json:"open_price"`
+	ClosePrice          float64                `db:"close_price" json:"close_price"`
+	OpenedAt            time.Time              `db:"opened_at" json:"opened_at"`
+	ClosedAt            time.Time              `db:"closed_at" json:"closed_at"`
+	OpenFee             float64                `db:"open_fee" json:"open_fee"`
+	CloseFee            float64                `db:"close_fee" json:"close_fee"`
+	FundingFee          float64                `db:"funding_fee" json:"funding_fee"`
+	GrossProfit         int64                  `db:"gross_profit" json:"gross_profit"`
+	Profit              int64                  `db:"profit" json:"profit"`
+	PercentProfit       float64                `db:"percent_profit" json:"percent_profit"`
+	IsFake              bool                   `db:"fake" json:"fake"`
+	ExitReason          sql.NullString         `db:"exit_reason" json:"-"`
+}
+
+func (d *RoundTripDto) GetHoldingTime() time.Duration {
+	return d.ClosedAt.Sub(d.OpenedAt)
+}
+
+// RoundTripFilter empty fields are not applied, From and To filter by close time
+type RoundTripFilter struct {
+	TradingStrategyId int64
+	CoinId            int64
+	From              *time.Time
+	To                *time.Time
+	IsFake            *bool
+}
+
+// TransactionFilter empty fields are not applied, From and To filter by created time
+type TransactionFilter struct {
+	TradingStrategyId int64
+	CoinId            int64
+	From              *time.Time
+	To                *time.Time
+	FuturesType       *futureType.FuturesType
+	IsFake            *bool
+}
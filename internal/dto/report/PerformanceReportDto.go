@@ -0,0 +1,46 @@
+package report
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/constants/reportPeriod"
+	"tradingViewWebhookBot/internal/dto/trade"
+)
+
+// PerformanceReportDto trades closed within [From, To), money values are in USD
+type PerformanceReportDto struct {
+	Period reportPeriod.ReportPeriod
+	From   time.Time
+	To     time.Time
+
+	Trades int
+	Wins   int
+	// WinRate in percent
+	WinRate float64
+	// RealisedProfit net of fees and funding
+	RealisedProfit float64
+	// Fees of both legs and funding of the closed trades
+	Fees float64
+
+	Strategies []ProfitRowDto
+	Coins      []ProfitRowDto
+	// DailyProfit realised profit of every day, for reports longer than a day
+	DailyProfit []DailyProfitDto
+
+	OpenedPositions  []trade.PositionDto
+	UnrealisedProfit float64
+
+	// WalletBalance available balance, nil if it was not fetched
+	WalletBalance *float64
+}
+
+// ProfitRowDto realised profit of a strategy or a coin
+type ProfitRowDto struct {
+	Name   string
+	Trades int
+	Profit float64
+}
+
+type DailyProfitDto struct {
+	Date   time.Time
+	Profit float64
+}
@@ -0,0 +1,13 @@
+package statistic
+
+import "time"
+
+// EquityPointDto realised P&L of round trips closed within the bucket, money values are in USD
+type EquityPointDto struct {
+	Period      time.Time `json:"period"`
+	CoinSymbol  string    `json:"coin_symbol,omitempty"`
+	TradesCount int       `json:"trades_count"`
+	Profit      float64   `json:"profit"`
+	Equity      float64   `json:"equity"`
+	Drawdown    float64   `json:"drawdown"`
+}
@@ -0,0 +1,35 @@
+package statistic
+
+import "time"
+
+// StrategyStatisticDto performance of closed round trips, money values are in USD
+type StrategyStatisticDto struct {
+	TradingStrategyId int64     `json:"trading_strategy_id"`
+	From              time.Time `json:"from"`
+	To                time.Time `json:"to"`
+
+	TradesCount    int `json:"trades_count"`
+	WinsCount      int `json:"wins_count"`
+	LossesCount    int `json:"losses_count"`
+	BreakevenCount int `json:"breakeven_count"`
+
+	GrossProfit float64 `json:"gross_profit"`
+	NetProfit   float64 `json:"net_profit"`
+	Fees        float64 `json:"fees"`
+	Funding     float64 `json:"funding"`
+
+	WinRate float64 `json:"win_rate"`
+	/* Gross wins to gross losses, null without losses as the ratio is undefined then */
+	ProfitFactor *float64 `json:"profit_factor"`
+	Expectancy   float64  `json:"expectancy"`
+	AverageWin   float64  `json:"average_win"`
+	AverageLoss  float64  `json:"average_loss"`
+
+	MaxDrawdown         float64 `json:"max_drawdown"`
+	SharpeRatio         float64 `json:"sharpe_ratio"`
+	SortinoRatio        float64 `json:"sortino_ratio"`
+	LongestLosingStreak int     `json:"longest_losing_streak"`
+
+	ExposureSeconds int64   `json:"exposure_seconds"`
+	ExposurePercent float64 `json:"exposure_percent"`
+}
@@ -0,0 +1,18 @@
+package strategy
+
+import "tradingViewWebhookBot/internal/dto/filter"
+
+// TradingStrategyRequestDto body of create and update requests, Enabled is true when omitted on create
+type TradingStrategyRequestDto struct {
+	Name              string   `json:"name" validate:"required,max=255"`
+	Description       string   `json:"description"`
+	Tag               string   `json:"tag" validate:"required,max=100"`
+	Enabled           *bool    `json:"enabled"`
+	MaxHoldingMinutes int      `json:"max_holding_minutes" validate:"min=0"`
+	SessionEndTimes   string   `json:"session_end_times"`
+	SessionEndDays    string   `json:"session_end_days"`
+	AllowedSymbols    []string `json:"allowed_symbols" validate:"dive,required"`
+
+	/* Filters which must pass before an alert opens a position, evaluated in order */
+	AlertFilters []filter.AlertFilterDto `json:"alert_filters" validate:"dive"`
+}
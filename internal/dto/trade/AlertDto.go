@@ -0,0 +1,9 @@
+package trade
+
+import "tradingViewWebhookBot/internal/domain"
+
+// AlertDto received alert and transactions opened or closed by it
+type AlertDto struct {
+	domain.Alert
+	Transactions []TransactionDto `json:"transactions"`
+}
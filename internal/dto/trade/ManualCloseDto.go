@@ -0,0 +1,24 @@
+package trade
+
+// ManualCloseResultDto positions which were closed or would be closed on dry run
+type ManualCloseResultDto struct {
+	DryRun    bool                  `json:"dry_run"`
+	Positions []ManualClosePosition `json:"positions"`
+	Closed    int                   `json:"closed"`
+	Failed    int                   `json:"failed"`
+	/* Positions closed or being closed concurrently by the watchdog, an alert or another manual close */
+	Skipped int `json:"skipped"`
+}
+
+type ManualClosePosition struct {
+	TransactionId      int64   `json:"transaction_id"`
+	TradingStrategyId  int64   `json:"trading_strategy_id"`
+	CoinSymbol         string  `json:"coin_symbol"`
+	Side               string  `json:"side"`
+	Amount             float64 `json:"amount"`
+	OpenPrice          float64 `json:"open_price"`
+	CurrentPrice       float64 `json:"current_price"`
+	EstimatedProfit    float64 `json:"estimated_profit"`
+	CloseTransactionId int64   `json:"close_transaction_id,omitempty"`
+	Error              string  `json:"error,omitempty"`
+}
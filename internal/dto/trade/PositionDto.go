@@ -0,0 +1,40 @@
+package trade
+
+import "time"
+
+// PositionDto opened transaction valued by the current price, money values are in USD
+type PositionDto struct {
+	TransactionId       int64     `json:"transaction_id"`
+	TradingStrategyId   int64     `json:"trading_strategy_id"`
+	TradingStrategyName string    `json:"trading_strategy_name"`
+	CoinSymbol          string    `json:"coin_symbol"`
+	Side                string    `json:"side"`
+	TradingKey          string    `json:"trading_key,omitempty"`
+	Amount              float64   `json:"amount"`
+	OpenPrice           float64   `json:"open_price"`
+	CurrentPrice        float64   `json:"current_price"`
+	OpenedAt            time.Time `json:"opened_at"`
+	HoldingSeconds      int64     `json:"holding_seconds"`
+	UnrealisedProfit    float64   `json:"unrealised_profit"`
+	// UnrealisedPercent with leverage, as the strategy sees it
+	UnrealisedPercent float64 `json:"unrealised_percent"`
+	IsFake            bool    `json:"fake"`
+	// PriceError current price is not available, unrealised values are zero
+	PriceError string `json:"price_error,omitempty"`
+}
+
+// RoundTripDetailDto both legs of the closed position
+type RoundTripDetailDto struct {
+	Open  TransactionDto  `json:"open"`
+	Close *TransactionDto `json:"close,omitempty"`
+	// Remainders of the partially closed position which are opened or closed separately
+	Remainders     []TransactionDto `json:"remainders,omitempty"`
+	HoldingSeconds int64            `json:"holding_seconds,omitempty"`
+}
+
+type TransactionPageDto struct {
+	Items  []TransactionDto `json:"items"`
+	Total  int64            `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
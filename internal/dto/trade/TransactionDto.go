@@ -0,0 +1,95 @@
+package trade
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+)
+
+// TransactionDto api view of domain.Transaction, null columns are omitted
+type TransactionDto struct {
+	Id                    int64     `json:"id"`
+	TradingStrategyId     int64     `json:"trading_strategy_id"`
+	CoinId                int64     `json:"coin_id"`
+	CoinSymbol            string    `json:"coin_symbol"`
+	Side                  string    `json:"side"`
+	TradingKey            string    `json:"trading_key,omitempty"`
+	Amount                float64   `json:"amount"`
+	RequestedAmount       *float64  `json:"requested_amount,omitempty"`
+	Price                 float64   `json:"price"`
+	TotalCost             float64   `json:"total_cost"`
+	Commission            float64   `json:"commission"`
+	ExecFee               *float64  `json:"exec_fee,omitempty"`
+	FundingFee            *float64  `json:"funding_fee,omitempty"`
+	GrossProfit           *float64  `json:"gross_profit,omitempty"`
+	Profit                *float64  `json:"profit,omitempty"`
+	PercentProfit         *float64  `json:"percent_profit,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+	ClientOrderId         string    `json:"client_order_id,omitempty"`
+	OrderStatus           string    `json:"order_status,omitempty"`
+	ApiError              string    `json:"api_error,omitempty"`
+	ExitReason            string    `json:"exit_reason,omitempty"`
+	RelatedTransactionId  *int64    `json:"related_transaction_id,omitempty"`
+	ReversedTransactionId *int64    `json:"reversed_transaction_id,omitempty"`
+	ParentTransactionId   *int64    `json:"parent_transaction_id,omitempty"`
+	AlertId               *int64    `json:"alert_id,omitempty"`
+	IsFake                bool      `json:"fake"`
+	IsOpened              bool      `json:"opened"`
+}
+
+// NewTransactionDto profit values are converted from cents to USD
+func NewTransactionDto(transaction *domain.Transaction, coinSymbol string) TransactionDto {
+	dto := TransactionDto{
+		Id:                transaction.Id,
+		TradingStrategyId: transaction.TradingStrategyId.Int64,
+		CoinId:            transaction.CoinId,
+		CoinSymbol:        coinSymbol,
+		Side:              futureType.GetString(transaction.FuturesType),
+		TradingKey:        transaction.TradingKey,
+		Amount:            transaction.Amount,
+		Price:             transaction.Price,
+		TotalCost:         transaction.TotalCost,
+		Commission:        transaction.Commission,
+		CreatedAt:         transaction.CreatedAt,
+		ClientOrderId:     transaction.ClientOrderId.String,
+		OrderStatus:       transaction.OrderStatus.String,
+		ApiError:          transaction.ApiError.String,
+		ExitReason:        transaction.ExitReason.String,
+		IsFake:            transaction.IsFake,
+		IsOpened:          !transaction.RelatedTransactionId.Valid,
+	}
+	if transaction.RequestedAmount.Valid {
+		dto.RequestedAmount = &transaction.RequestedAmount.Float64
+	}
+	if transaction.ExecFee.Valid {
+		dto.ExecFee = &transaction.ExecFee.Float64
+	}
+	if transaction.FundingFee.Valid {
+		dto.FundingFee = &transaction.FundingFee.Float64
+	}
+	if transaction.GrossProfit.Valid {
+		grossProfit := float64(transaction.GrossProfit.Int64) / 100
+		dto.GrossProfit = &grossProfit
+	}
+	if transaction.Profit.Valid {
+		profit := float64(transaction.Profit.Int64) / 100
+		dto.Profit = &profit
+		dto.IsOpened = false
+	}
+	if transaction.AlertId.Valid {
+		dto.AlertId = &transaction.AlertId.Int64
+	}
+	if transaction.PercentProfit.Valid {
+		dto.PercentProfit = &transaction.PercentProfit.Float64
+	}
+	if transaction.RelatedTransactionId.Valid {
+		dto.RelatedTransactionId = &transaction.RelatedTransactionId.Int64
+	}
+	if transaction.ReversedTransactionId.Valid {
+		dto.ReversedTransactionId = &transaction.ReversedTransactionId.Int64
+	}
+	if transaction.ParentTransactionId.Valid {
+		dto.ParentTransactionId = &transaction.ParentTransactionId.Int64
+	}
+	return dto
+}
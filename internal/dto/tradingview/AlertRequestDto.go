@@ -14,6 +14,10 @@ type AlertRequestDto struct {
 	Text         string `json:"text"`
 	Interval     string `json:"interval"`
 	PositionSize string `json:"positionSize"`
+	// TradingKey identifies independent sub-position of the coin, e.g. TradingView {{strategy.order.id}}
+	TradingKey string `json:"tradingKey" validate:"max=100"`
+	// Action is required to close positions in hedge mode, where sell can both open short and close long
+	Action string `json:"action" validate:"omitempty,oneof=open close reverse"`
 }
 
 func (r AlertRequestDto) GetFuturesType() futureType.FuturesType {
@@ -23,9 +27,26 @@ func (r AlertRequestDto) GetFuturesType() futureType.FuturesType {
 	return futureType.LONG
 }
 
+// IsReverse the alert flips an opened position to the opposite side in one order.
+// Either explicit "reverse" action or TradingView {{strategy.position_size}} after the order on the side of the alert:
+// negative after sell, positive after buy. The size of the other sign is left by a partial exit, so it is not a reverse.
+func (r AlertRequestDto) IsReverse() bool {
+	if r.Action != "" {
+		return r.Action == "reverse"
+	}
+	positionSize, err := strconv.ParseFloat(r.PositionSize, 64)
+	if err != nil {
+		return false
+	}
+	if r.GetFuturesType() == futureType.SHORT {
+		return positionSize < 0
+	}
+	return positionSize > 0
+}
+
 func (r AlertRequestDto) String() string {
 	return fmt.Sprintf(
-		"AlertRequest{tag: %s, ticker: %s, price: %s, side: %s, text: %s, interval: %s, positionSize: %s}",
+		"AlertRequest{tag: %s, ticker: %s, price: %s, side: %s, text: %s, interval: %s, positionSize: %s, tradingKey: %s, action: %s}",
 		r.Tag,
 		r.Ticker,
 		r.Price,
@@ -33,6 +54,8 @@ func (r AlertRequestDto) String() string {
 		r.Text,
 		r.Interval,
 		r.PositionSize,
+		r.TradingKey,
+		r.Action,
 	)
 }
 
@@ -0,0 +1,34 @@
+package tradingview
+
+import "testing"
+
+func TestIsReverse(t *testing.T) {
+	tests := []struct {
+		name         string
+		side         string
+		positionSize string
+		action       string
+		expected     bool
+	}{
+		{"partial exit of long", "sell", "1", "", false},
+		{"partial exit of short", "buy", "-1", "", false},
+		{"full exit of long", "sell", "0", "", false},
+		{"full exit of short", "buy", "0", "", false},
+		{"flip long to short", "sell", "-2", "", true},
+		{"flip short to long", "buy", "2", "", true},
+		{"without position size", "sell", "", "", false},
+		{"invalid position size", "buy", "abc", "", false},
+		{"close action", "sell", "-2", "close", false},
+		{"reverse action", "sell", "1", "reverse", true},
+		{"open action", "buy", "2", "open", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			alertRequest := AlertRequestDto{Side: test.side, PositionSize: test.positionSize, Action: test.action}
+			if isReverse := alertRequest.IsReverse(); isReverse != test.expected {
+				t.Errorf("IsReverse() of %s = %v, expected %v", alertRequest, isReverse, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package tradingview
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/constants/futureType"
+)
+
+// StrategyTesterTradeDto closed trade of the TradingView strategy tester "List of trades" export,
+// Profit is in the currency of the export
+type StrategyTesterTradeDto struct {
+	TradeNumber   int                    `json:"trade_number"`
+	FuturesType   futureType.FuturesType `json:"futures_type"`
+	EntrySignal   string                 `json:"entry_signal"`
+	ExitSignal    string                 `json:"exit_signal"`
+	EntryTime     time.Time              `json:"entry_time"`
+	ExitTime      time.Time              `json:"exit_time"`
+	EntryPrice    float64                `json:"entry_price"`
+	ExitPrice     float64                `json:"exit_price"`
+	Contracts     float64                `json:"contracts"`
+	Profit        float64                `json:"profit"`
+	ProfitPercent float64                `json:"profit_percent"`
+}
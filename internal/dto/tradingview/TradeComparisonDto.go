@@ -0,0 +1,62 @@
+package tradingview
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+)
+
+// TradeComparisonDto TradingView strategy tester trades compared with recorded round trips.
+// Slippage is in percent of the TradingView price, positive - our price is worse.
+type TradeComparisonDto struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	TradingViewTradesCount int `json:"trading_view_trades_count"`
+	RoundTripsCount        int `json:"round_trips_count"`
+	MatchedCount           int `json:"matched_count"`
+
+	AverageEntrySlippage float64 `json:"average_entry_slippage"`
+	AverageExitSlippage  float64 `json:"average_exit_slippage"`
+
+	/* Sums of profit percents of all trades, TradingView sizing differs from ours, so percents are compared */
+	TradingViewProfitPercent float64 `json:"trading_view_profit_percent"`
+	ProfitPercent            float64 `json:"profit_percent"`
+	ProfitPercentGap         float64 `json:"profit_percent_gap"`
+
+	TradingViewProfit float64 `json:"trading_view_profit"`
+	Profit            float64 `json:"profit"`
+
+	Matches []TradeMatchDto `json:"matches"`
+
+	/* TradingView trades without round trip */
+	MissedTrades []StrategyTesterTradeDto `json:"missed_trades"`
+
+	/* Round trips without TradingView trade */
+	ExtraTrades []transaction.RoundTripDto `json:"extra_trades"`
+}
+
+type TradeMatchDto struct {
+	TradeNumber        int       `json:"trade_number"`
+	Side               string    `json:"side"`
+	OpenTransactionId  int64     `json:"open_transaction_id"`
+	CloseTransactionId int64     `json:"close_transaction_id"`
+	TradingViewEntryAt time.Time `json:"trading_view_entry_at"`
+	EntryAt            time.Time `json:"entry_at"`
+	TradingViewExitAt  time.Time `json:"trading_view_exit_at"`
+	ExitAt             time.Time `json:"exit_at"`
+
+	/* Our time - TradingView time in seconds */
+	EntryDelay int64 `json:"entry_delay"`
+	ExitDelay  int64 `json:"exit_delay"`
+
+	TradingViewEntryPrice float64 `json:"trading_view_entry_price"`
+	EntryPrice            float64 `json:"entry_price"`
+	EntrySlippage         float64 `json:"entry_slippage"`
+	TradingViewExitPrice  float64 `json:"trading_view_exit_price"`
+	ExitPrice             float64 `json:"exit_price"`
+	ExitSlippage          float64 `json:"exit_slippage"`
+
+	TradingViewProfitPercent float64 `json:"trading_view_profit_percent"`
+	ProfitPercent            float64 `json:"profit_percent"`
+	ProfitPercentGap         float64 `json:"profit_percent_gap"`
+}
@@ -0,0 +1,87 @@
+package indicator
+
+import (
+	"math"
+	"tradingViewWebhookBot/internal/api"
+)
+
+func NewAdx(diPeriod int, adxSmoothing int) *Adx {
+	return &Adx{
+		trueRange:     newRmaSeries(diPeriod),
+		plusMovement:  newRmaSeries(diPeriod),
+		minusMovement: newRmaSeries(diPeriod),
+		adx:           newRmaSeries(adxSmoothing),
+	}
+}
+
+// Adx average directional index with the directional indicators, ta.dmi(diPeriod, adxSmoothing).
+// Movements start from the second kline, the ADX is smoothed from the moment the DI are ready.
+type Adx struct {
+	position      position
+	trueRange     *emaSeries
+	plusMovement  *emaSeries
+	minusMovement *emaSeries
+	adx           *emaSeries
+
+	/* Kline before the last one */
+	prev bar
+	last bar
+}
+
+func (i *Adx) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	if isNew {
+		i.prev = i.last
+	}
+	i.last = newBar(kline)
+	if i.position.count < 2 {
+		return
+	}
+
+	up := i.last.high - i.prev.high
+	down := i.prev.low - i.last.low
+	plusMovement, minusMovement := float64(0), float64(0)
+	if up > down && up > 0 {
+		plusMovement = up
+	}
+	if down > up && down > 0 {
+		minusMovement = down
+	}
+	i.trueRange.add(trueRange(kline, i.prev.close, true), isNew)
+	i.plusMovement.add(plusMovement, isNew)
+	i.minusMovement.add(minusMovement, isNew)
+	if !i.trueRange.isReady() {
+		return
+	}
+
+	sum := i.PlusDi() + i.MinusDi()
+	if sum == 0 {
+		sum = 1
+	}
+	i.adx.add(math.Abs(i.PlusDi()-i.MinusDi())/sum*100, isNew)
+}
+
+func (i *Adx) IsReady() bool {
+	return i.adx.isReady()
+}
+
+func (i *Adx) Adx() float64 {
+	return i.adx.value()
+}
+
+func (i *Adx) PlusDi() float64 {
+	if i.trueRange.value() == 0 {
+		return 0
+	}
+	return i.plusMovement.value() / i.trueRange.value() * 100
+}
+
+func (i *Adx) MinusDi() float64 {
+	if i.trueRange.value() == 0 {
+		return 0
+	}
+	return i.minusMovement.value() / i.trueRange.value() * 100
+}
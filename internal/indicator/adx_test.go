@@ -0,0 +1,22 @@
+package indicator
+
+import "testing"
+
+func TestAdx(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     func(*Adx) float64
+		reference []referenceValue
+	}{
+		{"adx", (*Adx).Adx, []referenceValue{{13, 39.8115792705}, {14, 40.1807521533}, {23, 24.5761097913}, {31, 45.3124301471}, {32, 48.3548569546}}},
+		{"plus di", (*Adx).PlusDi, []referenceValue{{7, 22.8360957643}, {8, 24.5720305504}, {20, 13.5290691415}, {31, 7.705837685}, {32, 6.740817983}}},
+		{"minus di", (*Adx).MinusDi, []referenceValue{{7, 12.5230202578}, {8, 10.7453252568}, {20, 16.2744458706}, {31, 37.7515729812}, {32, 33.634746255}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indicator := NewAdx(7, 7)
+			checkReference(t, indicator, func() float64 { return test.value(indicator) }, 13, test.reference)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package indicator
+
+import "tradingViewWebhookBot/internal/api"
+
+func NewAtr(period int) *Atr {
+	return &Atr{series: newRmaSeries(period)}
+}
+
+// Atr average true range with the Wilder's smoothing, ta.atr(period). True range of the first kline is high - low.
+type Atr struct {
+	position position
+	series   *emaSeries
+
+	/* Close of the kline before the last one */
+	prevClose float64
+	lastClose float64
+}
+
+func (i *Atr) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	if isNew {
+		i.prevClose = i.lastClose
+	}
+	i.lastClose = kline.GetClose()
+	i.series.add(trueRange(kline, i.prevClose, i.position.count > 1), isNew)
+}
+
+func (i *Atr) IsReady() bool {
+	return i.series.isReady()
+}
+
+func (i *Atr) Value() float64 {
+	return i.series.value()
+}
+
+// Percent ATR in percents of the last close
+func (i *Atr) Percent() float64 {
+	if i.lastClose == 0 {
+		return 0
+	}
+	return i.series.value() / i.lastClose * 100
+}
@@ -0,0 +1,21 @@
+package indicator
+
+import "testing"
+
+func TestAtr(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     func(*Atr) float64
+		reference []referenceValue
+	}{
+		{"value", (*Atr).Value, []referenceValue{{13, 0.72}, {14, 0.7007142857}, {23, 0.7466696123}, {31, 0.8605751529}, {32, 0.8576769277}}},
+		{"percent", (*Atr).Percent, []referenceValue{{13, 1.5557476232}, {14, 1.5140758118}, {23, 1.6074695636}, {31, 2.017288216}, {32, 1.9885855037}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indicator := NewAtr(14)
+			checkReference(t, indicator, func() float64 { return test.value(indicator) }, 13, test.reference)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package indicator
+
+import "tradingViewWebhookBot/internal/api"
+
+func NewBollinger(period int, multiplier float64) *Bollinger {
+	return &Bollinger{series: newSmaSeries(period), multiplier: multiplier}
+}
+
+// Bollinger bands of the close, ta.bb(close, period, multiplier): SMA +- multiplier * population standard deviation
+type Bollinger struct {
+	position   position
+	series     *smaSeries
+	multiplier float64
+}
+
+func (i *Bollinger) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	i.series.add(kline.GetClose(), isNew)
+}
+
+func (i *Bollinger) IsReady() bool {
+	return i.series.isReady()
+}
+
+func (i *Bollinger) Middle() float64 {
+	return i.series.value()
+}
+
+func (i *Bollinger) Upper() float64 {
+	return i.series.value() + i.multiplier*i.series.stdev()
+}
+
+func (i *Bollinger) Lower() float64 {
+	return i.series.value() - i.multiplier*i.series.stdev()
+}
+
+// Width distance between the bands in percents of the middle, ta.bbw
+func (i *Bollinger) Width() float64 {
+	if i.series.value() == 0 {
+		return 0
+	}
+	return (i.Upper() - i.Lower()) / i.series.value() * 100
+}
@@ -0,0 +1,23 @@
+package indicator
+
+import "testing"
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     func(*Bollinger) float64
+		reference []referenceValue
+	}{
+		{"middle", (*Bollinger).Middle, []referenceValue{{19, 45.409}, {20, 45.5025}, {26, 45.8755}, {31, 45.365}, {32, 45.241}}},
+		{"upper", (*Bollinger).Upper, []referenceValue{{19, 47.1153282217}, {20, 47.1687397787}, {26, 46.9216639451}, {31, 47.5409641541}, {32, 47.6201502685}}},
+		{"lower", (*Bollinger).Lower, []referenceValue{{19, 43.7026717783}, {20, 43.8362602213}, {26, 44.8293360549}, {31, 43.1890358459}, {32, 42.8618497315}}},
+		{"width", (*Bollinger).Width, []referenceValue{{19, 7.5153745806}, {20, 7.3237284926}, {26, 4.5608830207}, {31, 9.5931407654}, {32, 10.51767321}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indicator := NewBollinger(20, 2)
+			checkReference(t, indicator, func() float64 { return test.value(indicator) }, 19, test.reference)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Package indicator technical indicators calculated the same way as the TradingView built-ins (ta.sma, ta.ema, ta.rsi, ...),
+// so server-side checks agree with the chart the alerts come from.
+//
+// Indicators are streaming: klines are passed one by one in chronological order with Update.
+// A kline with the same start as the last one replaces it, so the unclosed candle can be updated on every tick
+// without recalculation of the whole series. Older klines are ignored.
+package indicator
+
+import (
+	"math"
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/util"
+)
+
+type Indicator interface {
+	Update(kline api.KlineDto)
+	// IsReady enough klines are received, values are not reliable before
+	IsReady() bool
+}
+
+// Feed updates the indicator with klines in chronological order, the exchange returns them in reverse
+func Feed(indicator Indicator, klines []api.KlineDto) {
+	for _, kline := range SortKlines(klines) {
+		indicator.Update(kline)
+	}
+}
+
+// SortKlines copy of klines ordered by start
+func SortKlines(klines []api.KlineDto) []api.KlineDto {
+	sorted := make([]api.KlineDto, len(klines))
+	copy(sorted, klines)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetStartAt().Before(sorted[j].GetStartAt())
+	})
+	return sorted
+}
+
+// FromCandles klines of stored candles
+func FromCandles(candles []domain.Candle) []api.KlineDto {
+	klines := make([]api.KlineDto, len(candles))
+	for i := range candles {
+		klines[i] = &CandleKlineDto{Candle: candles[i]}
+	}
+	return klines
+}
+
+// CandleKlineDto stored candle as a kline, the symbol is unknown as the candle has the coin id only
+type CandleKlineDto struct {
+	Candle domain.Candle
+}
+
+func (d *CandleKlineDto) GetSymbol() string {
+	return ""
+}
+
+func (d *CandleKlineDto) GetInterval() string {
+	return d.Candle.Interval
+}
+
+func (d *CandleKlineDto) GetStartAt() time.Time {
+	return d.Candle.StartAt
+}
+
+func (d *CandleKlineDto) GetCloseAt() time.Time {
+	intervalDuration, _ := util.ParseKlineInterval(d.Candle.Interval)
+	return d.Candle.StartAt.Add(intervalDuration)
+}
+
+func (d *CandleKlineDto) GetOpen() float64 {
+	return d.Candle.Open
+}
+
+func (d *CandleKlineDto) GetHigh() float64 {
+	return d.Candle.High
+}
+
+func (d *CandleKlineDto) GetLow() float64 {
+	return d.Candle.Low
+}
+
+func (d *CandleKlineDto) GetClose() float64 {
+	return d.Candle.Close
+}
+
+func (d *CandleKlineDto) GetVolume() float64 {
+	return d.Candle.Volume
+}
+
+func (d *CandleKlineDto) GetTurnover() float64 {
+	return d.Candle.Turnover
+}
+
+// position start of the last kline of the indicator
+type position struct {
+	lastStartAt time.Time
+	count       int
+}
+
+// advance isNew is false when the kline replaces the last one, ok is false for klines older than the last one
+func (p *position) advance(kline api.KlineDto) (isNew bool, ok bool) {
+	startAt := kline.GetStartAt()
+	if p.count > 0 {
+		if startAt.Equal(p.lastStartAt) {
+			return false, true
+		}
+		if startAt.Before(p.lastStartAt) {
+			return false, false
+		}
+	}
+	p.lastStartAt = startAt
+	p.count++
+	return true, true
+}
+
+// bar prices of the kline, klines are not kept as the same dto may be reused for updates of the unclosed candle
+type bar struct {
+	high  float64
+	low   float64
+	close float64
+}
+
+func newBar(kline api.KlineDto) bar {
+	return bar{high: kline.GetHigh(), low: kline.GetLow(), close: kline.GetClose()}
+}
+
+// trueRange high - low of the first kline, prevClose is used after
+func trueRange(kline api.KlineDto, prevClose float64, hasPrevClose bool) float64 {
+	if !hasPrevClose {
+		return kline.GetHigh() - kline.GetLow()
+	}
+	return math.Max(kline.GetHigh()-kline.GetLow(), math.Max(math.Abs(kline.GetHigh()-prevClose), math.Abs(kline.GetLow()-prevClose)))
+}
@@ -0,0 +1,235 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/domain"
+)
+
+// tolerance reference values are rounded to 10 decimals
+const tolerance = 0.000001
+
+// testKlines 15 minute klines with the closes of the StockCharts RSI example, the UTC day changes at index 8.
+// Reference values of the indicator tests are calculated by the definitions of the TradingView built-ins
+// (ta.sma, ta.ema, ta.rma, ta.rsi, ta.atr, ta.bb, ta.vwap, ta.macd, ta.dmi, ta.supertrend) for the whole series at once.
+var testKlines = [][5]float64{
+	{44.25, 44.49, 44.13, 44.34, 100},
+	{44.34, 44.54, 43.93, 44.09, 117},
+	{44.09, 44.40, 43.89, 44.15, 134},
+	{44.15, 44.45, 43.49, 43.61, 151},
+	{43.61, 44.48, 43.45, 44.33, 168},
+	{44.33, 45.03, 44.13, 44.83, 185},
+	{44.83, 45.35, 44.71, 45.10, 202},
+	{45.10, 45.72, 44.94, 45.42, 100},
+	{45.42, 45.99, 45.22, 45.84, 117},
+	{45.84, 46.28, 45.72, 46.08, 134},
+	{46.08, 46.33, 45.73, 45.89, 151},
+	{45.89, 46.33, 45.69, 46.03, 168},
+	{46.03, 46.18, 45.49, 45.61, 185},
+	{45.61, 46.48, 45.45, 46.28, 202},
+	{46.28, 46.53, 46.08, 46.28, 100},
+	{46.28, 46.58, 45.88, 46.00, 117},
+	{46.00, 46.18, 45.84, 46.03, 134},
+	{46.03, 46.61, 45.83, 46.41, 151},
+	{46.41, 46.66, 46.10, 46.22, 168},
+	{46.22, 46.52, 45.48, 45.64, 185},
+	{45.64, 46.36, 45.44, 46.21, 202},
+	{46.21, 46.45, 46.09, 46.25, 100},
+	{46.25, 46.50, 45.55, 45.71, 117},
+	{45.71, 46.75, 45.51, 46.45, 134},
+	{46.45, 46.60, 45.66, 45.78, 151},
+	{45.78, 45.98, 45.19, 45.35, 168},
+	{45.35, 45.60, 43.83, 44.03, 185},
+	{44.03, 44.48, 43.91, 44.18, 202},
+	{44.18, 44.37, 44.02, 44.22, 100},
+	{44.22, 44.77, 44.02, 44.57, 117},
+	{44.57, 44.82, 43.30, 43.42, 134},
+	{43.42, 43.72, 42.50, 42.66, 151},
+	{42.66, 43.28, 42.46, 43.13, 168},
+}
+
+var testStartAt = time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)
+
+type referenceValue struct {
+	index int
+	value float64
+}
+
+func newTestKline(index int) *CandleKlineDto {
+	prices := testKlines[index]
+	return &CandleKlineDto{Candle: domain.Candle{
+		Interval: "15",
+		StartAt:  testStartAt.Add(time.Duration(index) * 15 * time.Minute),
+		Open:     prices[0],
+		High:     prices[1],
+		Low:      prices[2],
+		Close:    prices[3],
+		Volume:   prices[4],
+	}}
+}
+
+func allTestKlines() []api.KlineDto {
+	klines := make([]api.KlineDto, len(testKlines))
+	for i := range testKlines {
+		klines[i] = newTestKline(i)
+	}
+	return klines
+}
+
+// checkReference updates the indicator with the test klines one by one and compares the value with the reference,
+// the indicator must become ready at firstReady
+func checkReference(t *testing.T, indicator Indicator, value func() float64, firstReady int, reference []referenceValue) {
+	t.Helper()
+	expected := make(map[int]float64)
+	for _, item := range reference {
+		expected[item.index] = item.value
+	}
+
+	for i := range testKlines {
+		indicator.Update(newTestKline(i))
+		if indicator.IsReady() != (i >= firstReady) {
+			t.Fatalf("IsReady at %d = %v, ready from %d expected", i, indicator.IsReady(), firstReady)
+		}
+		if expectedValue, found := expected[i]; found && math.Abs(value()-expectedValue) > tolerance {
+			t.Errorf("value at %d = %.10f, expected %.10f", i, value(), expectedValue)
+		}
+	}
+}
+
+// testIndicator constructor of the indicator returning the functions of all its values
+type testIndicator struct {
+	name string
+	new  func() (Indicator, []func() float64)
+}
+
+var testIndicators = []testIndicator{
+	{name: "sma", new: func() (Indicator, []func() float64) {
+		i := NewSma(5)
+		return i, []func() float64{i.Value}
+	}},
+	{name: "ema", new: func() (Indicator, []func() float64) {
+		i := NewEma(10)
+		return i, []func() float64{i.Value}
+	}},
+	{name: "rsi", new: func() (Indicator, []func() float64) {
+		i := NewRsi(14)
+		return i, []func() float64{i.Value}
+	}},
+	{name: "atr", new: func() (Indicator, []func() float64) {
+		i := NewAtr(14)
+		return i, []func() float64{i.Value, i.Percent}
+	}},
+	{name: "bollinger", new: func() (Indicator, []func() float64) {
+		i := NewBollinger(20, 2)
+		return i, []func() float64{i.Middle, i.Upper, i.Lower, i.Width}
+	}},
+	{name: "vwap", new: func() (Indicator, []func() float64) {
+		i := NewVwap()
+		return i, []func() float64{i.Value}
+	}},
+	{name: "macd", new: func() (Indicator, []func() float64) {
+		i := NewMacd(5, 10, 4)
+		return i, []func() float64{i.Macd, i.Signal, i.Histogram}
+	}},
+	{name: "adx", new: func() (Indicator, []func() float64) {
+		i := NewAdx(7, 7)
+		return i, []func() float64{i.Adx, i.PlusDi, i.MinusDi}
+	}},
+	{name: "supertrend", new: func() (Indicator, []func() float64) {
+		i := NewSupertrend(2, 5)
+		return i, []func() float64{i.Value, func() float64 {
+			if i.IsUpTrend() {
+				return 1
+			}
+			return 0
+		}}
+	}},
+}
+
+// snapshot values and readiness of the indicator
+func snapshot(indicator Indicator, values []func() float64) []float64 {
+	result := []float64{0}
+	if indicator.IsReady() {
+		result[0] = 1
+	}
+	for _, value := range values {
+		result = append(result, value())
+	}
+	return result
+}
+
+func assertSnapshot(t *testing.T, index int, actual []float64, expected []float64) {
+	t.Helper()
+	for j := range expected {
+		if math.Abs(actual[j]-expected[j]) > 1e-9 {
+			t.Fatalf("value %d at %d = %.10f, expected %.10f", j, index, actual[j], expected[j])
+		}
+	}
+}
+
+// TestUpdateReplacesLastKline updates of the unclosed kline must give the same values as the closed klines only.
+// The same dto is updated like the kline of the exchange stream, so indicators must not keep it.
+func TestUpdateReplacesLastKline(t *testing.T) {
+	for _, test := range testIndicators {
+		t.Run(test.name, func(t *testing.T) {
+			batch, batchValues := test.new()
+			streaming, streamingValues := test.new()
+
+			for i := range testKlines {
+				batch.Update(newTestKline(i))
+				expected := snapshot(batch, batchValues)
+
+				kline := newTestKline(i)
+				final := kline.Candle
+				for _, shift := range []float64{0.37, -0.52, 0.11} {
+					kline.Candle.Close = final.Close + shift
+					kline.Candle.High = math.Max(final.High, kline.Candle.Close)
+					kline.Candle.Low = math.Min(final.Low, kline.Candle.Close)
+					kline.Candle.Volume = final.Volume / 3
+					streaming.Update(kline)
+				}
+				kline.Candle = final
+				streaming.Update(kline)
+
+				assertSnapshot(t, i, snapshot(streaming, streamingValues), expected)
+			}
+		})
+	}
+}
+
+func TestUpdateIgnoresOlderKlines(t *testing.T) {
+	for _, test := range testIndicators {
+		t.Run(test.name, func(t *testing.T) {
+			indicator, values := test.new()
+			for i := range testKlines {
+				indicator.Update(newTestKline(i))
+			}
+			expected := snapshot(indicator, values)
+
+			indicator.Update(newTestKline(0))
+			indicator.Update(newTestKline(len(testKlines) - 2))
+
+			assertSnapshot(t, len(testKlines)-1, snapshot(indicator, values), expected)
+		})
+	}
+}
+
+func TestFeedSortsKlines(t *testing.T) {
+	for _, test := range testIndicators {
+		t.Run(test.name, func(t *testing.T) {
+			ordered, orderedValues := test.new()
+			Feed(ordered, allTestKlines())
+
+			reversed := allTestKlines()
+			for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+				reversed[i], reversed[j] = reversed[j], reversed[i]
+			}
+			fed, fedValues := test.new()
+			Feed(fed, reversed)
+
+			assertSnapshot(t, len(testKlines)-1, snapshot(fed, fedValues), snapshot(ordered, orderedValues))
+		})
+	}
+}
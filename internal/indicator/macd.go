@@ -0,0 +1,44 @@
+package indicator
+
+import "tradingViewWebhookBot/internal/api"
+
+func NewMacd(fastPeriod int, slowPeriod int, signalPeriod int) *Macd {
+	return &Macd{fast: newEmaSeries(fastPeriod), slow: newEmaSeries(slowPeriod), signal: newEmaSeries(signalPeriod)}
+}
+
+// Macd ta.macd(close, fast, slow, signal): difference of the fast and slow EMA of the close and its EMA as the signal line.
+// The signal line starts when the slow EMA is ready.
+type Macd struct {
+	position position
+	fast     *emaSeries
+	slow     *emaSeries
+	signal   *emaSeries
+}
+
+func (i *Macd) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	i.fast.add(kline.GetClose(), isNew)
+	i.slow.add(kline.GetClose(), isNew)
+	if i.slow.isReady() {
+		i.signal.add(i.Macd(), isNew)
+	}
+}
+
+func (i *Macd) IsReady() bool {
+	return i.signal.isReady()
+}
+
+func (i *Macd) Macd() float64 {
+	return i.fast.value() - i.slow.value()
+}
+
+func (i *Macd) Signal() float64 {
+	return i.signal.value()
+}
+
+func (i *Macd) Histogram() float64 {
+	return i.Macd() - i.signal.value()
+}
@@ -0,0 +1,22 @@
+package indicator
+
+import "testing"
+
+func TestMacd(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     func(*Macd) float64
+		reference []referenceValue
+	}{
+		{"macd", (*Macd).Macd, []referenceValue{{12, 0.4577216648}, {21, 0.1383318783}, {31, -0.6375255342}, {32, -0.6082205441}}},
+		{"signal", (*Macd).Signal, []referenceValue{{12, 0.5993326172}, {13, 0.5439972735}, {22, 0.1235031244}, {31, -0.4962050411}, {32, -0.5410112423}}},
+		{"histogram", (*Macd).Histogram, []referenceValue{{12, -0.1416109524}, {13, -0.0830030156}, {22, -0.0736931108}, {31, -0.1413204931}, {32, -0.0672093018}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indicator := NewMacd(5, 10, 4)
+			checkReference(t, indicator, func() float64 { return test.value(indicator) }, 12, test.reference)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package indicator
+
+import "tradingViewWebhookBot/internal/api"
+
+func NewSma(period int) *Sma {
+	return &Sma{series: newSmaSeries(period)}
+}
+
+// Sma simple moving average of the close, ta.sma(close, period)
+type Sma struct {
+	position position
+	series   *smaSeries
+}
+
+func (i *Sma) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	i.series.add(kline.GetClose(), isNew)
+}
+
+func (i *Sma) IsReady() bool {
+	return i.series.isReady()
+}
+
+func (i *Sma) Value() float64 {
+	return i.series.value()
+}
+
+func NewEma(period int) *Ema {
+	return &Ema{series: newEmaSeries(period)}
+}
+
+// Ema exponential moving average of the close, ta.ema(close, period)
+type Ema struct {
+	position position
+	series   *emaSeries
+}
+
+func (i *Ema) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	i.series.add(kline.GetClose(), isNew)
+}
+
+func (i *Ema) IsReady() bool {
+	return i.series.isReady()
+}
+
+func (i *Ema) Value() float64 {
+	return i.series.value()
+}
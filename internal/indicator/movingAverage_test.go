@@ -0,0 +1,38 @@
+package indicator
+
+import "testing"
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name       string
+		new        func() (Indicator, func() float64)
+		firstReady int
+		reference  []referenceValue
+	}{
+		{
+			name: "sma 5",
+			new: func() (Indicator, func() float64) {
+				i := NewSma(5)
+				return i, i.Value
+			},
+			firstReady: 4,
+			reference:  []referenceValue{{4, 44.104}, {5, 44.202}, {18, 46.188}, {31, 43.81}, {32, 43.6}},
+		},
+		{
+			name: "ema 10",
+			new: func() (Indicator, func() float64) {
+				i := NewEma(10)
+				return i, i.Value
+			},
+			firstReady: 9,
+			reference:  []referenceValue{{9, 44.779}, {10, 44.981}, {21, 45.9899141748}, {31, 44.3391432408}, {32, 44.1192990152}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indicator, value := test.new()
+			checkReference(t, indicator, value, test.firstReady, test.reference)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package indicator
+
+import (
+	"math"
+	"tradingViewWebhookBot/internal/api"
+)
+
+func NewRsi(period int) *Rsi {
+	return &Rsi{gains: newRmaSeries(period), losses: newRmaSeries(period)}
+}
+
+// Rsi relative strength index of the close with the Wilder's smoothing, ta.rsi(close, period).
+// Changes start from the second kline, so it is ready after period + 1 klines.
+type Rsi struct {
+	position position
+	gains    *emaSeries
+	losses   *emaSeries
+
+	/* Close of the kline before the last one */
+	prevClose float64
+	lastClose float64
+}
+
+func (i *Rsi) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	if isNew {
+		i.prevClose = i.lastClose
+	}
+	i.lastClose = kline.GetClose()
+	if i.position.count < 2 {
+		return
+	}
+
+	change := i.lastClose - i.prevClose
+	i.gains.add(math.Max(change, 0), isNew)
+	i.losses.add(math.Max(-change, 0), isNew)
+}
+
+func (i *Rsi) IsReady() bool {
+	return i.gains.isReady()
+}
+
+func (i *Rsi) Value() float64 {
+	gain, loss := i.gains.value(), i.losses.value()
+	if loss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
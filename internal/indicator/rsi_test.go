@@ -0,0 +1,23 @@
+package indicator
+
+import "testing"
+
+func TestRsi(t *testing.T) {
+	indicator := NewRsi(14)
+	// 70.46 is the first value of the StockCharts example
+	checkReference(t, indicator, indicator.Value, 14, []referenceValue{
+		{14, 70.4641350211}, {15, 66.2496185536}, {23, 62.3399293109}, {31, 33.0904825727}, {32, 37.7887719821},
+	})
+}
+
+func TestRsiWithoutLosses(t *testing.T) {
+	indicator := NewRsi(3)
+	for i := 0; i < 5; i++ {
+		kline := newTestKline(i)
+		kline.Candle.Close = float64(100 + i)
+		indicator.Update(kline)
+	}
+	if !indicator.IsReady() || indicator.Value() != 100 {
+		t.Errorf("Value = %v, ready %v, 100 expected", indicator.Value(), indicator.IsReady())
+	}
+}
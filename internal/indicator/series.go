@@ -0,0 +1,95 @@
+package indicator
+
+import (
+	"tradingViewWebhookBot/internal/util"
+)
+
+// smaSeries simple moving average of the last period values
+type smaSeries struct {
+	period int
+	window []float64
+	sum    float64
+}
+
+func newSmaSeries(period int) *smaSeries {
+	return &smaSeries{period: period, window: make([]float64, 0, period+1)}
+}
+
+// add isNew is false when the value replaces the last one
+func (s *smaSeries) add(value float64, isNew bool) {
+	if !isNew && len(s.window) > 0 {
+		last := len(s.window) - 1
+		s.sum += value - s.window[last]
+		s.window[last] = value
+		return
+	}
+
+	s.window = append(s.window, value)
+	s.sum += value
+	if len(s.window) > s.period {
+		s.sum -= s.window[0]
+		s.window = append(s.window[:0], s.window[1:]...)
+	}
+}
+
+func (s *smaSeries) isReady() bool {
+	return len(s.window) == s.period
+}
+
+// value average of the received values until the series is ready
+func (s *smaSeries) value() float64 {
+	if len(s.window) == 0 {
+		return 0
+	}
+	return s.sum / float64(len(s.window))
+}
+
+// stdev population standard deviation like ta.stdev
+func (s *smaSeries) stdev() float64 {
+	if len(s.window) == 0 {
+		return 0
+	}
+	return util.StandardDeviation(s.window)
+}
+
+// emaSeries exponential moving average seeded with the simple average of the first period values like ta.ema.
+// Alpha is 2 / (period + 1) for the EMA and 1 / period for the Wilder's moving average (ta.rma).
+type emaSeries struct {
+	period int
+	alpha  float64
+	seed   *smaSeries
+	count  int
+
+	/* Value before the last one, the last value is recalculated from it when replaced */
+	prev float64
+	last float64
+}
+
+func newEmaSeries(period int) *emaSeries {
+	return &emaSeries{period: period, alpha: 2 / float64(period+1), seed: newSmaSeries(period)}
+}
+
+func newRmaSeries(period int) *emaSeries {
+	return &emaSeries{period: period, alpha: 1 / float64(period), seed: newSmaSeries(period)}
+}
+
+func (s *emaSeries) add(value float64, isNew bool) {
+	if isNew || s.count == 0 {
+		s.prev = s.last
+		s.count++
+	}
+	if s.count <= s.period {
+		s.seed.add(value, isNew)
+		s.last = s.seed.value()
+		return
+	}
+	s.last = s.alpha*value + (1-s.alpha)*s.prev
+}
+
+func (s *emaSeries) isReady() bool {
+	return s.count >= s.period
+}
+
+func (s *emaSeries) value() float64 {
+	return s.last
+}
@@ -0,0 +1,86 @@
+package indicator
+
+import "tradingViewWebhookBot/internal/api"
+
+func NewSupertrend(factor float64, atrPeriod int) *Supertrend {
+	return &Supertrend{factor: factor, atr: newRmaSeries(atrPeriod)}
+}
+
+// Supertrend ta.supertrend(factor, atrPeriod): bands of hl2 +- factor * ATR which only move in the direction of the trend,
+// the lower band is the value in the uptrend and the upper band in the downtrend. The first value is a downtrend.
+type Supertrend struct {
+	position position
+	factor   float64
+	atr      *emaSeries
+
+	/* State of the kline before the last one */
+	prev      supertrendState
+	last      supertrendState
+	prevClose float64
+	lastClose float64
+}
+
+type supertrendState struct {
+	isReady   bool
+	upperBand float64
+	lowerBand float64
+	value     float64
+	isUpTrend bool
+}
+
+func (i *Supertrend) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+	if isNew {
+		i.prev = i.last
+		i.prevClose = i.lastClose
+	}
+	i.lastClose = kline.GetClose()
+	i.atr.add(trueRange(kline, i.prevClose, i.position.count > 1), isNew)
+	if !i.atr.isReady() {
+		i.last = supertrendState{}
+		return
+	}
+
+	source := (kline.GetHigh() + kline.GetLow()) / 2
+	state := supertrendState{
+		isReady:   true,
+		upperBand: source + i.factor*i.atr.value(),
+		lowerBand: source - i.factor*i.atr.value(),
+	}
+	if state.lowerBand <= i.prev.lowerBand && i.prevClose >= i.prev.lowerBand {
+		state.lowerBand = i.prev.lowerBand
+	}
+	if state.upperBand >= i.prev.upperBand && i.prevClose <= i.prev.upperBand {
+		state.upperBand = i.prev.upperBand
+	}
+
+	switch {
+	case !i.prev.isReady:
+		state.isUpTrend = false
+	case i.prev.value == i.prev.upperBand:
+		state.isUpTrend = i.lastClose > state.upperBand
+	default:
+		state.isUpTrend = i.lastClose >= state.lowerBand
+	}
+	if state.isUpTrend {
+		state.value = state.lowerBand
+	} else {
+		state.value = state.upperBand
+	}
+	i.last = state
+}
+
+func (i *Supertrend) IsReady() bool {
+	return i.last.isReady
+}
+
+func (i *Supertrend) Value() float64 {
+	return i.last.value
+}
+
+func (i *Supertrend) IsUpTrend() bool {
+	return i.last.isUpTrend
+}
@@ -0,0 +1,37 @@
+package indicator
+
+import "testing"
+
+func TestSupertrend(t *testing.T) {
+	// the trend changes to up at index 7 and back to down at index 26
+	tests := []struct {
+		index     int
+		value     float64
+		isUpTrend bool
+	}{
+		{4, 45.353, false},
+		{6, 45.353, false},
+		{7, 43.872144, true},
+		{18, 45.1125987903, true},
+		{25, 45.1125987903, true},
+		{26, 46.7695710737, false},
+		{32, 44.8017685196, false},
+	}
+
+	indicator := NewSupertrend(2, 5)
+	reference := make([]referenceValue, len(tests))
+	for i, test := range tests {
+		reference[i] = referenceValue{test.index, test.value}
+	}
+	checkReference(t, indicator, indicator.Value, 4, reference)
+
+	indicator = NewSupertrend(2, 5)
+	for i := range testKlines {
+		indicator.Update(newTestKline(i))
+		for _, test := range tests {
+			if test.index == i && indicator.IsUpTrend() != test.isUpTrend {
+				t.Errorf("IsUpTrend at %d = %v, expected %v", i, indicator.IsUpTrend(), test.isUpTrend)
+			}
+		}
+	}
+}
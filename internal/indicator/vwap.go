@@ -0,0 +1,57 @@
+package indicator
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/api"
+)
+
+func NewVwap() *Vwap {
+	return &Vwap{}
+}
+
+// Vwap volume weighted average of hlc3 anchored to the UTC day like ta.vwap on crypto charts,
+// the sums are reset by the first kline of the day
+type Vwap struct {
+	position position
+
+	sessionStart       time.Time
+	sessionPriceVolume float64
+	sessionVolume      float64
+	lastTypicalPrice   float64
+
+	/* Sums of the session before the last kline */
+	prevPriceVolume float64
+	prevVolume      float64
+}
+
+func (i *Vwap) Update(kline api.KlineDto) {
+	isNew, ok := i.position.advance(kline)
+	if !ok {
+		return
+	}
+
+	if isNew {
+		sessionStart := kline.GetStartAt().UTC().Truncate(24 * time.Hour)
+		if !sessionStart.Equal(i.sessionStart) {
+			i.sessionStart = sessionStart
+			i.sessionPriceVolume, i.sessionVolume = 0, 0
+		}
+		i.prevPriceVolume, i.prevVolume = i.sessionPriceVolume, i.sessionVolume
+	}
+
+	i.lastTypicalPrice = (kline.GetHigh() + kline.GetLow() + kline.GetClose()) / 3
+	i.sessionPriceVolume = i.prevPriceVolume + i.lastTypicalPrice*kline.GetVolume()
+	i.sessionVolume = i.prevVolume + kline.GetVolume()
+}
+
+func (i *Vwap) IsReady() bool {
+	return i.position.count > 0
+}
+
+// Value hlc3 of the last kline while the session has no volume
+func (i *Vwap) Value() float64 {
+	if i.sessionVolume == 0 {
+		return i.lastTypicalPrice
+	}
+	return i.sessionPriceVolume / i.sessionVolume
+}
@@ -0,0 +1,11 @@
+package indicator
+
+import "testing"
+
+func TestVwap(t *testing.T) {
+	indicator := NewVwap()
+	// the session is reset at index 8
+	checkReference(t, indicator, indicator.Value, 0, []referenceValue{
+		{0, 44.32}, {1, 44.2481105991}, {8, 45.6833333333}, {16, 45.9895922528}, {31, 45.5176443698}, {32, 45.4026365499},
+	})
+}
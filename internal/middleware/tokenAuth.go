@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// TokenAuth accepts "Authorization: Bearer <token>" or "X-Api-Token: <token>".
+// Requests are refused when the token is not configured, management API must never be open by mistake.
+func TokenAuth(token string) func(http.Handler) http.Handler {
+	if token == "" {
+		zap.S().Warn("API_AUTH_TOKEN is not set, authenticated endpoints are disabled")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				http.Error(w, "authentication is not configured", http.StatusServiceUnavailable)
+				return
+			}
+
+			requestToken := r.Header.Get("X-Api-Token")
+			if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+				requestToken = strings.TrimPrefix(authorization, "Bearer ")
+			}
+
+			if subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
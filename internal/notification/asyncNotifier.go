@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// asyncQueueSize events of the channel waiting for delivery, newer events are dropped when it's full
+const asyncQueueSize = 100
+
+var errAsyncQueueIsFull = errors.New("notification queue is full")
+var errAsyncQueueIsStopped = errors.New("notification queue is stopped")
+
+// asyncNotifier delivers events of the channel in background one by one,
+// so a slow channel doesn't delay orders and the other channels. Delivery errors are logged only.
+type asyncNotifier struct {
+	name     string
+	notifier Notifier
+
+	events chan Event
+	done   chan struct{}
+	// mutex guards events from sending after they are closed by stop
+	mutex   sync.RWMutex
+	stopped bool
+}
+
+func newAsyncNotifier(name string, notifier Notifier, size int) *asyncNotifier {
+	n := &asyncNotifier{
+		name:     name,
+		notifier: notifier,
+		events:   make(chan Event, size),
+		done:     make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// Notify never blocks the caller
+func (n *asyncNotifier) Notify(event Event) error {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	if n.stopped {
+		return errAsyncQueueIsStopped
+	}
+
+	select {
+	case n.events <- event:
+		return nil
+	default:
+		return errAsyncQueueIsFull
+	}
+}
+
+// stop delivers queued events, waiting not longer than timeout
+func (n *asyncNotifier) stop(timeout time.Duration) {
+	n.mutex.Lock()
+	if !n.stopped {
+		n.stopped = true
+		close(n.events)
+	}
+	n.mutex.Unlock()
+
+	select {
+	case <-n.done:
+	case <-time.After(timeout):
+		zap.S().Warnf("%d notifications to %s are not delivered", len(n.events), n.name)
+	}
+}
+
+func (n *asyncNotifier) run() {
+	defer close(n.done)
+	for event := range n.events {
+		if err := n.notifier.Notify(event); err != nil {
+			zap.S().Errorf("Error during notification to %s of %s: %s", n.name, event.Type, err.Error())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"os"
+	"strings"
+	"tradingViewWebhookBot/internal/constants/eventType"
+	"tradingViewWebhookBot/internal/constants/severity"
+	"tradingViewWebhookBot/internal/telegram"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewRouterFromConfig creates routes of the channels configured in .env, filters are read from the
+// notification.<channel> section of config.yml: minSeverity and events (empty - all events).
+// Telegram has its own message queue, the other channels are delivered by asynchronous notifiers.
+func NewRouterFromConfig(telegramClient *telegram.TelegramClient) *Router {
+	routes := []Route{newRoute("telegram", NewTelegramNotifier(telegramClient, newMessageTemplatesFromConfig()))}
+
+	if webhookUrl := os.Getenv("DISCORD_WEBHOOK_URL"); webhookUrl != "" {
+		routes = append(routes, newAsyncRoute("discord", NewDiscordNotifier(webhookUrl)))
+	}
+	if webhookUrl := os.Getenv("SLACK_WEBHOOK_URL"); webhookUrl != "" {
+		routes = append(routes, newAsyncRoute("slack", NewSlackNotifier(webhookUrl)))
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		routes = append(routes, newAsyncRoute("email", NewEmailNotifier(SmtpConfig{
+			Host:     host,
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+			To:       splitList(os.Getenv("SMTP_TO")),
+		})))
+	}
+	if url := os.Getenv("NOTIFICATION_WEBHOOK_URL"); url != "" {
+		routes = append(routes, newAsyncRoute("webhook", NewWebhookNotifier(url, os.Getenv("NOTIFICATION_WEBHOOK_TOKEN"))))
+	}
+
+	router := NewRouter(routes...)
+	zap.S().Infof("Notification channels: %s", strings.Join(router.GetRouteNames(), ", "))
+	return router
+}
+
+// newMessageTemplatesFromConfig custom templates of notification.telegram.templatesDir, the default ones if they are not valid.
+// Alert links lead to the signed read-only alert route of the bot at PUBLIC_URL unless notification.alertUrl is set.
+func newMessageTemplatesFromConfig() *MessageTemplates {
+	alertUrl := viper.GetString("notification.alertUrl")
+	if publicUrl := strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"); alertUrl == "" && publicUrl != "" {
+		alertUrl = publicUrl + AlertLinkPath
+	}
+	linkSecret := os.Getenv("API_AUTH_TOKEN")
+
+	templates, err := NewMessageTemplates(viper.GetString("notification.telegram.templatesDir"), alertUrl, linkSecret)
+	if err != nil {
+		zap.S().Errorf("Default notification templates are used: %s", err.Error())
+		if templates, err = NewMessageTemplates("", alertUrl, linkSecret); err != nil {
+			return NewDefaultMessageTemplates()
+		}
+	}
+	return templates
+}
+
+// AlertLinkPath read-only alert route of the bot, it is opened without the API token and checks the signature instead
+const AlertLinkPath = "/alerts/{id}/view?signature={signature}"
+
+func newRoute(name string, notifier Notifier) Route {
+	route := Route{
+		Name:        name,
+		Notifier:    notifier,
+		MinSeverity: severity.Parse(viper.GetString("notification." + name + ".minSeverity")),
+	}
+	for _, value := range viper.GetStringSlice("notification." + name + ".events") {
+		if !eventType.IsValid(eventType.EventType(value)) {
+			zap.S().Warnf("Unknown notification event type %s of %s is ignored", value, name)
+			continue
+		}
+		route.EventTypes = append(route.EventTypes, eventType.EventType(value))
+	}
+	return route
+}
+
+func newAsyncRoute(name string, notifier Notifier) Route {
+	return newRoute(name, newAsyncNotifier(name, notifier, asyncQueueSize))
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,21 @@
+package notification
+
+// discordMessageLimit max length of the content of Discord message
+const discordMessageLimit = 2000
+
+// DiscordNotifier posts events to the Discord channel webhook
+type DiscordNotifier struct {
+	webhookUrl string
+}
+
+func NewDiscordNotifier(webhookUrl string) *DiscordNotifier {
+	return &DiscordNotifier{webhookUrl: webhookUrl}
+}
+
+func (n *DiscordNotifier) Notify(event Event) error {
+	content := event.Message
+	if event.Title != "" {
+		content = "**" + event.Title + "**\n" + content
+	}
+	return postJson(n.webhookUrl, map[string]string{"content": truncate(content, discordMessageLimit)}, nil)
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// smtpTimeout max time of connecting and sending one email
+const smtpTimeout = 30 * time.Second
+
+type SmtpConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailNotifier sends events as plain text emails, PLAIN auth is used if the username is set
+type EmailNotifier struct {
+	config SmtpConfig
+}
+
+func NewEmailNotifier(config SmtpConfig) *EmailNotifier {
+	return &EmailNotifier{config: config}
+}
+
+func (n *EmailNotifier) Notify(event Event) error {
+	if len(n.config.To) == 0 {
+		return fmt.Errorf("smtp recipients are not set")
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	return n.sendMail(auth, n.buildMessage(event))
+}
+
+// sendMail smtp.SendMail bounded by smtpTimeout, it has no timeout itself
+func (n *EmailNotifier) sendMail(auth smtp.Auth, message []byte) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(n.config.Host, n.config.Port), smtpTimeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, to := range n.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (n *EmailNotifier) buildMessage(event Event) []byte {
+	subject := fmt.Sprintf("[%s] %s", strings.ToUpper(string(event.Severity)), event.Title)
+	if event.Title == "" {
+		subject = fmt.Sprintf("[%s] %s", strings.ToUpper(string(event.Severity)), event.Type)
+	}
+
+	var message strings.Builder
+	message.WriteString("From: " + n.config.From + "\r\n")
+	message.WriteString("To: " + strings.Join(n.config.To, ", ") + "\r\n")
+	message.WriteString("Subject: " + stripNewLines(subject) + "\r\n")
+	message.WriteString("Date: " + event.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700") + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(event.Message, "\n", "\r\n"))
+	return []byte(message.String())
+}
+
+// stripNewLines header values must not contain line breaks
+func stripNewLines(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
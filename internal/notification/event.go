@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+	"tradingViewWebhookBot/internal/constants/eventType"
+	"tradingViewWebhookBot/internal/constants/severity"
+)
+
+// Event typed notification, channels render Title and Message in their own format
+type Event struct {
+	Type     eventType.EventType
+	Severity severity.Severity
+	Title    string
+	Message  string
+	Time     time.Time
+	// Trade details of order events, nil for other events
+	Trade *TradeDetails
+}
+
+func NewEvent(eventType eventType.EventType, severity severity.Severity, title string, message string) Event {
+	return Event{
+		Type:     eventType,
+		Severity: severity,
+		Title:    title,
+		Message:  message,
+		Time:     time.Now(),
+	}
+}
+
+// Text plain text representation of the event: title on the first line and message below
+func (e Event) Text() string {
+	if e.Title == "" {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Title
+	}
+	return e.Title + "\n" + e.Message
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("[%s/%s] %s", e.Type, e.Severity, e.Text())
+}
+
+func AlertReceived(body string) Event {
+	return NewEvent(eventType.ALERT_RECEIVED, severity.INFO, "Alert triggered", body)
+}
+
+func Error(title string, err error) Event {
+	return NewEvent(eventType.ERROR, severity.ERROR, title, err.Error())
+}
+
+// Warning error which doesn't need intervention, e.g. not valid alert
+func Warning(title string, message string) Event {
+	return NewEvent(eventType.ERROR, severity.WARNING, title, message)
+}
+
+// Critical error which needs manual intervention, e.g. position of the exchange differs from the transactions
+func Critical(title string, message string) Event {
+	return NewEvent(eventType.ERROR, severity.CRITICAL, title, message)
+}
+
+func RiskRejection(title string, message string) Event {
+	return NewEvent(eventType.RISK_REJECTION, severity.WARNING, title, message)
+}
+
+func DailySummary(message string) Event {
+	return NewEvent(eventType.DAILY_SUMMARY, severity.INFO, "Daily summary", message)
+}
+
+func WeeklySummary(message string) Event {
+	return NewEvent(eventType.WEEKLY_SUMMARY, severity.INFO, "Weekly summary", message)
+}
+
+func Message(title string, message string) Event {
+	return NewEvent(eventType.MESSAGE, severity.INFO, title, message)
+}
@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/constants/severity"
+	"tradingViewWebhookBot/internal/util"
+
+	"go.uber.org/zap"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// defaultTemplateName is used for event types without own template
+const defaultTemplateName = "default"
+
+// MessageTemplates renders events to Telegram HTML. Every event type has a template of the same name,
+// e.g. {{define "order_closed"}}, templates of the directory override the default ones.
+// Values are HTML escaped by html/template.
+type MessageTemplates struct {
+	templates *template.Template
+	// alertUrl link to the alert, {id} is replaced by the alert id and {signature} by its signature. Empty - without link.
+	alertUrl string
+	// linkSecret key of the signature, links with {signature} are not rendered without it
+	linkSecret string
+}
+
+type templateData struct {
+	Event
+	AlertUrl string
+}
+
+func NewMessageTemplates(dir string, alertUrl string, linkSecret string) (*MessageTemplates, error) {
+	templates, err := template.New("").Funcs(templateFuncs).ParseFS(defaultTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	if dir != "" {
+		if templates, err = templates.ParseGlob(filepath.Join(dir, "*.tmpl")); err != nil {
+			return nil, fmt.Errorf("error during parse of templates of %s: %w", dir, err)
+		}
+	}
+
+	return &MessageTemplates{templates: templates, alertUrl: alertUrl, linkSecret: linkSecret}, nil
+}
+
+// NewDefaultMessageTemplates templates embedded to the binary, parsing of them can't fail
+func NewDefaultMessageTemplates() *MessageTemplates {
+	templates, err := NewMessageTemplates("", "", "")
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+func (m *MessageTemplates) Render(event Event) (string, error) {
+	name := string(event.Type)
+	if m.templates.Lookup(name) == nil {
+		name = defaultTemplateName
+	}
+
+	data := templateData{Event: event}
+	if event.Trade != nil && event.Trade.AlertId != 0 {
+		data.AlertUrl = m.getAlertUrl(event.Trade.AlertId)
+	}
+
+	var message bytes.Buffer
+	if err := m.templates.ExecuteTemplate(&message, name, data); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
+
+// getAlertUrl empty when the link is not configured or needs a signature without the secret
+func (m *MessageTemplates) getAlertUrl(alertId int64) string {
+	if m.alertUrl == "" || (strings.Contains(m.alertUrl, "{signature}") && m.linkSecret == "") {
+		return ""
+	}
+	alertUrl := strings.ReplaceAll(m.alertUrl, "{id}", strconv.FormatInt(alertId, 10))
+	return strings.ReplaceAll(alertUrl, "{signature}", util.SignId(m.linkSecret, alertId))
+}
+
+var templateFuncs = template.FuncMap{
+	"sideEmoji": func(side string) string {
+		if side == "SHORT" {
+			return "🔴"
+		}
+		return "🟢"
+	},
+	"profitEmoji": func(profit float64) string {
+		if profit < 0 {
+			return "❌"
+		}
+		return "✅"
+	},
+	"severityEmoji": func(value severity.Severity) string {
+		switch value {
+		case severity.CRITICAL:
+			return "🚨"
+		case severity.ERROR:
+			return "❗"
+		case severity.WARNING:
+			return "⚠️"
+		default:
+			return "ℹ️"
+		}
+	},
+	"number": func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	},
+	"usd": func(value float64) string {
+		if value < 0 {
+			return fmt.Sprintf("-$%.2f", math.Abs(value))
+		}
+		return fmt.Sprintf("$%.2f", value)
+	},
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%+.2f%%", value)
+	},
+	"duration": formatDuration,
+}
+
+// formatDuration e.g. 2d 3h 15m, seconds are shown for durations below a minute
+func formatDuration(duration time.Duration) string {
+	if duration < time.Minute {
+		return fmt.Sprintf("%ds", int(duration.Seconds()))
+	}
+
+	days := int(duration.Hours()) / 24
+	hours := int(duration.Hours()) % 24
+	minutes := int(duration.Minutes()) % 60
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
+func logTemplateError(event Event, err error) {
+	zap.S().Errorf("Error during render of %s notification template: %s", event.Type, err.Error())
+}
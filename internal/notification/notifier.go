@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"tradingViewWebhookBot/internal/constants/eventType"
+	"tradingViewWebhookBot/internal/constants/severity"
+
+	"go.uber.org/zap"
+)
+
+// Notifier delivers the event to a single channel or fans it out to several channels
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// Route channel of the Router and events it is subscribed to
+type Route struct {
+	Name        string
+	Notifier    Notifier
+	MinSeverity severity.Severity
+	// EventTypes empty - all event types
+	EventTypes []eventType.EventType
+}
+
+func (r Route) accepts(event Event) bool {
+	if !severity.IsAtLeast(event.Severity, r.MinSeverity) {
+		return false
+	}
+	return len(r.EventTypes) == 0 || slices.Contains(r.EventTypes, event.Type)
+}
+
+// Router fans out events to all routes accepting them. A failed channel doesn't stop delivery to the others,
+// errors are logged and returned joined. Routes of asynchronous channels return only the errors of queueing.
+type Router struct {
+	routes []Route
+}
+
+func NewRouter(routes ...Route) *Router {
+	return &Router{routes: routes}
+}
+
+func (r *Router) Notify(event Event) error {
+	var errs []error
+	for _, route := range r.routes {
+		if !route.accepts(event) {
+			continue
+		}
+		if err := route.Notifier.Notify(event); err != nil {
+			zap.S().Errorf("Error during notification to %s of %s: %s", route.Name, event.Type, err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", route.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close delivers queued events of asynchronous routes, commands have to call it before exit
+func (r *Router) Close(timeout time.Duration) {
+	for _, route := range r.routes {
+		if notifier, ok := route.Notifier.(*asyncNotifier); ok {
+			notifier.stop(timeout)
+		}
+	}
+}
+
+func (r *Router) GetRouteNames() []string {
+	names := make([]string, 0, len(r.routes))
+	for _, route := range r.routes {
+		names = append(names, route.Name)
+	}
+	return names
+}
@@ -0,0 +1,25 @@
+package notification
+
+import "strings"
+
+// SlackNotifier posts events to the Slack incoming webhook
+type SlackNotifier struct {
+	webhookUrl string
+}
+
+func NewSlackNotifier(webhookUrl string) *SlackNotifier {
+	return &SlackNotifier{webhookUrl: webhookUrl}
+}
+
+func (n *SlackNotifier) Notify(event Event) error {
+	text := escapeSlack(event.Message)
+	if event.Title != "" {
+		text = "*" + escapeSlack(event.Title) + "*\n" + text
+	}
+	return postJson(n.webhookUrl, map[string]string{"text": text}, nil)
+}
+
+// escapeSlack escapes control characters of Slack mrkdwn
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
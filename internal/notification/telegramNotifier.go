@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"html"
+	"tradingViewWebhookBot/internal/telegram"
+)
+
+// TelegramNotifier sends events rendered by the message templates, plain text is sent if rendering fails
+type TelegramNotifier struct {
+	client    *telegram.TelegramClient
+	templates *MessageTemplates
+}
+
+func NewTelegramNotifier(client *telegram.TelegramClient, templates *MessageTemplates) *TelegramNotifier {
+	return &TelegramNotifier{client: client, templates: templates}
+}
+
+func (n *TelegramNotifier) Notify(event Event) error {
+	text, err := n.templates.Render(event)
+	if err != nil {
+		logTemplateError(event, err)
+		text = html.EscapeString(event.Text())
+	}
+	return n.client.Send(text)
+}
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+	"tradingViewWebhookBot/internal/constants/eventType"
+	"tradingViewWebhookBot/internal/constants/severity"
+)
+
+// TradeDetails of opened or closed position, rendered by the message templates
+type TradeDetails struct {
+	Strategy string
+	Symbol   string
+	// Side LONG or SHORT
+	Side     string
+	Leverage int64
+	Amount   float64
+
+	EntryPrice float64
+	// ExitPrice zero for opened position
+	ExitPrice  float64
+	StopLoss   float64
+	TakeProfit float64
+
+	// Fee of both legs for closed position
+	Fee float64
+	// ProfitUsd and ProfitPercent are set for closed position only
+	ProfitUsd     float64
+	ProfitPercent float64
+	HoldingTime   time.Duration
+	ExitReason    string
+
+	// AlertId 0 - the order was not triggered by the alert
+	AlertId int64
+	// PartiallyFilled executed amount is less than requested, RequestedAmount is set
+	PartiallyFilled bool
+	RequestedAmount float64
+}
+
+func (t TradeDetails) IsClosed() bool {
+	return t.ExitPrice > 0
+}
+
+// String plain text representation for channels without templates
+func (t TradeDetails) String() string {
+	if !t.IsClosed() {
+		return fmt.Sprintf("%s %s %s x%d: amount %v, entry %v", t.Strategy, t.Symbol, t.Side, t.Leverage, t.Amount, t.EntryPrice)
+	}
+	return fmt.Sprintf("%s %s %s x%d: amount %v, entry %v, exit %v, profit %.2f USD (%.2f%%), held %s, reason %s",
+		t.Strategy, t.Symbol, t.Side, t.Leverage, t.Amount, t.EntryPrice, t.ExitPrice, t.ProfitUsd, t.ProfitPercent,
+		formatDuration(t.HoldingTime), t.ExitReason)
+}
+
+func OrderOpened(trade TradeDetails) Event {
+	event := NewEvent(eventType.ORDER_OPENED, severity.INFO, trade.Symbol+" "+trade.Side+" opened", trade.String())
+	if trade.PartiallyFilled {
+		event.Severity = severity.WARNING
+		event.Message += fmt.Sprintf(", partially filled %v of %v", trade.Amount, trade.RequestedAmount)
+	}
+	event.Trade = &trade
+	return event
+}
+
+func OrderClosed(trade TradeDetails) Event {
+	event := NewEvent(eventType.ORDER_CLOSED, severity.INFO, trade.Symbol+" "+trade.Side+" closed", trade.String())
+	if trade.PartiallyFilled {
+		event.Severity = severity.WARNING
+		event.Message += fmt.Sprintf(", partially filled %v of %v, the rest remains opened", trade.Amount, trade.RequestedAmount)
+	}
+	event.Trade = &trade
+	return event
+}
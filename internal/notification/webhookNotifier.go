@@ -0,0 +1,35 @@
+package notification
+
+import "time"
+
+// WebhookNotifier posts events as JSON to any HTTP endpoint, token is sent as Bearer authorization if set
+type WebhookNotifier struct {
+	url   string
+	token string
+}
+
+type webhookPayload struct {
+	Type     string    `json:"type"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+func NewWebhookNotifier(url string, token string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, token: token}
+}
+
+func (n *WebhookNotifier) Notify(event Event) error {
+	var headers map[string]string
+	if n.token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + n.token}
+	}
+	return postJson(n.url, webhookPayload{
+		Type:     string(event.Type),
+		Severity: string(event.Severity),
+		Title:    event.Title,
+		Message:  event.Message,
+		Time:     event.Time,
+	}, headers)
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AlertRepository struct {
+	db *sqlx.DB
+}
+
+func NewAlertRepository(db *sqlx.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+func (r *AlertRepository) Create(alert *domain.Alert) error {
+	query := `INSERT INTO alerts (tag, ticker, payload, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
+	return r.db.QueryRow(query, alert.Tag, alert.Ticker, alert.Payload, alert.CreatedAt).Scan(&alert.Id)
+}
+
+func (r *AlertRepository) FindById(id int64) (*domain.Alert, error) {
+	var alert domain.Alert
+	query := `SELECT id, tag, ticker, payload, filter_verdicts, created_at FROM alerts WHERE id = $1`
+	if err := r.db.Get(&alert, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error during select alert: %w", err)
+	}
+	return &alert, nil
+}
+
+// FindByPeriod ordered by receive time, from is inclusive and to is exclusive
+func (r *AlertRepository) FindByPeriod(from time.Time, to time.Time) ([]domain.Alert, error) {
+	var alerts []domain.Alert
+	query := `SELECT id, tag, ticker, payload, filter_verdicts, created_at FROM alerts WHERE created_at >= $1 AND created_at < $2 ORDER BY created_at, id`
+	if err := r.db.Select(&alerts, query, from, to); err != nil {
+		return nil, fmt.Errorf("error during select alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (r *AlertRepository) UpdateFilterVerdicts(id int64, filterVerdicts string) error {
+	query := `UPDATE alerts SET filter_verdicts = $1 WHERE id = $2`
+	if _, err := r.db.Exec(query, filterVerdicts, id); err != nil {
+		return fmt.Errorf("error during update of alert filter verdicts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/postgres/candle"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const candleColumns = `coin_id, candle_interval, start_at, open, high, low, close, volume, turnover`
+
+type CandleRepository struct {
+	db *sqlx.DB
+}
+
+func NewCandleRepository(db *sqlx.DB) *CandleRepository {
+	return &CandleRepository{db: db}
+}
+
+// SaveAll inserts candles in one statement, stored candles are overwritten
+func (r *CandleRepository) SaveAll(candles []domain.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	query := `INSERT INTO candles (` + candleColumns + `)
+              VALUES (:coin_id, :candle_interval, :start_at, :open, :high, :low, :close, :volume, :turnover)
+              ON CONFLICT (coin_id, candle_interval, start_at) DO UPDATE
+              SET open = excluded.open, high = excluded.high, low = excluded.low, close = excluded.close,
+                  volume = excluded.volume, turnover = excluded.turnover`
+	if _, err := r.db.NamedExec(query, candles); err != nil {
+		return fmt.Errorf("error during insert candles: %w", err)
+	}
+	return nil
+}
+
+// FindByPeriod ordered by start, from is inclusive and to is exclusive
+func (r *CandleRepository) FindByPeriod(coinId int64, interval string, from time.Time, to time.Time) ([]domain.Candle, error) {
+	var candles []domain.Candle
+	query := `SELECT ` + candleColumns + ` FROM candles
+              WHERE coin_id = $1 AND candle_interval = $2 AND start_at >= $3 AND start_at < $4 ORDER BY start_at`
+	if err := r.db.Select(&candles, query, coinId, interval, from, to); err != nil {
+		return nil, fmt.Errorf("error during select candles: %w", err)
+	}
+	return candles, nil
+}
+
+// FindLastStartAt start of the latest stored candle, nil if there are no candles
+func (r *CandleRepository) FindLastStartAt(coinId int64, interval string) (*time.Time, error) {
+	var lastStartAt sql.NullTime
+	query := `SELECT max(start_at) FROM candles WHERE coin_id = $1 AND candle_interval = $2`
+	if err := r.db.Get(&lastStartAt, query, coinId, interval); err != nil {
+		return nil, fmt.Errorf("error during select last candle: %w", err)
+	}
+	if !lastStartAt.Valid {
+		return nil, nil
+	}
+	return &lastStartAt.Time, nil
+}
+
+// FindGaps pairs of neighbour stored candles with missing candles between them
+func (r *CandleRepository) FindGaps(coinId int64, interval string, intervalMinutes int) ([]candle.CandleGapDto, error) {
+	var gaps []candle.CandleGapDto
+	query := `SELECT gap_from, gap_to
+              FROM (SELECT start_at gap_from, lead(start_at) OVER (ORDER BY start_at) gap_to
+                    FROM candles WHERE coin_id = $1 AND candle_interval = $2) neighbours
+              WHERE gap_to - gap_from > $3 * interval '1 minute'
+              ORDER BY gap_from`
+	if err := r.db.Select(&gaps, query, coinId, interval, intervalMinutes); err != nil {
+		return nil, fmt.Errorf("error during select candle gaps: %w", err)
+	}
+	return gaps, nil
+}
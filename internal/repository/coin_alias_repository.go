@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"tradingViewWebhookBot/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CoinAliasRepository struct {
+	db *sqlx.DB
+}
+
+func NewCoinAliasRepository(db *sqlx.DB) *CoinAliasRepository {
+	return &CoinAliasRepository{db: db}
+}
+
+// FindByExchangeAndAlias alias of the exchange is preferred to the alias of any exchange
+func (r *CoinAliasRepository) FindByExchangeAndAlias(exchange string, alias string) (*domain.CoinAlias, error) {
+	var coinAlias domain.CoinAlias
+	query := `SELECT id, exchange, alias, coin_id FROM coin_aliases
+              WHERE alias = $2 AND (exchange = $1 OR exchange = '')
+              ORDER BY exchange DESC LIMIT 1`
+	if err := r.db.Get(&coinAlias, query, exchange, alias); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error during select coin alias: %w", err)
+	}
+	return &coinAlias, nil
+}
+
+func (r *CoinAliasRepository) FindAll() ([]domain.CoinAlias, error) {
+	var aliases []domain.CoinAlias
+	if err := r.db.Select(&aliases, `SELECT id, exchange, alias, coin_id FROM coin_aliases ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("error during select coin aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+func (r *CoinAliasRepository) Create(coinAlias *domain.CoinAlias) error {
+	query := `INSERT INTO coin_aliases (exchange, alias, coin_id) VALUES ($1, $2, $3) RETURNING id`
+	return r.db.QueryRow(query, coinAlias.Exchange, coinAlias.Alias, coinAlias.CoinId).Scan(&coinAlias.Id)
+}
+
+func (r *CoinAliasRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM coin_aliases WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
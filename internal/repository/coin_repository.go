@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"database/sql"
 	"fmt"
 	"tradingViewWebhookBot/internal/domain"
 
 	"github.com/jmoiron/sqlx"
 )
 
+const coinColumns = `id, coin_name, symbol, enabled, tick_size, qty_step, min_order_qty, max_order_qty, min_notional`
+
 type CoinRepository struct {
 	db *sqlx.DB
 }
@@ -17,7 +20,7 @@ func NewCoinRepository(db *sqlx.DB) *CoinRepository {
 
 func (r *CoinRepository) FindBySymbol(symbol string) (*domain.Coin, error) {
 	coin := &domain.Coin{}
-	query := `SELECT id, coin_name, symbol FROM coins WHERE symbol = $1`
+	query := `SELECT ` + coinColumns + ` FROM coins WHERE symbol = $1`
 	err := r.db.Get(coin, query, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("coin not found with symbol: %s", symbol)
@@ -27,10 +30,58 @@ func (r *CoinRepository) FindBySymbol(symbol string) (*domain.Coin, error) {
 
 func (r *CoinRepository) FindById(id int64) (*domain.Coin, error) {
 	coin := &domain.Coin{}
-	query := `SELECT id, coin_name, symbol FROM coins WHERE id = $1`
+	query := `SELECT ` + coinColumns + ` FROM coins WHERE id = $1`
 	err := r.db.Get(coin, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("coin not found with id: %d", id)
 	}
 	return coin, nil
 }
+
+func (r *CoinRepository) FindAll() ([]domain.Coin, error) {
+	var coins []domain.Coin
+	query := `SELECT ` + coinColumns + ` FROM coins ORDER BY id`
+	if err := r.db.Select(&coins, query); err != nil {
+		return nil, fmt.Errorf("error during select coins: %w", err)
+	}
+	return coins, nil
+}
+
+func (r *CoinRepository) Create(coin *domain.Coin) error {
+	query := `INSERT INTO coins (coin_name, symbol, enabled, tick_size, qty_step, min_order_qty, max_order_qty, min_notional)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              RETURNING id`
+	return r.db.QueryRow(query, coin.Name, coin.Symbol, coin.Enabled, coin.TickSize, coin.QtyStep, coin.MinOrderQty,
+		coin.MaxOrderQty, coin.MinNotional).Scan(&coin.Id)
+}
+
+func (r *CoinRepository) Update(coin *domain.Coin) error {
+	query := `UPDATE coins
+              SET coin_name = $2, enabled = $3, tick_size = $4, qty_step = $5, min_order_qty = $6, max_order_qty = $7, min_notional = $8
+              WHERE id = $1`
+	result, err := r.db.Exec(query, coin.Id, coin.Name, coin.Enabled, coin.TickSize, coin.QtyStep, coin.MinOrderQty,
+		coin.MaxOrderQty, coin.MinNotional)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (r *CoinRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM coins WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const failedMessageColumns = `id, channel, chat_id, text, error, attempts, created_at, replayed_at`
+
+type FailedMessageRepository struct {
+	db *sqlx.DB
+}
+
+func NewFailedMessageRepository(db *sqlx.DB) *FailedMessageRepository {
+	return &FailedMessageRepository{db: db}
+}
+
+func (r *FailedMessageRepository) Create(message *domain.FailedMessage) error {
+	query := `INSERT INTO failed_messages (channel, chat_id, text, error, attempts, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	return r.db.QueryRow(query, message.Channel, message.ChatId, message.Text, message.Error, message.Attempts, message.CreatedAt).
+		Scan(&message.Id)
+}
+
+// FindNotReplayed oldest first
+func (r *FailedMessageRepository) FindNotReplayed(channel string, limit int) ([]domain.FailedMessage, error) {
+	var messages []domain.FailedMessage
+	query := `SELECT ` + failedMessageColumns + ` FROM failed_messages
+              WHERE channel = $1 AND replayed_at IS NULL ORDER BY id LIMIT $2`
+	if err := r.db.Select(&messages, query, channel, limit); err != nil {
+		return nil, fmt.Errorf("error during select failed messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (r *FailedMessageRepository) MarkReplayed(id int64, replayedAt time.Time) error {
+	result, err := r.db.Exec(`UPDATE failed_messages SET replayed_at = $2 WHERE id = $1`, id, replayedAt)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
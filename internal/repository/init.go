@@ -4,13 +4,46 @@ import (
 	"github.com/jmoiron/sqlx"
 	"time"
 	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/futureType"
 	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/postgres/candle"
 	"tradingViewWebhookBot/internal/dto/postgres/transaction"
 )
 
 type Coin interface {
 	FindBySymbol(symbol string) (*domain.Coin, error)
 	FindById(id int64) (*domain.Coin, error)
+	FindAll() ([]domain.Coin, error)
+	Create(coin *domain.Coin) error
+	Update(coin *domain.Coin) error
+	Delete(id int64) error
+}
+
+type CoinAlias interface {
+	FindByExchangeAndAlias(exchange string, alias string) (*domain.CoinAlias, error)
+	FindAll() ([]domain.CoinAlias, error)
+	Create(coinAlias *domain.CoinAlias) error
+	Delete(id int64) error
+}
+
+type Alert interface {
+	Create(alert *domain.Alert) error
+	FindById(id int64) (*domain.Alert, error)
+	FindByPeriod(from time.Time, to time.Time) ([]domain.Alert, error)
+	UpdateFilterVerdicts(id int64, filterVerdicts string) error
+}
+
+type Candle interface {
+	SaveAll(candles []domain.Candle) error
+	FindByPeriod(coinId int64, interval string, from time.Time, to time.Time) ([]domain.Candle, error)
+	FindLastStartAt(coinId int64, interval string) (*time.Time, error)
+	FindGaps(coinId int64, interval string, intervalMinutes int) ([]candle.CandleGapDto, error)
+}
+
+type FailedMessage interface {
+	Create(message *domain.FailedMessage) error
+	FindNotReplayed(channel string, limit int) ([]domain.FailedMessage, error)
+	MarkReplayed(id int64, replayedAt time.Time) error
 }
 
 type Transaction interface {
@@ -33,11 +66,25 @@ type Transaction interface {
 	FindOpenedTransaction(tradingStrategy domain.TradingStrategy) (*domain.Transaction, error)
 	FindAllOpenedTransactions(tradingStrategy domain.TradingStrategy) ([]*domain.Transaction, error)
 	FindOpenedTransactionByCoin(tradingStrategyId int64, coinId int64) (*domain.Transaction, error)
-	FindOpenedTransactionByCoinAndTradingKey(tradingStrategy domain.TradingStrategy, coinId int64, tradingKey string) (*domain.Transaction, error)
+	FindOpenedTransactionByCoinAndTradingKey(tradingStrategyId int64, coinId int64, tradingKey string) (*domain.Transaction, error)
+	FindOpenedTransactionByCoinAndTradingKeyAndFuturesType(tradingStrategyId int64, coinId int64, tradingKey string, futuresType futureType.FuturesType) (*domain.Transaction, error)
+	CalculateNetOpenedAmountByCoin(coinId int64) (float64, error)
+	FindClosedTransactionsWithoutFees(afterId int64, limit int) ([]*domain.Transaction, error)
 
 	FindAllProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error)
 	FetchStatisticByDays(tradingStrategy int, coinIds []int64) ([]transaction.PairTransactionProfitPercentsDto, error)
 	FindAllCoinIds(tradingStrategy int) ([]int64, error)
+	FindRoundTrips(filter transaction.RoundTripFilter) ([]transaction.RoundTripDto, error)
+	ExistsByCoin(coinId int64) (bool, error)
+	ExistsByStrategy(tradingStrategyId int64) (bool, error)
+	FindTransactions(filter transaction.TransactionFilter, limit int, offset int) ([]*domain.Transaction, int64, error)
+	FindByParentTransactionId(parentTransactionId int64) ([]*domain.Transaction, error)
+	FindByAlertId(alertId int64) ([]*domain.Transaction, error)
+	FindOpenedAt(moment time.Time) ([]*domain.Transaction, error)
+	// ClaimForClose marks the opened transaction as being closed, false if it is already closed
+	// or another close claimed it after staleBefore
+	ClaimForClose(id int64, moment time.Time, staleBefore time.Time) (bool, error)
+	ReleaseCloseClaim(id int64) error
 }
 
 type TradingStrategy interface {
@@ -47,10 +94,15 @@ type TradingStrategy interface {
 	Delete(id int64) error
 	List() ([]domain.TradingStrategy, error)
 	FindByTag(tag string) (*domain.TradingStrategy, error)
+	ExistsByTag(tag string, excludeId int64) (bool, error)
 }
 
 type Repository struct {
 	Coin            Coin
+	CoinAlias       CoinAlias
+	Alert           Alert
+	Candle          Candle
+	FailedMessage   FailedMessage
 	Transaction     Transaction
 	TradingStrategy TradingStrategy
 }
@@ -58,6 +110,10 @@ type Repository struct {
 func NewRepositories(postgresDb *sqlx.DB) *Repository {
 	return &Repository{
 		Coin:            NewCoinRepository(postgresDb),
+		CoinAlias:       NewCoinAliasRepository(postgresDb),
+		Alert:           NewAlertRepository(postgresDb),
+		Candle:          NewCandleRepository(postgresDb),
+		FailedMessage:   NewFailedMessageRepository(postgresDb),
 		Transaction:     NewTransactionRepository(postgresDb),
 		TradingStrategy: NewTradingStrategyRepository(postgresDb),
 	}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+)
+
+type AlertRepository struct {
+	alerts []domain.Alert
+	lastId int64
+}
+
+func NewAlertRepository() *AlertRepository {
+	return &AlertRepository{}
+}
+
+// Create keeps the id of the alert if it's set, so replayed alerts have the ids of the stored ones
+func (r *AlertRepository) Create(alert *domain.Alert) error {
+	if alert.Id == 0 {
+		alert.Id = r.lastId + 1
+	}
+	r.lastId = max(r.lastId, alert.Id)
+	r.alerts = append(r.alerts, *alert)
+	return nil
+}
+
+func (r *AlertRepository) FindById(id int64) (*domain.Alert, error) {
+	for _, alert := range r.alerts {
+		if alert.Id == id {
+			return &alert, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *AlertRepository) UpdateFilterVerdicts(id int64, filterVerdicts string) error {
+	for i := range r.alerts {
+		if r.alerts[i].Id == id {
+			r.alerts[i].FilterVerdicts = filterVerdicts
+		}
+	}
+	return nil
+}
+
+func (r *AlertRepository) FindByPeriod(from time.Time, to time.Time) ([]domain.Alert, error) {
+	var result []domain.Alert
+	for _, alert := range r.alerts {
+		if !alert.CreatedAt.Before(from) && alert.CreatedAt.Before(to) {
+			result = append(result, alert)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
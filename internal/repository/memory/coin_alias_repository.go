@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"tradingViewWebhookBot/internal/domain"
+)
+
+type CoinAliasRepository struct {
+	aliases []domain.CoinAlias
+	lastId  int64
+}
+
+func NewCoinAliasRepository(aliases []domain.CoinAlias) *CoinAliasRepository {
+	r := &CoinAliasRepository{aliases: append([]domain.CoinAlias(nil), aliases...)}
+	for _, alias := range aliases {
+		r.lastId = max(r.lastId, alias.Id)
+	}
+	return r
+}
+
+// FindByExchangeAndAlias alias of the exchange is preferred to the alias of any exchange
+func (r *CoinAliasRepository) FindByExchangeAndAlias(exchange string, alias string) (*domain.CoinAlias, error) {
+	var found *domain.CoinAlias
+	for i := range r.aliases {
+		coinAlias := r.aliases[i]
+		if coinAlias.Alias != alias {
+			continue
+		}
+		if coinAlias.Exchange == exchange {
+			return &coinAlias, nil
+		}
+		if coinAlias.Exchange == "" {
+			found = &coinAlias
+		}
+	}
+	return found, nil
+}
+
+func (r *CoinAliasRepository) FindAll() ([]domain.CoinAlias, error) {
+	return append([]domain.CoinAlias(nil), r.aliases...), nil
+}
+
+func (r *CoinAliasRepository) Create(coinAlias *domain.CoinAlias) error {
+	r.lastId++
+	coinAlias.Id = r.lastId
+	r.aliases = append(r.aliases, *coinAlias)
+	return nil
+}
+
+func (r *CoinAliasRepository) Delete(id int64) error {
+	for i := range r.aliases {
+		if r.aliases[i].Id == id {
+			r.aliases = append(r.aliases[:i], r.aliases[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+	"tradingViewWebhookBot/internal/domain"
+)
+
+type CoinRepository struct {
+	coins  []domain.Coin
+	lastId int64
+}
+
+func NewCoinRepository(coins []domain.Coin) *CoinRepository {
+	r := &CoinRepository{coins: append([]domain.Coin(nil), coins...)}
+	for _, coin := range coins {
+		r.lastId = max(r.lastId, coin.Id)
+	}
+	return r
+}
+
+func (r *CoinRepository) FindBySymbol(symbol string) (*domain.Coin, error) {
+	for _, coin := range r.coins {
+		if coin.Symbol == symbol {
+			return &coin, nil
+		}
+	}
+	return nil, fmt.Errorf("coin not found with symbol: %s", symbol)
+}
+
+func (r *CoinRepository) FindById(id int64) (*domain.Coin, error) {
+	for _, coin := range r.coins {
+		if coin.Id == id {
+			return &coin, nil
+		}
+	}
+	return nil, fmt.Errorf("coin not found with id: %d", id)
+}
+
+func (r *CoinRepository) FindAll() ([]domain.Coin, error) {
+	return append([]domain.Coin(nil), r.coins...), nil
+}
+
+func (r *CoinRepository) Create(coin *domain.Coin) error {
+	r.lastId++
+	coin.Id = r.lastId
+	r.coins = append(r.coins, *coin)
+	return nil
+}
+
+func (r *CoinRepository) Update(coin *domain.Coin) error {
+	for i := range r.coins {
+		if r.coins[i].Id == coin.Id {
+			r.coins[i] = *coin
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (r *CoinRepository) Delete(id int64) error {
+	for i := range r.coins {
+		if r.coins[i].Id == id {
+			r.coins = append(r.coins[:i], r.coins[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
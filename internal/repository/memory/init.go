@@ -0,0 +1,42 @@
+// Package memory keeps repositories in memory, used by the backtest so simulated trades never reach the database.
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+)
+
+var errNotSupported = errors.New("not supported by the in-memory repository")
+var errNotFound = errors.New("not found")
+
+// NewRepositories copies of the given strategies, coins and aliases, transactions and alerts start empty
+func NewRepositories(strategies []domain.TradingStrategy, coins []domain.Coin, coinAliases []domain.CoinAlias) *repository.Repository {
+	coinRepo := NewCoinRepository(coins)
+	strategyRepo := NewTradingStrategyRepository(strategies)
+	return &repository.Repository{
+		Coin:            coinRepo,
+		CoinAlias:       NewCoinAliasRepository(coinAliases),
+		Alert:           NewAlertRepository(),
+		Transaction:     NewTransactionRepository(coinRepo, strategyRepo),
+		TradingStrategy: strategyRepo,
+	}
+}
+
+// CopyRepositories strategies, coins and aliases of the source repositories, usually the database ones
+func CopyRepositories(source *repository.Repository) (*repository.Repository, error) {
+	strategies, err := source.TradingStrategy.List()
+	if err != nil {
+		return nil, fmt.Errorf("error during load of strategies: %w", err)
+	}
+	coins, err := source.Coin.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("error during load of coins: %w", err)
+	}
+	coinAliases, err := source.CoinAlias.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("error during load of coin aliases: %w", err)
+	}
+	return NewRepositories(strategies, coins, coinAliases), nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"tradingViewWebhookBot/internal/domain"
+)
+
+type TradingStrategyRepository struct {
+	strategies []domain.TradingStrategy
+	lastId     int64
+}
+
+func NewTradingStrategyRepository(strategies []domain.TradingStrategy) *TradingStrategyRepository {
+	r := &TradingStrategyRepository{strategies: append([]domain.TradingStrategy(nil), strategies...)}
+	for _, strategy := range strategies {
+		r.lastId = max(r.lastId, strategy.Id)
+	}
+	return r
+}
+
+func (r *TradingStrategyRepository) Create(strategy *domain.TradingStrategy) error {
+	r.lastId++
+	strategy.Id = r.lastId
+	r.strategies = append(r.strategies, *strategy)
+	return nil
+}
+
+func (r *TradingStrategyRepository) GetByID(id int64) (*domain.TradingStrategy, error) {
+	for _, strategy := range r.strategies {
+		if strategy.Id == id {
+			return &strategy, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (r *TradingStrategyRepository) Update(strategy *domain.TradingStrategy) error {
+	for i := range r.strategies {
+		if r.strategies[i].Id == strategy.Id {
+			r.strategies[i] = *strategy
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (r *TradingStrategyRepository) Delete(id int64) error {
+	for i := range r.strategies {
+		if r.strategies[i].Id == id {
+			r.strategies = append(r.strategies[:i], r.strategies[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (r *TradingStrategyRepository) List() ([]domain.TradingStrategy, error) {
+	return append([]domain.TradingStrategy(nil), r.strategies...), nil
+}
+
+// FindByTag only enabled strategies, nil if not found
+func (r *TradingStrategyRepository) FindByTag(tag string) (*domain.TradingStrategy, error) {
+	for _, strategy := range r.strategies {
+		if strategy.Tag == tag && strategy.Enabled {
+			return &strategy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *TradingStrategyRepository) ExistsByTag(tag string, excludeId int64) (bool, error) {
+	for _, strategy := range r.strategies {
+		if strategy.Tag == tag && strategy.Id != excludeId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,358 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+)
+
+// TransactionRepository supports what the order flow, the watchdog and the statistics need,
+// aggregations used only by the REST API return errNotSupported.
+type TransactionRepository struct {
+	coinRepo     *CoinRepository
+	strategyRepo *TradingStrategyRepository
+	transactions []domain.Transaction
+}
+
+func NewTransactionRepository(coinRepo *CoinRepository, strategyRepo *TradingStrategyRepository) *TransactionRepository {
+	return &TransactionRepository{coinRepo: coinRepo, strategyRepo: strategyRepo}
+}
+
+// SaveTransaction inserts the transaction without id and updates the existing one otherwise
+func (r *TransactionRepository) SaveTransaction(transaction *domain.Transaction) error {
+	if transaction.Id == 0 {
+		transaction.Id = int64(len(r.transactions) + 1)
+		r.transactions = append(r.transactions, *transaction)
+		return nil
+	}
+	if transaction.Id > int64(len(r.transactions)) {
+		return errNotFound
+	}
+	r.transactions[transaction.Id-1] = *transaction
+	return nil
+}
+
+func (r *TransactionRepository) ClaimForClose(id int64, moment time.Time, staleBefore time.Time) (bool, error) {
+	if id < 1 || id > int64(len(r.transactions)) {
+		return false, errNotFound
+	}
+	transaction := &r.transactions[id-1]
+	if transaction.RelatedTransactionId.Valid || (transaction.ClosingAt.Valid && !transaction.ClosingAt.Time.Before(staleBefore)) {
+		return false, nil
+	}
+	transaction.ClosingAt = sql.NullTime{Time: moment, Valid: true}
+	return true, nil
+}
+
+func (r *TransactionRepository) ReleaseCloseClaim(id int64) error {
+	if id < 1 || id > int64(len(r.transactions)) {
+		return errNotFound
+	}
+	if !r.transactions[id-1].RelatedTransactionId.Valid {
+		r.transactions[id-1].ClosingAt = sql.NullTime{}
+	}
+	return nil
+}
+
+func (r *TransactionRepository) FindById(id int64) (*domain.Transaction, error) {
+	if id < 1 || id > int64(len(r.transactions)) {
+		return nil, nil
+	}
+	transaction := r.transactions[id-1]
+	return &transaction, nil
+}
+
+// findOpened opened transactions matching the condition, the latest first
+func (r *TransactionRepository) findOpened(matches func(transaction *domain.Transaction) bool) []*domain.Transaction {
+	var result []*domain.Transaction
+	for i := range r.transactions {
+		transaction := r.transactions[i]
+		if transaction.RelatedTransactionId.Valid || !matches(&transaction) {
+			continue
+		}
+		result = append(result, &transaction)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (r *TransactionRepository) findLastOpened(matches func(transaction *domain.Transaction) bool) *domain.Transaction {
+	opened := r.findOpened(matches)
+	if len(opened) == 0 {
+		return nil
+	}
+	return opened[0]
+}
+
+func (r *TransactionRepository) FindOpenedTransaction(tradingStrategy domain.TradingStrategy) (*domain.Transaction, error) {
+	return r.findLastOpened(func(t *domain.Transaction) bool {
+		return t.TradingStrategyId.Int64 == tradingStrategy.Id
+	}), nil
+}
+
+// FindAllOpenedTransactions the oldest first as in the database repository
+func (r *TransactionRepository) FindAllOpenedTransactions(tradingStrategy domain.TradingStrategy) ([]*domain.Transaction, error) {
+	opened := r.findOpened(func(t *domain.Transaction) bool {
+		return t.TradingStrategyId.Int64 == tradingStrategy.Id
+	})
+	for i, j := 0, len(opened)-1; i < j; i, j = i+1, j-1 {
+		opened[i], opened[j] = opened[j], opened[i]
+	}
+	return opened, nil
+}
+
+func (r *TransactionRepository) FindOpenedTransactionByCoin(tradingStrategyId int64, coinId int64) (*domain.Transaction, error) {
+	return r.findLastOpened(func(t *domain.Transaction) bool {
+		return t.TradingStrategyId.Int64 == tradingStrategyId && t.CoinId == coinId
+	}), nil
+}
+
+func (r *TransactionRepository) FindOpenedTransactionByCoinAndTradingKey(tradingStrategyId int64, coinId int64, tradingKey string) (*domain.Transaction, error) {
+	return r.findLastOpened(func(t *domain.Transaction) bool {
+		return t.TradingStrategyId.Int64 == tradingStrategyId && t.CoinId == coinId && t.TradingKey == tradingKey
+	}), nil
+}
+
+func (r *TransactionRepository) FindOpenedTransactionByCoinAndTradingKeyAndFuturesType(tradingStrategyId int64, coinId int64, tradingKey string, futuresType futureType.FuturesType) (*domain.Transaction, error) {
+	return r.findLastOpened(func(t *domain.Transaction) bool {
+		return t.TradingStrategyId.Int64 == tradingStrategyId && t.CoinId == coinId && t.TradingKey == tradingKey && t.FuturesType == futuresType
+	}), nil
+}
+
+func (r *TransactionRepository) CalculateNetOpenedAmountByCoin(coinId int64) (float64, error) {
+	var netAmount float64
+	for _, opened := range r.findOpened(func(t *domain.Transaction) bool { return t.CoinId == coinId && !t.IsFake }) {
+		if opened.FuturesType == futureType.SHORT {
+			netAmount -= opened.Amount
+		} else {
+			netAmount += opened.Amount
+		}
+	}
+	return netAmount, nil
+}
+
+// FindRoundTrips closed positions ordered by close time.
+// Fee of the open transaction is split in proportion to the closed amount for partially closed positions.
+func (r *TransactionRepository) FindRoundTrips(filter transaction.RoundTripFilter) ([]transaction.RoundTripDto, error) {
+	var roundTrips []transaction.RoundTripDto
+	for _, c := range r.transactions {
+		if !c.Profit.Valid || !c.RelatedTransactionId.Valid || c.RelatedTransactionId.Int64 > int64(len(r.transactions)) {
+			continue
+		}
+		if (filter.TradingStrategyId != 0 && c.TradingStrategyId.Int64 != filter.TradingStrategyId) ||
+			(filter.CoinId != 0 && c.CoinId != filter.CoinId) ||
+			(filter.From != nil && c.CreatedAt.Before(*filter.From)) ||
+			(filter.To != nil && !c.CreatedAt.Before(*filter.To)) ||
+			(filter.IsFake != nil && c.IsFake != *filter.IsFake) {
+			continue
+		}
+		o := r.transactions[c.RelatedTransactionId.Int64-1]
+
+		closedPart := float64(1)
+		if o.Amount > 0 && c.Amount < o.Amount {
+			closedPart = c.Amount / o.Amount
+		}
+		grossProfit := c.Profit.Int64
+		if c.GrossProfit.Valid {
+			grossProfit = c.GrossProfit.Int64
+		}
+		roundTrip := transaction.RoundTripDto{
+			OpenTransactionId:  o.Id,
+			CloseTransactionId: c.Id,
+			TradingStrategyId:  c.TradingStrategyId.Int64,
+			CoinId:             c.CoinId,
+			FuturesType:        o.FuturesType,
+			TradingKey:         o.TradingKey,
+			Amount:             c.Amount,
+			OpenPrice:          o.Price,
+			ClosePrice:         c.Price,
+			OpenedAt:           o.CreatedAt,
+			ClosedAt:           c.CreatedAt,
+			OpenFee:            o.GetFee() * closedPart,
+			CloseFee:           c.GetFee(),
+			FundingFee:         c.FundingFee.Float64,
+			GrossProfit:        grossProfit,
+			Profit:             c.Profit.Int64,
+			PercentProfit:      c.PercentProfit.Float64,
+			IsFake:             c.IsFake,
+			ExitReason:         c.ExitReason,
+		}
+		if coin, err := r.coinRepo.FindById(c.CoinId); err == nil {
+			roundTrip.CoinSymbol = coin.Symbol
+		}
+		if strategy, err := r.strategyRepo.GetByID(c.TradingStrategyId.Int64); err == nil && strategy != nil {
+			roundTrip.TradingStrategyName = strategy.Name
+		} else if c.TradingStrategyId.Valid {
+			roundTrip.TradingStrategyName = fmt.Sprintf("deleted strategy #%d", c.TradingStrategyId.Int64)
+		}
+		roundTrips = append(roundTrips, roundTrip)
+	}
+
+	sort.SliceStable(roundTrips, func(i, j int) bool {
+		return roundTrips[i].ClosedAt.Before(roundTrips[j].ClosedAt)
+	})
+	return roundTrips, nil
+}
+
+func (r *TransactionRepository) FindByAlertId(alertId int64) ([]*domain.Transaction, error) {
+	var result []*domain.Transaction
+	for i := range r.transactions {
+		if r.transactions[i].AlertId.Valid && r.transactions[i].AlertId.Int64 == alertId {
+			transaction := r.transactions[i]
+			result = append(result, &transaction)
+		}
+	}
+	return result, nil
+}
+
+// FindOpenedAt not fake open transactions which were opened before the moment and closed after it or are still opened, the oldest first
+func (r *TransactionRepository) FindOpenedAt(moment time.Time) ([]*domain.Transaction, error) {
+	var result []*domain.Transaction
+	for i := range r.transactions {
+		transaction := r.transactions[i]
+		if transaction.Profit.Valid || transaction.IsFake || !transaction.CreatedAt.Before(moment) {
+			continue
+		}
+		if transaction.RelatedTransactionId.Valid {
+			closeTransaction, _ := r.FindById(transaction.RelatedTransactionId.Int64)
+			if closeTransaction != nil && closeTransaction.CreatedAt.Before(moment) {
+				continue
+			}
+		}
+		result = append(result, &transaction)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *TransactionRepository) FindLastByCoinId(coinId int64, tradingStrategy domain.TradingStrategy) (*domain.Transaction, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) FindLastByCoinIdAndType(coinId int64, transactionType constants.TransactionType, tradingStrategy domain.TradingStrategy) (*domain.Transaction, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) FindLastBoughtNotSold(coinId int64, tradingStrategy domain.TradingStrategy) (*domain.Transaction, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) FindLastBoughtNotSoldAndDate(date time.Time, tradingStrategy domain.TradingStrategy) (*domain.Transaction, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfProfit(tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfProfitByCoin(coinId int64, tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfProfitByCoinAndTradingKey(coinId int64, tradingStrategy domain.TradingStrategy, tradingKey string) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfSpentTransactions(tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfSpentTransactionsAndCreatedAfter(date time.Time, tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+// CalculateSumOfProfitByDate profit of the transactions created within the UTC day starting at the date
+func (r *TransactionRepository) CalculateSumOfProfitByDate(date time.Time, tradingStrategy domain.TradingStrategy) (int64, error) {
+	var result int64
+	for _, t := range r.transactions {
+		day := t.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if t.Profit.Valid && t.TradingStrategyId.Int64 == tradingStrategy.Id && day.Equal(date) {
+			result += t.Profit.Int64
+		}
+	}
+	return result, nil
+}
+
+func (r *TransactionRepository) FindMinPriceByDate(date time.Time, tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfSpentTransactionsByDate(date time.Time, tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) CalculateSumOfTransactionsByDateAndType(date time.Time, transType constants.TransactionType, tradingStrategy domain.TradingStrategy) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (r *TransactionRepository) FindClosedTransactionsWithoutFees(afterId int64, limit int) ([]*domain.Transaction, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) FindAllProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) FetchStatisticByDays(tradingStrategy int, coinIds []int64) ([]transaction.PairTransactionProfitPercentsDto, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) FindAllCoinIds(tradingStrategy int) ([]int64, error) {
+	return nil, errNotSupported
+}
+
+func (r *TransactionRepository) ExistsByCoin(coinId int64) (bool, error) {
+	return false, errNotSupported
+}
+
+func (r *TransactionRepository) ExistsByStrategy(tradingStrategyId int64) (bool, error) {
+	for _, t := range r.transactions {
+		if t.TradingStrategyId.Valid && t.TradingStrategyId.Int64 == tradingStrategyId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindTransactions the latest first
+func (r *TransactionRepository) FindTransactions(filter transaction.TransactionFilter, limit int, offset int) ([]*domain.Transaction, int64, error) {
+	var found []*domain.Transaction
+	for i := len(r.transactions) - 1; i >= 0; i-- {
+		t := r.transactions[i]
+		if (filter.TradingStrategyId != 0 && t.TradingStrategyId.Int64 != filter.TradingStrategyId) ||
+			(filter.CoinId != 0 && t.CoinId != filter.CoinId) ||
+			(filter.From != nil && t.CreatedAt.Before(*filter.From)) ||
+			(filter.To != nil && !t.CreatedAt.Before(*filter.To)) ||
+			(filter.FuturesType != nil && t.FuturesType != *filter.FuturesType) ||
+			(filter.IsFake != nil && t.IsFake != *filter.IsFake) {
+			continue
+		}
+		found = append(found, &t)
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].CreatedAt.After(found[j].CreatedAt)
+	})
+
+	total := int64(len(found))
+	if offset >= len(found) {
+		return []*domain.Transaction{}, total, nil
+	}
+	found = found[offset:]
+	if limit < len(found) {
+		found = found[:limit]
+	}
+	return found, total, nil
+}
+
+func (r *TransactionRepository) FindByParentTransactionId(parentTransactionId int64) ([]*domain.Transaction, error) {
+	return nil, errNotSupported
+}
@@ -8,6 +8,9 @@ import (
 	"tradingViewWebhookBot/internal/domain"
 )
 
+const tradingStrategyColumns = `id, name, coalesce(description, '') description, tag, enabled, created_at, updated_at,
+        max_holding_minutes, session_end_times, session_end_days, allowed_symbols, alert_filters`
+
 type tradingStrategyRepository struct {
 	db *sqlx.DB
 }
@@ -18,29 +21,35 @@ func NewTradingStrategyRepository(db *sqlx.DB) TradingStrategy {
 
 func (r *tradingStrategyRepository) Create(strategy *domain.TradingStrategy) error {
 	query := `
-        INSERT INTO trading_strategies (name, description, created_at, updated_at)
-        VALUES ($1, $2, $3, $3)
+        INSERT INTO trading_strategies (name, description, tag, enabled, max_holding_minutes, session_end_times,
+                                        session_end_days, allowed_symbols, alert_filters, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
         RETURNING id`
 
 	now := time.Now()
-	return r.db.QueryRow(query, strategy.Name, strategy.Description, now).Scan(&strategy.Id)
+	strategy.CreatedAt = now
+	strategy.UpdatedAt = now
+	return r.db.QueryRow(query,
+		strategy.Name,
+		strategy.Description,
+		strategy.Tag,
+		strategy.Enabled,
+		strategy.MaxHoldingMinutes,
+		strategy.SessionEndTimes,
+		strategy.SessionEndDays,
+		strategy.AllowedSymbols,
+		strategy.AlertFilters,
+		now,
+	).Scan(&strategy.Id)
 }
 
 func (r *tradingStrategyRepository) GetByID(id int64) (*domain.TradingStrategy, error) {
 	strategy := &domain.TradingStrategy{}
-	query := `
-        SELECT id, name, description, created_at, updated_at
+	query := `SELECT ` + tradingStrategyColumns + `
         FROM trading_strategies
         WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&strategy.Id,
-		&strategy.Name,
-		&strategy.Description,
-		&strategy.CreatedAt,
-		&strategy.UpdatedAt,
-	)
-	if err != nil {
+	if err := r.db.Get(strategy, query, id); err != nil {
 		return nil, err
 	}
 	return strategy, nil
@@ -49,13 +58,22 @@ func (r *tradingStrategyRepository) GetByID(id int64) (*domain.TradingStrategy,
 func (r *tradingStrategyRepository) Update(strategy *domain.TradingStrategy) error {
 	query := `
         UPDATE trading_strategies
-        SET name = $1, description = $2, updated_at = $3
-        WHERE id = $4`
+        SET name = $1, description = $2, tag = $3, enabled = $4, max_holding_minutes = $5, session_end_times = $6,
+            session_end_days = $7, allowed_symbols = $8, alert_filters = $9, updated_at = $10
+        WHERE id = $11`
 
+	strategy.UpdatedAt = time.Now()
 	result, err := r.db.Exec(query,
 		strategy.Name,
 		strategy.Description,
-		time.Now(),
+		strategy.Tag,
+		strategy.Enabled,
+		strategy.MaxHoldingMinutes,
+		strategy.SessionEndTimes,
+		strategy.SessionEndDays,
+		strategy.AllowedSymbols,
+		strategy.AlertFilters,
+		strategy.UpdatedAt,
 		strategy.Id,
 	)
 	if err != nil {
@@ -90,38 +108,20 @@ func (r *tradingStrategyRepository) Delete(id int64) error {
 }
 
 func (r *tradingStrategyRepository) List() ([]domain.TradingStrategy, error) {
-	query := `
-        SELECT id, name, description, created_at, updated_at
+	query := `SELECT ` + tradingStrategyColumns + `
         FROM trading_strategies
         ORDER BY id`
 
-	rows, err := r.db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
 	var strategies []domain.TradingStrategy
-	for rows.Next() {
-		var strategy domain.TradingStrategy
-		err := rows.Scan(
-			&strategy.Id,
-			&strategy.Name,
-			&strategy.Description,
-			&strategy.CreatedAt,
-			&strategy.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		strategies = append(strategies, strategy)
+	if err := r.db.Select(&strategies, query); err != nil {
+		return nil, err
 	}
-	return strategies, rows.Err()
+	return strategies, nil
 }
 
 func (r *tradingStrategyRepository) FindByTag(tag string) (*domain.TradingStrategy, error) {
 	var strategy domain.TradingStrategy
-	query := `SELECT id, name, description, tag, enabled, created_at, updated_at 
+	query := `SELECT ` + tradingStrategyColumns + `
               FROM trading_strategies 
               WHERE tag = $1 AND enabled = true`
 
@@ -135,3 +135,13 @@ func (r *tradingStrategyRepository) FindByTag(tag string) (*domain.TradingStrate
 
 	return &strategy, nil
 }
+
+// ExistsByTag checks enabled and disabled strategies, excludeId skips the strategy being updated
+func (r *tradingStrategyRepository) ExistsByTag(tag string, excludeId int64) (bool, error) {
+	var exists bool
+	query := `SELECT exists(SELECT 1 FROM trading_strategies WHERE tag = $1 AND id <> $2)`
+	if err := r.db.Get(&exists, query, tag, excludeId); err != nil {
+		return false, fmt.Errorf("error checking trading strategy tag: %w", err)
+	}
+	return exists, nil
+}
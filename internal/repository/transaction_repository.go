@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/futureType"
 	"tradingViewWebhookBot/internal/domain"
 	"tradingViewWebhookBot/internal/dto/postgres/transaction"
 
@@ -54,9 +55,20 @@ func (r *TransactionRepository) FindOpenedTransactionByCoin(tradingStrategyId in
 	return &transaction, nil
 }
 
-func (r *TransactionRepository) FindOpenedTransactionByCoinAndTradingKey(tradingStrategy domain.TradingStrategy, coinId int64, tradingKey string) (*domain.Transaction, error) {
+func (r *TransactionRepository) FindOpenedTransactionByCoinAndTradingKeyAndFuturesType(tradingStrategyId int64, coinId int64, tradingKey string, futuresType futureType.FuturesType) (*domain.Transaction, error) {
 	var transaction domain.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy_id=$1 AND coin_id=$2 AND trading_key = $3 order by created_at desc limit 1", tradingStrategy, coinId, tradingKey); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy_id=$1 AND coin_id=$2 AND trading_key = $3 AND futures_type=$4 order by created_at desc limit 1", tradingStrategyId, coinId, tradingKey, futuresType); err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *TransactionRepository) FindOpenedTransactionByCoinAndTradingKey(tradingStrategyId int64, coinId int64, tradingKey string) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy_id=$1 AND coin_id=$2 AND trading_key = $3 order by created_at desc limit 1", tradingStrategyId, coinId, tradingKey); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -68,7 +80,7 @@ func (r *TransactionRepository) FindOpenedTransactionByCoinAndTradingKey(trading
 func (r *TransactionRepository) FindAllOpenedTransactions(tradingStrategy domain.TradingStrategy) ([]*domain.Transaction, error) {
 	var klines []domain.Transaction
 	err := r.db.Select(&klines, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy_id=$1 order by created_at desc",
-		tradingStrategy)
+		tradingStrategy.Id)
 
 	if err != nil {
 		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
@@ -77,6 +89,24 @@ func (r *TransactionRepository) FindAllOpenedTransactions(tradingStrategy domain
 	return r.listRelationsToListRelationsPointers(klines), nil
 }
 
+// FindClosedTransactionsWithoutFees close transactions without funding or without exec fee which can be fetched by order id
+func (r *TransactionRepository) FindClosedTransactionsWithoutFees(afterId int64, limit int) ([]*domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.Select(&transactions, "SELECT * FROM transaction_table WHERE profit is not null AND related_transaction_id is not null AND fake = false "+
+		"AND (funding_fee is null OR (exec_fee is null AND client_order_id is not null)) AND id > $1 order by id asc limit $2",
+		afterId, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domain.Transaction, 0, len(transactions))
+	for i := range transactions {
+		result = append(result, &transactions[i])
+	}
+	return result, nil
+}
+
 func (r *TransactionRepository) FindAllProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error) {
 	var profitPercents []transaction.TransactionProfitPercentsDto
 	err := r.db.Select(&profitPercents, "select created_at, sum(percent_profit) profit_percent from transaction_table where trading_strategy_id = $1 and profit is not null group by created_at order by created_at asc;",
@@ -103,6 +133,71 @@ func (r *TransactionRepository) FetchStatisticByDays(tradingStrategy int, coinId
 	return profitPercents, nil
 }
 
+// FindRoundTrips closed positions ordered by close time.
+// Fee of the open transaction is split in proportion to the closed amount for partially closed positions.
+// Round trips of deleted strategies are kept for the history, the strategy name is replaced by its id then.
+func (r *TransactionRepository) FindRoundTrips(filter transaction.RoundTripFilter) ([]transaction.RoundTripDto, error) {
+	query := `SELECT o.id open_transaction_id, c.id close_transaction_id, c.trading_strategy_id,
+       coalesce(s.name, 'deleted strategy #' || c.trading_strategy_id, '') trading_strategy_name,
+       c.coin_id, coin.symbol coin_symbol, o.futures_type, o.trading_key, c.amount, o.price open_price, c.price close_price,
+       o.created_at opened_at, c.created_at closed_at,
+       coalesce(o.exec_fee, o.commission) * least(1, c.amount / nullif(o.amount, 0)) open_fee,
+       coalesce(c.exec_fee, c.commission) close_fee, coalesce(c.funding_fee, 0) funding_fee,
+       coalesce(c.gross_profit, c.profit) gross_profit, c.profit, coalesce(c.percent_profit, 0) percent_profit, c.fake, c.exit_reason
+FROM transaction_table c
+         JOIN transaction_table o ON o.id = c.related_transaction_id
+         JOIN coins coin ON coin.id = c.coin_id
+         LEFT JOIN trading_strategies s ON s.id = c.trading_strategy_id
+WHERE c.profit is not null`
+
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+condition, len(args))
+	}
+	if filter.TradingStrategyId != 0 {
+		addCondition("c.trading_strategy_id = $%d", filter.TradingStrategyId)
+	}
+	if filter.CoinId != 0 {
+		addCondition("c.coin_id = $%d", filter.CoinId)
+	}
+	if filter.From != nil {
+		addCondition("c.created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("c.created_at < $%d", *filter.To)
+	}
+	if filter.IsFake != nil {
+		addCondition("c.fake = $%d", *filter.IsFake)
+	}
+	query += " ORDER BY c.created_at asc, c.id asc"
+
+	var roundTrips []transaction.RoundTripDto
+	if err := r.db.Select(&roundTrips, query, args...); err != nil {
+		return nil, fmt.Errorf("Error during select round trips: %s", err.Error())
+	}
+
+	return roundTrips, nil
+}
+
+// FindOpenedAt not fake open transactions which were opened before the moment and closed after it or are still opened, the oldest first
+func (r *TransactionRepository) FindOpenedAt(moment time.Time) ([]*domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.Select(&transactions, `SELECT o.* FROM transaction_table o
+         LEFT JOIN transaction_table c ON c.id = o.related_transaction_id
+WHERE o.profit is null AND o.fake = false AND o.created_at < $1 AND (o.related_transaction_id is null OR c.created_at >= $1)
+ORDER BY o.created_at asc, o.id asc`, moment)
+	if err != nil {
+		return nil, fmt.Errorf("Error during select opened transactions: %s", err.Error())
+	}
+
+	result := make([]*domain.Transaction, 0, len(transactions))
+	for i := range transactions {
+		result = append(result, &transactions[i])
+	}
+	return result, nil
+}
+
 func (r *TransactionRepository) FindAllCoinIds(tradingStrategy int) ([]int64, error) {
 	var results []int64
 	err := r.db.Select(&results, "select distinct coin_id from transaction_table where trading_strategy_id = $1;",
@@ -192,10 +287,17 @@ func (r *TransactionRepository) CalculateSumOfProfitByCoin(coinId int64, trading
 
 func (r *TransactionRepository) CalculateSumOfProfitByCoinAndTradingKey(coinId int64, tradingStrategy domain.TradingStrategy, tradingKey string) (int64, error) {
 	var sumOfProfit int64
-	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND coin_id=$1 AND trading_strategy_id=$2 AND fake = false trading_key = $3 AND ", coinId, tradingStrategy.Id, tradingKey)
+	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND coin_id=$1 AND trading_strategy_id=$2 AND fake = false AND trading_key = $3", coinId, tradingStrategy.Id, tradingKey)
 	return sumOfProfit, err
 }
 
+// CalculateNetOpenedAmountByCoin signed amount of all real opened positions of the coin: long is positive, short is negative
+func (r *TransactionRepository) CalculateNetOpenedAmountByCoin(coinId int64) (float64, error) {
+	var netAmount sql.NullFloat64
+	err := r.db.Get(&netAmount, "select sum(case when futures_type = $2 then -amount else amount end) from transaction_table where related_transaction_id is null AND coin_id=$1 AND fake = false", coinId, futureType.SHORT)
+	return netAmount.Float64, err
+}
+
 func (r *TransactionRepository) CalculateSumOfSpentTransactions(tradingStrategy domain.TradingStrategy) (int64, error) {
 	var sumOfSpent int64
 	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null AND trading_strategy_id=$1", tradingStrategy.Id)
@@ -210,7 +312,7 @@ func (r *TransactionRepository) CalculateSumOfSpentTransactionsAndCreatedAfter(d
 
 func (r *TransactionRepository) CalculateSumOfProfitByDate(date time.Time, tradingStrategy domain.TradingStrategy) (int64, error) {
 	var sumOfProfit int64
-	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null and date_trunc('day', created_at) = $1 AND trading_strategy_id=$2", date, tradingStrategy.Id)
+	err := r.db.Get(&sumOfProfit, "select coalesce(sum(profit), 0) from transaction_table where profit is not null and date_trunc('day', created_at) = $1 AND trading_strategy_id=$2", date, tradingStrategy.Id)
 	return sumOfProfit, err
 }
 
@@ -240,8 +342,8 @@ func (r *TransactionRepository) SaveTransaction(trnsctn *domain.Transaction) err
 
 	if trnsctn.Id == 0 {
 		transactionId := int64(0)
-		err := tx.QueryRow("INSERT INTO transaction_table (coin_id, transaction_type, amount, price, total_cost, created_at, client_order_id, api_error, related_transaction_id, profit, percent_profit, commission, trading_strategy_id, futures_type, stop_loss_price, take_profit_price, fake, trading_key) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id",
-			trnsctn.CoinId, trnsctn.TransactionType, trnsctn.Amount, trnsctn.Price, trnsctn.TotalCost, trnsctn.CreatedAt, trnsctn.ClientOrderId, trnsctn.ApiError, trnsctn.RelatedTransactionId, trnsctn.Profit, trnsctn.PercentProfit, trnsctn.Commission, trnsctn.TradingStrategyId, trnsctn.FuturesType, trnsctn.StopLossPrice, trnsctn.TakeProfitPrice, trnsctn.IsFake, trnsctn.TradingKey,
+		err := tx.QueryRow("INSERT INTO transaction_table (coin_id, transaction_type, amount, price, total_cost, created_at, client_order_id, api_error, related_transaction_id, profit, percent_profit, commission, trading_strategy_id, futures_type, stop_loss_price, take_profit_price, fake, trading_key, exit_reason, reversed_transaction_id, order_status, requested_amount, parent_transaction_id, exec_fee, funding_fee, gross_profit, alert_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27) RETURNING id",
+			trnsctn.CoinId, trnsctn.TransactionType, trnsctn.Amount, trnsctn.Price, trnsctn.TotalCost, trnsctn.CreatedAt, trnsctn.ClientOrderId, trnsctn.ApiError, trnsctn.RelatedTransactionId, trnsctn.Profit, trnsctn.PercentProfit, trnsctn.Commission, trnsctn.TradingStrategyId, trnsctn.FuturesType, trnsctn.StopLossPrice, trnsctn.TakeProfitPrice, trnsctn.IsFake, trnsctn.TradingKey, trnsctn.ExitReason, trnsctn.ReversedTransactionId, trnsctn.OrderStatus, trnsctn.RequestedAmount, trnsctn.ParentTransactionId, trnsctn.ExecFee, trnsctn.FundingFee, trnsctn.GrossProfit, trnsctn.AlertId,
 		).Scan(&transactionId)
 		if err != nil {
 			_ = tx.Rollback()
@@ -254,8 +356,8 @@ func (r *TransactionRepository) SaveTransaction(trnsctn *domain.Transaction) err
 		return tx.Commit()
 	}
 
-	resp, err := tx.Exec("UPDATE transaction_table SET coin_id = $2, transaction_type = $3, amount = $4, price = $5, total_cost = $6, client_order_id = $7, api_error = $8, related_transaction_id = $9, profit = $10, percent_profit = $11, commission = $12 WHERE id = $1",
-		trnsctn.Id, trnsctn.CoinId, trnsctn.TransactionType, trnsctn.Amount, trnsctn.Price, trnsctn.TotalCost, trnsctn.ClientOrderId, trnsctn.ApiError, trnsctn.RelatedTransactionId, trnsctn.Profit, trnsctn.PercentProfit, trnsctn.Commission)
+	resp, err := tx.Exec("UPDATE transaction_table SET coin_id = $2, transaction_type = $3, amount = $4, price = $5, total_cost = $6, client_order_id = $7, api_error = $8, related_transaction_id = $9, profit = $10, percent_profit = $11, commission = $12, exec_fee = $13, funding_fee = $14, gross_profit = $15, order_status = $16 WHERE id = $1",
+		trnsctn.Id, trnsctn.CoinId, trnsctn.TransactionType, trnsctn.Amount, trnsctn.Price, trnsctn.TotalCost, trnsctn.ClientOrderId, trnsctn.ApiError, trnsctn.RelatedTransactionId, trnsctn.Profit, trnsctn.PercentProfit, trnsctn.Commission, trnsctn.ExecFee, trnsctn.FundingFee, trnsctn.GrossProfit, trnsctn.OrderStatus)
 	if err != nil {
 		_ = tx.Rollback()
 		zap.S().Errorf("Invalid try to update domain on proxy side: %s. "+
@@ -273,3 +375,110 @@ func (r *TransactionRepository) SaveTransaction(trnsctn *domain.Transaction) err
 
 	return tx.Commit()
 }
+
+func (r *TransactionRepository) ClaimForClose(id int64, moment time.Time, staleBefore time.Time) (bool, error) {
+	resp, err := r.db.Exec("UPDATE transaction_table SET closing_at = $2 WHERE id = $1 AND related_transaction_id IS NULL AND (closing_at IS NULL OR closing_at < $3)",
+		id, moment, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("Error during claim of transaction %d: %s", id, err.Error())
+	}
+	count, err := resp.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
+
+func (r *TransactionRepository) ReleaseCloseClaim(id int64) error {
+	if _, err := r.db.Exec("UPDATE transaction_table SET closing_at = NULL WHERE id = $1 AND related_transaction_id IS NULL", id); err != nil {
+		return fmt.Errorf("Error during release of transaction %d: %s", id, err.Error())
+	}
+	return nil
+}
+
+func (r *TransactionRepository) ExistsByCoin(coinId int64) (bool, error) {
+	var exists bool
+	if err := r.db.Get(&exists, "SELECT exists(SELECT 1 FROM transaction_table WHERE coin_id = $1)", coinId); err != nil {
+		return false, fmt.Errorf("Error during select transactions of coin: %s", err.Error())
+	}
+	return exists, nil
+}
+
+func (r *TransactionRepository) ExistsByStrategy(tradingStrategyId int64) (bool, error) {
+	var exists bool
+	if err := r.db.Get(&exists, "SELECT exists(SELECT 1 FROM transaction_table WHERE trading_strategy_id = $1)", tradingStrategyId); err != nil {
+		return false, fmt.Errorf("Error during select transactions of strategy: %s", err.Error())
+	}
+	return exists, nil
+}
+
+// FindTransactions newest first, total is the count of all transactions matching the filter
+func (r *TransactionRepository) FindTransactions(filter transaction.TransactionFilter, limit int, offset int) ([]*domain.Transaction, int64, error) {
+	where := " WHERE true"
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		where += fmt.Sprintf(" AND "+condition, len(args))
+	}
+	if filter.TradingStrategyId != 0 {
+		addCondition("trading_strategy_id = $%d", filter.TradingStrategyId)
+	}
+	if filter.CoinId != 0 {
+		addCondition("coin_id = $%d", filter.CoinId)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	if filter.FuturesType != nil {
+		addCondition("futures_type = $%d", *filter.FuturesType)
+	}
+	if filter.IsFake != nil {
+		addCondition("fake = $%d", *filter.IsFake)
+	}
+
+	var total int64
+	if err := r.db.Get(&total, "SELECT count(*) FROM transaction_table"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("Error during count transactions: %s", err.Error())
+	}
+
+	var transactions []domain.Transaction
+	query := fmt.Sprintf("SELECT * FROM transaction_table%s ORDER BY created_at desc, id desc LIMIT %d OFFSET %d", where, limit, offset)
+	if err := r.db.Select(&transactions, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("Error during select transactions: %s", err.Error())
+	}
+
+	result := make([]*domain.Transaction, 0, len(transactions))
+	for i := range transactions {
+		result = append(result, &transactions[i])
+	}
+	return result, total, nil
+}
+
+func (r *TransactionRepository) FindByParentTransactionId(parentTransactionId int64) ([]*domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if err := r.db.Select(&transactions, "SELECT * FROM transaction_table WHERE parent_transaction_id = $1 ORDER BY created_at, id", parentTransactionId); err != nil {
+		return nil, fmt.Errorf("Error during select transactions by parent: %s", err.Error())
+	}
+
+	result := make([]*domain.Transaction, 0, len(transactions))
+	for i := range transactions {
+		result = append(result, &transactions[i])
+	}
+	return result, nil
+}
+
+func (r *TransactionRepository) FindByAlertId(alertId int64) ([]*domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if err := r.db.Select(&transactions, "SELECT * FROM transaction_table WHERE alert_id = $1 ORDER BY created_at, id", alertId); err != nil {
+		return nil, fmt.Errorf("Error during select transactions by alert: %s", err.Error())
+	}
+
+	result := make([]*domain.Transaction, 0, len(transactions))
+	for i := range transactions {
+		result = append(result, &transactions[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,223 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/exitReason"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/tradingview"
+	"tradingViewWebhookBot/internal/notification"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/filters"
+	"tradingViewWebhookBot/internal/service/orders"
+	"tradingViewWebhookBot/internal/service/symbol"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var ErrInvalidAlert = errors.New("alert is not valid")
+var ErrStrategyNotFound = errors.New("trading strategy not found")
+var ErrCoinNotFound = errors.New("coin not found")
+var ErrSymbolNotAllowed = errors.New("symbol is not allowed")
+var ErrAlertFiltered = errors.New("alert is rejected by the strategy filters")
+
+func NewAlertProcessorService(
+	strategyRepo repository.TradingStrategy,
+	transactionRepo repository.Transaction,
+	symbolMapperService *symbol.SymbolMapperService,
+	orderManagerService *orders.OrderManagerService,
+	alertFilterService *filters.AlertFilterService,
+	notifier notification.Notifier,
+	hedgeMode bool,
+) *AlertProcessorService {
+	return &AlertProcessorService{
+		strategyRepo:        strategyRepo,
+		transactionRepo:     transactionRepo,
+		symbolMapperService: symbolMapperService,
+		orderManagerService: orderManagerService,
+		alertFilterService:  alertFilterService,
+		notifier:            notifier,
+		hedgeMode:           hedgeMode,
+	}
+}
+
+// AlertProcessorService turns a TradingView alert into orders. Shared by the webhook and the backtest,
+// so both go through the same code path. Alerts which open positions are checked by the strategy filters,
+// alertFilterService is nil when alerts are not filtered.
+type AlertProcessorService struct {
+	strategyRepo        repository.TradingStrategy
+	transactionRepo     repository.Transaction
+	symbolMapperService *symbol.SymbolMapperService
+	orderManagerService *orders.OrderManagerService
+	alertFilterService  *filters.AlertFilterService
+	notifier            notification.Notifier
+	hedgeMode           bool
+}
+
+// Process validates the alert, finds its strategy and coin and opens, closes or reverses the position.
+// alert is the stored alert which transactions refer to, nil if it was not stored.
+func (s *AlertProcessorService) Process(alertRequest tradingview.AlertRequestDto, alert *domain.Alert) error {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(alertRequest); err != nil {
+		s.notifier.Notify(notification.Warning("AlertRequest is not valid", alertRequest.String()))
+		return fmt.Errorf("%w: %s", ErrInvalidAlert, err.Error())
+	}
+
+	strategy, err := s.strategyRepo.FindByTag(alertRequest.Tag)
+	if err != nil || strategy == nil {
+		s.notifier.Notify(notification.Warning("Trading strategy not found", alertRequest.Tag))
+		return fmt.Errorf("%w: %s", ErrStrategyNotFound, alertRequest.Tag)
+	}
+
+	coin, err := s.symbolMapperService.FindCoin(alertRequest.Ticker)
+	if err != nil || coin == nil {
+		s.notifier.Notify(notification.Warning("Coin not found", alertRequest.Ticker))
+		return fmt.Errorf("%w: %s", ErrCoinNotFound, alertRequest.Ticker)
+	}
+
+	if !strategy.IsSymbolAllowed(coin.Symbol) {
+		s.notifier.Notify(notification.RiskRejection("Symbol is not allowed", fmt.Sprintf("Symbol %s is not allowed for strategy %s", coin.Symbol, strategy.Tag)))
+		return fmt.Errorf("%w: %s for strategy %s", ErrSymbolNotAllowed, coin.Symbol, strategy.Tag)
+	}
+
+	if s.hedgeMode {
+		err = s.processHedgeModeAlert(strategy, coin, alertRequest, alert)
+	} else {
+		err = s.processOneWayAlert(strategy, coin, alertRequest, alert)
+	}
+	if err != nil {
+		// filtered alert is already notified as a risk rejection
+		if !errors.Is(err, ErrAlertFiltered) {
+			s.notifier.Notify(notification.Error("Alert is not processed", err))
+		}
+		return err
+	}
+	return nil
+}
+
+// processOneWayAlert one position per coin and trading key: alert opens a position, closes or reverses the opened one
+func (s *AlertProcessorService) processOneWayAlert(strategy *domain.TradingStrategy, coin *domain.Coin, alertRequest tradingview.AlertRequestDto, alert *domain.Alert) error {
+	openedTransaction, err := s.transactionRepo.FindOpenedTransactionByCoinAndTradingKey(strategy.Id, coin.Id, alertRequest.TradingKey)
+	if err != nil {
+		return fmt.Errorf("Error during FindOpenedTransactionByCoinAndTradingKey: %d", coin.Id)
+	}
+
+	isReverse := openedTransaction != nil && openedTransaction.FuturesType != alertRequest.GetFuturesType() && alertRequest.IsReverse() && coin.Enabled
+	// filtered reverse only closes the opened position
+	if isReverse && s.checkFilters(strategy, coin, alertRequest, alertRequest.GetFuturesType(), alert) != nil {
+		isReverse = false
+	}
+
+	if openedTransaction == nil {
+		if !coin.Enabled {
+			return fmt.Errorf("Coin %s is disabled, position is not opened", coin.Symbol)
+		}
+		if err := s.checkFilters(strategy, coin, alertRequest, alertRequest.GetFuturesType(), alert); err != nil {
+			return err
+		}
+		s.orderManagerService.OpenOrderAllIn(
+			strategy,
+			coin,
+			alertRequest.TradingKey,
+			alertRequest.GetFuturesType(),
+			alert,
+		)
+	} else if isReverse {
+		s.orderManagerService.ReverseOrder(
+			strategy,
+			openedTransaction,
+			coin,
+			alertRequest.GetPriceFloat(),
+			alert,
+		)
+	} else {
+		s.orderManagerService.CloseOrder(
+			strategy,
+			openedTransaction,
+			coin,
+			alertRequest.GetPriceFloat(),
+			constants.FUTURES,
+			exitReason.SIGNAL,
+			alert,
+		)
+	}
+	return nil
+}
+
+// processHedgeModeAlert long and short positions are independent, so the alert must tell what to do:
+// "close" closes the position which the alert side reduces (sell closes long), "reverse" closes it and opens the alert side,
+// otherwise the alert side position is opened.
+func (s *AlertProcessorService) processHedgeModeAlert(strategy *domain.TradingStrategy, coin *domain.Coin, alertRequest tradingview.AlertRequestDto, alert *domain.Alert) error {
+	alertType := alertRequest.GetFuturesType()
+	oppositeType := futureType.GetTypeByBool(alertType == futureType.SHORT)
+
+	switch alertRequest.Action {
+	case "close", "reverse":
+		openedTransaction, err := s.transactionRepo.FindOpenedTransactionByCoinAndTradingKeyAndFuturesType(strategy.Id, coin.Id, alertRequest.TradingKey, oppositeType)
+		if err != nil {
+			return fmt.Errorf("Error during FindOpenedTransactionByCoinAndTradingKeyAndFuturesType: %d", coin.Id)
+		}
+		if openedTransaction == nil {
+			return fmt.Errorf("No opened %s position of %s to %s", futureType.GetString(oppositeType), coin.Symbol, alertRequest.Action)
+		}
+
+		// disabled coin is only closed, the opposite position is not opened, as well as when the alert is filtered
+		isReverse := alertRequest.Action == "reverse" && coin.Enabled
+		if isReverse && s.checkFilters(strategy, coin, alertRequest, alertType, alert) != nil {
+			isReverse = false
+		}
+		if isReverse {
+			s.orderManagerService.ReverseOrder(strategy, openedTransaction, coin, alertRequest.GetPriceFloat(), alert)
+		} else {
+			s.orderManagerService.CloseOrder(strategy, openedTransaction, coin, alertRequest.GetPriceFloat(), constants.FUTURES, exitReason.SIGNAL, alert)
+		}
+	default:
+		openedTransaction, err := s.transactionRepo.FindOpenedTransactionByCoinAndTradingKeyAndFuturesType(strategy.Id, coin.Id, alertRequest.TradingKey, alertType)
+		if err != nil {
+			return fmt.Errorf("Error during FindOpenedTransactionByCoinAndTradingKeyAndFuturesType: %d", coin.Id)
+		}
+		if openedTransaction != nil {
+			return fmt.Errorf("%s position of %s is already opened", futureType.GetString(alertType), coin.Symbol)
+		}
+		if !coin.Enabled {
+			return fmt.Errorf("Coin %s is disabled, position is not opened", coin.Symbol)
+		}
+		if err := s.checkFilters(strategy, coin, alertRequest, alertType, alert); err != nil {
+			return err
+		}
+
+		s.orderManagerService.OpenOrderAllIn(strategy, coin, alertRequest.TradingKey, alertType, alert)
+	}
+	return nil
+}
+
+// checkFilters before the alert opens a futuresType position, closing of positions is never filtered.
+func (s *AlertProcessorService) checkFilters(strategy *domain.TradingStrategy, coin *domain.Coin, alertRequest tradingview.AlertRequestDto,
+	futuresType futureType.FuturesType, alert *domain.Alert) error {
+	if s.alertFilterService == nil {
+		return nil
+	}
+
+	verdicts, passed := s.alertFilterService.Check(strategy, coin, futuresType, alertRequest.GetPriceFloat(), alert)
+	if passed {
+		return nil
+	}
+
+	var rejections []string
+	for _, verdict := range verdicts {
+		if verdict.Passed {
+			continue
+		}
+		if verdict.Error != "" {
+			rejections = append(rejections, fmt.Sprintf("%s: %s", verdict.Type, verdict.Error))
+		} else {
+			rejections = append(rejections, fmt.Sprintf("%s: %s", verdict.Type, verdict.Reason))
+		}
+	}
+	message := fmt.Sprintf("%s %s of strategy %s: %s", futureType.GetString(futuresType), coin.Symbol, strategy.Tag, strings.Join(rejections, "; "))
+	s.notifier.Notify(notification.RiskRejection("Alert is filtered", message))
+	return fmt.Errorf("%w: %s", ErrAlertFiltered, message)
+}
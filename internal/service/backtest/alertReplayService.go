@@ -0,0 +1,166 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/backtest"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+)
+
+// maxTransactionsWithoutAlert transactions of watchdog and manual closes compared in one replay
+const maxTransactionsWithoutAlert = 10000
+
+// NewAlertReplayService liveRepos are the database repositories, replayRepos the in-memory copy of them
+func NewAlertReplayService(liveRepos *repository.Repository, replayRepos *repository.Repository, candleSource candles.CandleSource,
+	clock date.Clock, settings Settings) (*AlertReplayService, error) {
+	backtestService, err := NewBacktestService(replayRepos, candleSource, clock, settings)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertReplayService{
+		liveRepos:       liveRepos,
+		replayRepos:     replayRepos,
+		backtestService: backtestService,
+		settings:        settings,
+	}, nil
+}
+
+// AlertReplayService replays the stored alerts of the period against the paper exchange and compares the transactions
+// with the live ones. Positions opened live at the start of the period are seeded, so closes of them are replayed too.
+// Strategies and coins are the current ones, changes of them since the incident are not reverted.
+type AlertReplayService struct {
+	liveRepos       *repository.Repository
+	replayRepos     *repository.Repository
+	backtestService *BacktestService
+	settings        Settings
+}
+
+// Replay all adds matched alerts to the result
+func (s *AlertReplayService) Replay(all bool) (*backtest.ReplayResultDto, error) {
+	storedAlerts, err := s.liveRepos.Alert.FindByPeriod(s.settings.From, s.settings.To)
+	if err != nil {
+		return nil, err
+	}
+
+	openedTransactions, err := s.liveRepos.Transaction.FindOpenedAt(s.settings.From)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.backtestService.SeedOpenedPositions(openedTransactions); err != nil {
+		return nil, err
+	}
+
+	alertList := make([]Alert, 0, len(storedAlerts))
+	for _, storedAlert := range storedAlerts {
+		alert := Alert{Id: storedAlert.Id, Time: storedAlert.CreatedAt, Payload: storedAlert.Payload, FilterVerdicts: storedAlert.FilterVerdicts}
+		// not parsed alert is rejected by validation as it was live
+		_ = json.Unmarshal([]byte(storedAlert.Payload), &alert.Request)
+		alertList = append(alertList, alert)
+	}
+	if _, err := s.backtestService.Run(alertList); err != nil {
+		return nil, err
+	}
+
+	result := &backtest.ReplayResultDto{
+		From:            s.settings.From,
+		To:              s.settings.To,
+		Interval:        s.settings.Interval,
+		AlertsCount:     len(storedAlerts),
+		SeededPositions: len(openedTransactions),
+		Alerts:          make([]backtest.AlertReplayDto, 0),
+	}
+	for _, storedAlert := range storedAlerts {
+		alertReplay := backtest.AlertReplayDto{AlertId: storedAlert.Id, Time: &storedAlert.CreatedAt, Tag: storedAlert.Tag, Ticker: storedAlert.Ticker}
+		if alertReplay.Live, err = s.getTradeActions(s.liveRepos.Transaction.FindByAlertId(storedAlert.Id)); err != nil {
+			return nil, err
+		}
+		if alertReplay.Replay, err = s.getTradeActions(s.replayRepos.Transaction.FindByAlertId(storedAlert.Id)); err != nil {
+			return nil, err
+		}
+		compareTradeActions(&alertReplay)
+		if !alertReplay.Matched {
+			result.MismatchedAlerts++
+		}
+		if all || !alertReplay.Matched {
+			result.Alerts = append(result.Alerts, alertReplay)
+		}
+	}
+
+	if result.WithoutAlert.Live, err = s.getTradeActions(s.findWithoutAlert(s.liveRepos.Transaction)); err != nil {
+		return nil, err
+	}
+	if result.WithoutAlert.Replay, err = s.getTradeActions(s.findWithoutAlert(s.replayRepos.Transaction)); err != nil {
+		return nil, err
+	}
+	compareTradeActions(&result.WithoutAlert)
+
+	return result, nil
+}
+
+// findWithoutAlert not fake transactions of the period which are not linked to an alert, the oldest first
+func (s *AlertReplayService) findWithoutAlert(transactionRepo repository.Transaction) ([]*domain.Transaction, error) {
+	isFake := false
+	transactions, _, err := transactionRepo.FindTransactions(transaction.TransactionFilter{From: &s.settings.From, To: &s.settings.To, IsFake: &isFake},
+		maxTransactionsWithoutAlert, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*domain.Transaction
+	for i := len(transactions) - 1; i >= 0; i-- {
+		if !transactions[i].AlertId.Valid {
+			result = append(result, transactions[i])
+		}
+	}
+	return result, nil
+}
+
+func (s *AlertReplayService) getTradeActions(transactions []*domain.Transaction, err error) ([]backtest.TradeActionDto, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]backtest.TradeActionDto, 0, len(transactions))
+	for _, t := range transactions {
+		action := backtest.TradeActionDto{
+			TransactionId: t.Id,
+			Action:        "open",
+			Side:          futureType.GetString(t.FuturesType),
+			TradingKey:    t.TradingKey,
+			Amount:        t.Amount,
+			Price:         t.Price,
+			ExitReason:    t.ExitReason.String,
+			CreatedAt:     t.CreatedAt,
+		}
+		if t.Profit.Valid || t.ExitReason.Valid {
+			action.Action = "close"
+		}
+		if coin, err := s.liveRepos.Coin.FindById(t.CoinId); err == nil {
+			action.Symbol = coin.Symbol
+		}
+		result = append(result, action)
+	}
+	return result, nil
+}
+
+// compareTradeActions live and replayed actions are compared one by one in time order
+func compareTradeActions(alertReplay *backtest.AlertReplayDto) {
+	for i := 0; i < max(len(alertReplay.Live), len(alertReplay.Replay)); i++ {
+		live, replay := "none", "none"
+		if i < len(alertReplay.Live) {
+			live = alertReplay.Live[i].String()
+		}
+		if i < len(alertReplay.Replay) {
+			replay = alertReplay.Replay[i].String()
+		}
+		if live != replay {
+			alertReplay.Differences = append(alertReplay.Differences, fmt.Sprintf("#%d live: %s, replay: %s", i+1, live, replay))
+		}
+	}
+	alertReplay.Matched = len(alertReplay.Differences) == 0
+}
@@ -0,0 +1,233 @@
+package backtest
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/backtest"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/trade"
+	"tradingViewWebhookBot/internal/dto/tradingview"
+	"tradingViewWebhookBot/internal/notification"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/alerts"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/filters"
+	"tradingViewWebhookBot/internal/service/orders"
+	"tradingViewWebhookBot/internal/service/statistics"
+	"tradingViewWebhookBot/internal/service/symbol"
+	"tradingViewWebhookBot/internal/service/watchdog"
+	"tradingViewWebhookBot/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// Settings of one backtest run, From is inclusive and To is exclusive
+type Settings struct {
+	Interval   string
+	From       time.Time
+	To         time.Time
+	Balance    float64
+	Commission float64
+	Leverage   int64
+	HedgeMode  bool
+}
+
+// Alert recorded TradingView alert with the time it was received, Id and FilterVerdicts are set for alerts stored by the bot
+type Alert struct {
+	Id      int64
+	Time    time.Time
+	Request tradingview.AlertRequestDto
+	Payload string
+
+	/* Verdicts of the live filter check, the spread and funding filters reuse their values as the past ticker is not available */
+	FilterVerdicts string
+}
+
+// LoadAlerts one alert json per line with the "time" field in RFC 3339 next to the alert fields,
+// e.g. {"time": "2024-05-01T10:00:00Z", "tag": "trend", "ticker": "BTCUSDT", "price": "60000", "side": "buy"}.
+// Alerts are sorted by time.
+func LoadAlerts(reader io.Reader) ([]Alert, error) {
+	var result []Alert
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var timeDto struct {
+			Time time.Time `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(line), &timeDto); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if timeDto.Time.IsZero() {
+			return nil, fmt.Errorf("line %d: time is required", lineNumber)
+		}
+
+		alert := Alert{Time: timeDto.Time.UTC(), Payload: line}
+		if err := json.Unmarshal([]byte(line), &alert.Request); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		result = append(result, alert)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result, nil
+}
+
+// NewBacktestService repos must be in-memory ones, the order manager is a singleton, so only one backtest can be created per process.
+// Strategy filters use the candles of the source, there are no historical tickers, so spread and funding filters use the values
+// recorded on stored alerts and can't be checked for alerts of a file.
+func NewBacktestService(repos *repository.Repository, candleSource candles.CandleSource, clock date.Clock, settings Settings) (*BacktestService, error) {
+	notifier := notification.NewRouter()
+	exchange, err := NewSimulatedExchange(candleSource, clock, settings.Interval, settings.From, settings.To,
+		settings.Balance, settings.Commission, settings.Leverage)
+	if err != nil {
+		return nil, err
+	}
+	orderManagerService := orders.NewOrderManagerService(repos.Transaction, repos.TradingStrategy, exchange, clock, notifier, settings.Leverage)
+	symbolMapperService := symbol.NewSymbolMapperService(repos.Coin, repos.CoinAlias)
+
+	return &BacktestService{
+		repos:    repos,
+		exchange: exchange,
+		clock:    clock,
+		settings: settings,
+		alertProcessorService: alerts.NewAlertProcessorService(repos.TradingStrategy, repos.Transaction, symbolMapperService,
+			orderManagerService, filters.NewAlertFilterService(repos.Alert, candleSource, exchange, nil, clock), notifier, settings.HedgeMode),
+		watchdogService: watchdog.NewPositionWatchdogService(repos.TradingStrategy, repos.Transaction, repos.Coin,
+			exchange, orderManagerService, clock, 0),
+	}, nil
+}
+
+// BacktestService replays recorded alerts against historical candles through the same alert processing
+// and order manager as the live bot. The clock is moved candle by candle: time exits of the watchdog are checked
+// at the start of every candle, then the alerts received during the candle are processed at their own time.
+type BacktestService struct {
+	repos                 *repository.Repository
+	exchange              *SimulatedExchange
+	clock                 date.Clock
+	settings              Settings
+	alertProcessorService *alerts.AlertProcessorService
+	watchdogService       *watchdog.PositionWatchdogService
+}
+
+// SeedOpenedPositions copies positions which were opened before the start of the period, e.g. live positions for a replay.
+// Seeded transactions get new ids and are opened until an alert or the watchdog closes them.
+func (s *BacktestService) SeedOpenedPositions(openedTransactions []*domain.Transaction) error {
+	for _, openedTransaction := range openedTransactions {
+		seeded := *openedTransaction
+		seeded.Id = 0
+		seeded.RelatedTransactionId = sql.NullInt64{}
+		if err := s.repos.Transaction.SaveTransaction(&seeded); err != nil {
+			return err
+		}
+		s.exchange.AddPosition(&seeded)
+	}
+	return nil
+}
+
+// Run alerts must be sorted by time, alerts outside of the period are skipped
+func (s *BacktestService) Run(alertList []Alert) (*backtest.BacktestResultDto, error) {
+	intervalDuration, err := util.ParseKlineInterval(s.settings.Interval)
+	if err != nil {
+		return nil, err
+	}
+	if !s.settings.From.Before(s.settings.To) {
+		return nil, fmt.Errorf("from %v must be before to %v", s.settings.From, s.settings.To)
+	}
+
+	result := &backtest.BacktestResultDto{
+		From:           s.settings.From,
+		To:             s.settings.To,
+		Interval:       s.settings.Interval,
+		InitialBalance: s.settings.Balance,
+	}
+
+	alertIndex := 0
+	for alertIndex < len(alertList) && alertList[alertIndex].Time.Before(s.settings.From) {
+		alertIndex++
+	}
+	for candleStart := s.settings.From; candleStart.Before(s.settings.To); candleStart = candleStart.Add(intervalDuration) {
+		s.clock.SetTime(candleStart)
+		s.watchdogService.CheckOpenedPositions()
+
+		candleEnd := candleStart.Add(intervalDuration)
+		for ; alertIndex < len(alertList) && alertList[alertIndex].Time.Before(candleEnd) && alertList[alertIndex].Time.Before(s.settings.To); alertIndex++ {
+			result.AlertsCount++
+			if err := s.processAlert(alertList[alertIndex]); err != nil {
+				result.RejectedAlerts++
+			}
+		}
+	}
+	s.clock.SetTime(s.settings.To)
+
+	roundTrips, err := s.repos.Transaction.FindRoundTrips(transaction.RoundTripFilter{})
+	if err != nil {
+		return nil, err
+	}
+	result.Trades = roundTrips
+	result.Statistic = statistics.CalculateStatistic(roundTrips, &s.settings.From, &s.settings.To)
+	result.FinalBalance = s.exchange.GetBalance()
+
+	if result.OpenedPositions, err = s.getOpenedPositions(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *BacktestService) processAlert(alert Alert) error {
+	s.clock.SetTime(alert.Time)
+
+	storedAlert := &domain.Alert{Id: alert.Id, Tag: alert.Request.Tag, Ticker: alert.Request.Ticker, Payload: alert.Payload,
+		FilterVerdicts: alert.FilterVerdicts, CreatedAt: alert.Time}
+	if err := s.repos.Alert.Create(storedAlert); err != nil {
+		return err
+	}
+
+	if err := s.alertProcessorService.Process(alert.Request, storedAlert); err != nil {
+		zap.S().Infof("Alert at %v is rejected: %s", alert.Time, err.Error())
+		return err
+	}
+	return nil
+}
+
+// getOpenedPositions positions left opened at the end of the period, they are not counted in the statistic
+func (s *BacktestService) getOpenedPositions() ([]trade.TransactionDto, error) {
+	strategies, err := s.repos.TradingStrategy.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]trade.TransactionDto, 0)
+	for _, strategy := range strategies {
+		openedTransactions, err := s.repos.Transaction.FindAllOpenedTransactions(strategy)
+		if err != nil {
+			return nil, err
+		}
+		for _, openedTransaction := range openedTransactions {
+			coinSymbol := ""
+			if coin, err := s.repos.Coin.FindById(openedTransaction.CoinId); err == nil {
+				coinSymbol = coin.Symbol
+			}
+			result = append(result, trade.NewTransactionDto(openedTransaction, coinSymbol))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,256 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/util"
+)
+
+var errNotSimulated = errors.New("not simulated by the backtest exchange")
+
+func NewSimulatedExchange(candleSource candles.CandleSource, clock date.Clock, interval string, from time.Time, to time.Time,
+	balance float64, commission float64, leverage int64) (*SimulatedExchange, error) {
+	intervalDuration, err := util.ParseKlineInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	return &SimulatedExchange{
+		candleSource:     candleSource,
+		clock:            clock,
+		interval:         interval,
+		intervalDuration: intervalDuration,
+		from:             from,
+		to:               to,
+		balance:          balance,
+		commission:       commission,
+		leverage:         leverage,
+		candles:          make(map[int64][]domain.Candle),
+		netPositions:     make(map[int64]float64),
+	}, nil
+}
+
+// SimulatedExchange fills market orders at the open of the candle which contains the clock time.
+// The commission is taken from the order cost, margin of opened positions is cost / leverage.
+// Stop loss and take profit orders are not triggered and funding is not charged,
+// positions are closed by alerts and the watchdog only.
+type SimulatedExchange struct {
+	candleSource     candles.CandleSource
+	clock            date.Clock
+	interval         string
+	intervalDuration time.Duration
+	from             time.Time
+	to               time.Time
+	commission       float64
+	leverage         int64
+
+	/* Realised balance: initial balance + closed profit - fees */
+	balance      float64
+	margin       float64
+	candles      map[int64][]domain.Candle
+	netPositions map[int64]float64
+}
+
+// GetCurrentCoinPrice first price at or after the clock time, candles of the coin are loaded on the first call.
+// The open of the candle containing the moment is in the past unless the moment is its start, so an alert inside a candle,
+// e.g. received a few seconds after the bar close, is filled at the open of the next candle as a market order would be.
+// The close of the candle is used when the next one is not available, e.g. at the end of the period.
+// Watchdog checks run at the candle starts and get their opens.
+func (e *SimulatedExchange) GetCurrentCoinPrice(coin *domain.Coin) (float64, error) {
+	moment := e.clock.NowTime()
+	candle, err := e.GetCandle(coin, moment)
+	if err != nil {
+		return 0, err
+	}
+	if candle.StartAt.Equal(moment) {
+		return candle.Open, nil
+	}
+
+	nextCandle, err := e.GetCandle(coin, candle.StartAt.Add(e.intervalDuration))
+	if err != nil {
+		return candle.Close, nil
+	}
+	return nextCandle.Open, nil
+}
+
+// GetCandle candle which contains the moment
+func (e *SimulatedExchange) GetCandle(coin *domain.Coin, moment time.Time) (*domain.Candle, error) {
+	candles, err := e.getCandles(coin)
+	if err != nil {
+		return nil, err
+	}
+
+	index := sort.Search(len(candles), func(i int) bool {
+		return candles[i].StartAt.After(moment)
+	}) - 1
+	if index < 0 || !moment.Before(candles[index].StartAt.Add(e.intervalDuration)) {
+		return nil, fmt.Errorf("no %s candle of %s at %v", e.interval, coin.Symbol, moment)
+	}
+	return &candles[index], nil
+}
+
+func (e *SimulatedExchange) getCandles(coin *domain.Coin) ([]domain.Candle, error) {
+	if candles, ok := e.candles[coin.Id]; ok {
+		return candles, nil
+	}
+
+	candles, err := e.candleSource.GetCandles(coin, e.interval, e.from, e.to)
+	if err != nil {
+		return nil, err
+	}
+	e.candles[coin.Id] = candles
+	return candles, nil
+}
+
+func (e *SimulatedExchange) OpenFuturesOrder(coin *domain.Coin, amount float64, price float64, futuresType futureType.FuturesType, stopLossPriceInCents float64) (api.OrderResponseDto, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount of %s order must be positive, available balance %.2f", coin.Symbol, e.getAvailableBalance())
+	}
+	order, err := e.fillOrder(coin, amount)
+	if err != nil {
+		return nil, err
+	}
+	if margin := order.CalculateTotalCost() / float64(e.leverage); margin > e.getAvailableBalance() {
+		return nil, fmt.Errorf("insufficient balance for %s order: margin %.2f, available %.2f", coin.Symbol, margin, e.getAvailableBalance())
+	}
+
+	e.balance -= order.CalculateCommissionInUsd()
+	e.margin += order.CalculateTotalCost() / float64(e.leverage)
+	e.netPositions[coin.Id] += amount * futureType.GetFuturesSignFloat64(futuresType)
+	return order, nil
+}
+
+func (e *SimulatedExchange) CloseFuturesOrder(coin *domain.Coin, openedTransaction *domain.Transaction, price float64) (api.OrderResponseDto, error) {
+	order, err := e.fillOrder(coin, openedTransaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := futureType.GetFuturesSignFloat64(openedTransaction.FuturesType)
+	e.balance += (order.CalculateTotalCost()-openedTransaction.TotalCost)*sign - order.CalculateCommissionInUsd()
+	e.margin -= openedTransaction.TotalCost / float64(e.leverage)
+	e.netPositions[coin.Id] -= openedTransaction.Amount * sign
+	return order, nil
+}
+
+// AddPosition position which was opened before the backtest, only its margin is taken from the balance
+func (e *SimulatedExchange) AddPosition(openedTransaction *domain.Transaction) {
+	e.margin += openedTransaction.TotalCost / float64(e.leverage)
+	e.netPositions[openedTransaction.CoinId] += openedTransaction.Amount * futureType.GetFuturesSignFloat64(openedTransaction.FuturesType)
+}
+
+func (e *SimulatedExchange) fillOrder(coin *domain.Coin, amount float64) (*SimulatedOrderDto, error) {
+	price, err := e.GetCurrentCoinPrice(coin)
+	if err != nil {
+		return nil, err
+	}
+	createdAt := e.clock.NowTime()
+	return &SimulatedOrderDto{
+		Amount:     amount,
+		Price:      price,
+		Commission: amount * price * e.commission,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+func (e *SimulatedExchange) getAvailableBalance() float64 {
+	return e.balance - e.margin
+}
+
+// GetBalance realised balance without the result of opened positions
+func (e *SimulatedExchange) GetBalance() float64 {
+	return e.balance
+}
+
+func (e *SimulatedExchange) GetWalletBalance() (api.WalletBalanceDto, error) {
+	return &SimulatedWalletBalanceDto{AvailableBalance: e.getAvailableBalance()}, nil
+}
+
+func (e *SimulatedExchange) GetNetPositionSize(coin *domain.Coin) (float64, error) {
+	return e.netPositions[coin.Id], nil
+}
+
+// GetOrderExecFee is not called as simulated orders have no id, the commission is used instead
+func (e *SimulatedExchange) GetOrderExecFee(coin *domain.Coin, orderId string) (float64, error) {
+	return 0, errNotSimulated
+}
+
+func (e *SimulatedExchange) GetFundingFee(coin *domain.Coin, futuresType futureType.FuturesType, amount float64, from time.Time, to time.Time) (float64, error) {
+	return 0, nil
+}
+
+func (e *SimulatedExchange) BuyCoinByMarket(coin *domain.Coin, amount float64, price float64) (api.OrderResponseDto, error) {
+	return nil, errNotSimulated
+}
+
+func (e *SimulatedExchange) SellCoinByMarket(coin *domain.Coin, amount float64, price float64) (api.OrderResponseDto, error) {
+	return nil, errNotSimulated
+}
+
+func (e *SimulatedExchange) SetFuturesLeverage(coin *domain.Coin, leverage int) error {
+	return nil
+}
+
+func (e *SimulatedExchange) SetIsolatedMargin(coin *domain.Coin, leverage int) error {
+	return nil
+}
+
+func (e *SimulatedExchange) SwitchPositionMode(coin *domain.Coin, hedgeMode bool) error {
+	return nil
+}
+
+// GetOrder is not called as simulated orders are always final
+func (e *SimulatedExchange) GetOrder(coin *domain.Coin, orderId string) (api.OrderResponseDto, error) {
+	return nil, errNotSimulated
+}
+
+func (e *SimulatedExchange) GetInstrumentInfo(symbol string) (api.InstrumentInfoDto, error) {
+	return nil, errNotSimulated
+}
+
+// SimulatedOrderDto market order filled completely at one price
+type SimulatedOrderDto struct {
+	Amount     float64
+	Price      float64
+	Commission float64
+	CreatedAt  time.Time
+}
+
+func (d *SimulatedOrderDto) CalculateAvgPrice() float64 {
+	return d.Price
+}
+
+func (d *SimulatedOrderDto) CalculateTotalCost() float64 {
+	return d.Amount * d.Price
+}
+
+func (d *SimulatedOrderDto) CalculateCommissionInUsd() float64 {
+	return d.Commission
+}
+
+func (d *SimulatedOrderDto) GetAmount() float64 {
+	return d.Amount
+}
+
+func (d *SimulatedOrderDto) GetCreatedAt() *time.Time {
+	return &d.CreatedAt
+}
+
+// GetOrderId empty, so the exec fee is not requested from the exchange
+func (d *SimulatedOrderDto) GetOrderId() string {
+	return ""
+}
+
+type SimulatedWalletBalanceDto struct {
+	AvailableBalance float64
+}
+
+func (d *SimulatedWalletBalanceDto) GetAvailableBalance() float64 {
+	return d.AvailableBalance
+}
@@ -0,0 +1,199 @@
+package candles
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// klinesLimit max candles of one Bybit kline request
+const klinesLimit = 200
+
+// pageSize candles of one page, the request of a page starts one candle earlier
+const pageSize = klinesLimit - 1
+
+// maxAttempts of one kline request, rate limit errors of Bybit are retried with backoff
+const maxAttempts = 5
+
+// NewCandleDownloadService requestDelay pause between kline requests to stay under the rate limit
+func NewCandleDownloadService(candleRepo repository.Candle, klinesApi api.KlinesApi, clock date.Clock, requestDelay time.Duration) *CandleDownloadService {
+	return &CandleDownloadService{
+		candleRepo:   candleRepo,
+		klinesApi:    klinesApi,
+		clock:        clock,
+		requestDelay: requestDelay,
+	}
+}
+
+// CandleDownloadService downloads closed candles from the exchange to the local candle store
+type CandleDownloadService struct {
+	candleRepo   repository.Candle
+	klinesApi    api.KlinesApi
+	clock        date.Clock
+	requestDelay time.Duration
+}
+
+// Update downloads candles after the latest stored one, from backfillFrom when nothing is stored yet,
+// then tries to download the missing candles between stored ones
+func (s *CandleDownloadService) Update(coin *domain.Coin, interval string, backfillFrom time.Time) (int, error) {
+	intervalDuration, err := util.ParseKlineInterval(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	from := backfillFrom.Truncate(intervalDuration)
+	lastStartAt, err := s.candleRepo.FindLastStartAt(coin.Id, interval)
+	if err != nil {
+		return 0, err
+	}
+	if lastStartAt != nil {
+		from = lastStartAt.Add(intervalDuration)
+	}
+
+	downloaded, err := s.Download(coin, interval, from, s.clock.NowTime())
+	if err != nil {
+		return downloaded, err
+	}
+
+	filled, err := s.FillGaps(coin, interval)
+	return downloaded + filled, err
+}
+
+// Download stores closed candles of the period page by page, so a failed download is continued by the next Update
+func (s *CandleDownloadService) Download(coin *domain.Coin, interval string, from time.Time, to time.Time) (int, error) {
+	count := 0
+	err := s.fetchPages(coin, interval, from, to, func(candles []domain.Candle) error {
+		if err := s.candleRepo.SaveAll(candles); err != nil {
+			return err
+		}
+		count += len(candles)
+		return nil
+	})
+	return count, err
+}
+
+// FillGaps downloads missing candles between stored ones.
+// The exchange has no candles for some periods, e.g. maintenance, so such gaps stay and are reported on every call.
+func (s *CandleDownloadService) FillGaps(coin *domain.Coin, interval string) (int, error) {
+	intervalDuration, err := util.ParseKlineInterval(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	gaps, err := s.candleRepo.FindGaps(coin.Id, interval, int(intervalDuration/time.Minute))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, gap := range gaps {
+		downloaded, err := s.Download(coin, interval, gap.From.Add(intervalDuration), gap.To)
+		if err != nil {
+			return count, err
+		}
+		count += downloaded
+		if missing := int(gap.To.Sub(gap.From)/intervalDuration) - 1 - downloaded; missing > 0 {
+			zap.S().Warnf("%d %s candles of %s are missing between %v and %v", missing, interval, coin.Symbol, gap.From, gap.To)
+		}
+	}
+	return count, nil
+}
+
+// FetchCandles closed candles of the period ordered by start without storing them, from is inclusive and to is exclusive
+func (s *CandleDownloadService) FetchCandles(coin *domain.Coin, interval string, from time.Time, to time.Time) ([]domain.Candle, error) {
+	var result []domain.Candle
+	err := s.fetchPages(coin, interval, from, to, func(candles []domain.Candle) error {
+		result = append(result, candles...)
+		return nil
+	})
+	return result, err
+}
+
+func (s *CandleDownloadService) fetchPages(coin *domain.Coin, interval string, from time.Time, to time.Time, handlePage func(candles []domain.Candle) error) error {
+	intervalDuration, err := util.ParseKlineInterval(interval)
+	if err != nil {
+		return err
+	}
+
+	// the not closed candle is changing, it's downloaded by the next Update
+	if lastClosedEnd := s.clock.NowTime().Truncate(intervalDuration); to.After(lastClosedEnd) {
+		to = lastClosedEnd
+	}
+
+	for pageStart := from; pageStart.Before(to); pageStart = pageStart.Add(intervalDuration * pageSize) {
+		pageEnd := pageStart.Add(intervalDuration * pageSize)
+		if pageEnd.After(to) {
+			pageEnd = to
+		}
+
+		klinesDto, err := s.getKlines(coin, interval, pageStart.Add(-intervalDuration))
+		if err != nil {
+			return fmt.Errorf("error during GetKlinesFutures of %s from %v: %w", coin.Symbol, pageStart, err)
+		}
+
+		if err := handlePage(toCandles(coin, interval, klinesDto.GetKlines(), pageStart, pageEnd)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getKlines the range of a request includes both ends and only the latest candles of it are returned when it's longer
+// than the limit, so the request starts one candle before the page to not lose its first candle
+func (s *CandleDownloadService) getKlines(coin *domain.Coin, interval string, fromTime time.Time) (api.KlinesDto, error) {
+	var err error
+	backoff := s.requestDelay
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		time.Sleep(s.requestDelay)
+
+		var klinesDto api.KlinesDto
+		if klinesDto, err = s.klinesApi.GetKlinesFutures(coin, interval, klinesLimit, fromTime); err == nil {
+			return klinesDto, nil
+		}
+
+		if attempt < maxAttempts {
+			zap.S().Warnf("Klines of %s are not received, attempt %d: %s", coin.Symbol, attempt, err.Error())
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return nil, err
+}
+
+// toCandles candles of the page ordered by start, the exchange returns them in reverse
+func toCandles(coin *domain.Coin, interval string, klines []api.KlineDto, from time.Time, to time.Time) []domain.Candle {
+	candles := make([]domain.Candle, 0, len(klines))
+	for _, kline := range klines {
+		startAt := kline.GetStartAt().UTC()
+		if startAt.Before(from) || !startAt.Before(to) {
+			continue
+		}
+		candles = append(candles, domain.Candle{
+			CoinId:   coin.Id,
+			Interval: interval,
+			StartAt:  startAt,
+			Open:     kline.GetOpen(),
+			High:     kline.GetHigh(),
+			Low:      kline.GetLow(),
+			Close:    kline.GetClose(),
+			Volume:   kline.GetVolume(),
+			Turnover: kline.GetTurnover(),
+		})
+	}
+
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].StartAt.Before(candles[j].StartAt)
+	})
+	return candles
+}
@@ -0,0 +1,74 @@
+package candles
+
+import (
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/util"
+)
+
+// CandleSource closed candles of the coin ordered by start, from is inclusive and to is exclusive
+type CandleSource interface {
+	GetCandles(coin *domain.Coin, interval string, from time.Time, to time.Time) ([]domain.Candle, error)
+}
+
+func NewDatabaseCandleSource(candleRepo repository.Candle) *DatabaseCandleSource {
+	return &DatabaseCandleSource{candleRepo: candleRepo}
+}
+
+// DatabaseCandleSource candles of the local store, they are downloaded by cmd/downloadKlines
+type DatabaseCandleSource struct {
+	candleRepo repository.Candle
+}
+
+func (s *DatabaseCandleSource) GetCandles(coin *domain.Coin, interval string, from time.Time, to time.Time) ([]domain.Candle, error) {
+	return s.candleRepo.FindByPeriod(coin.Id, interval, from, to)
+}
+
+func NewExchangeCandleSource(candleDownloadService *CandleDownloadService) *ExchangeCandleSource {
+	return &ExchangeCandleSource{candleDownloadService: candleDownloadService}
+}
+
+// ExchangeCandleSource downloads candles from the exchange on every run without storing them
+type ExchangeCandleSource struct {
+	candleDownloadService *CandleDownloadService
+}
+
+func (s *ExchangeCandleSource) GetCandles(coin *domain.Coin, interval string, from time.Time, to time.Time) ([]domain.Candle, error) {
+	return s.candleDownloadService.FetchCandles(coin, interval, from, to)
+}
+
+func NewStoreCandleSource(candleRepo repository.Candle, candleDownloadService *CandleDownloadService) *StoreCandleSource {
+	return &StoreCandleSource{candleRepo: candleRepo, candleDownloadService: candleDownloadService}
+}
+
+// StoreCandleSource candles of the local store, the candles after the latest stored one are downloaded from the exchange
+// without storing them. Gaps inside the stored period are not filled.
+type StoreCandleSource struct {
+	candleRepo            repository.Candle
+	candleDownloadService *CandleDownloadService
+}
+
+func (s *StoreCandleSource) GetCandles(coin *domain.Coin, interval string, from time.Time, to time.Time) ([]domain.Candle, error) {
+	intervalDuration, err := util.ParseKlineInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.candleRepo.FindByPeriod(coin.Id, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchFrom := from
+	if len(stored) > 0 {
+		fetchFrom = stored[len(stored)-1].StartAt.Add(intervalDuration)
+	}
+	if !fetchFrom.Before(to) {
+		return stored, nil
+	}
+	fetched, err := s.candleDownloadService.FetchCandles(coin, interval, fetchFrom, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(stored, fetched...), nil
+}
@@ -0,0 +1,154 @@
+package coins
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/domain"
+	coinDto "tradingViewWebhookBot/internal/dto/coin"
+	"tradingViewWebhookBot/internal/repository"
+)
+
+var (
+	ErrNotFound          = errors.New("coin not found")
+	ErrAlreadyExists     = errors.New("coin already exists")
+	ErrNotTradable       = errors.New("symbol is not tradable on the exchange")
+	ErrHasTransactions   = errors.New("coin has transactions")
+	ErrInstrumentRefresh = errors.New("instrument info is not available")
+)
+
+func NewCoinService(coinRepo repository.Coin, transactionRepo repository.Transaction, exchangeApi api.ExchangeApi) *CoinService {
+	return &CoinService{
+		coinRepo:        coinRepo,
+		transactionRepo: transactionRepo,
+		exchangeApi:     exchangeApi,
+	}
+}
+
+// CoinService coins are validated against the exchange instrument list, its precision and limits are stored with the coin
+type CoinService struct {
+	coinRepo        repository.Coin
+	transactionRepo repository.Transaction
+	exchangeApi     api.ExchangeApi
+}
+
+func (s *CoinService) List() ([]domain.Coin, error) {
+	return s.coinRepo.FindAll()
+}
+
+func (s *CoinService) Add(request coinDto.CoinRequestDto) (*domain.Coin, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(request.Symbol))
+	if existing, err := s.coinRepo.FindBySymbol(symbol); err == nil && existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyExists, symbol)
+	}
+
+	instrument, err := s.exchangeApi.GetInstrumentInfo(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrNotTradable, symbol, err.Error())
+	}
+	if !instrument.IsTrading() {
+		return nil, fmt.Errorf("%w: %s", ErrNotTradable, symbol)
+	}
+
+	coin := &domain.Coin{
+		Name:    request.Name,
+		Symbol:  symbol,
+		Enabled: true,
+	}
+	if coin.Name == "" {
+		coin.Name = instrument.GetBaseCoin()
+	}
+	setInstrumentInfo(coin, instrument)
+
+	if err := s.coinRepo.Create(coin); err != nil {
+		return nil, err
+	}
+	return coin, nil
+}
+
+// RefreshInstrumentInfo updates precision and limits, exchanges change them from time to time
+func (s *CoinService) RefreshInstrumentInfo(id int64) (*domain.Coin, error) {
+	coin, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	instrument, err := s.exchangeApi.GetInstrumentInfo(coin.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInstrumentRefresh, coin.Symbol, err.Error())
+	}
+	setInstrumentInfo(coin, instrument)
+
+	if err := s.coinRepo.Update(coin); err != nil {
+		return nil, err
+	}
+	return coin, nil
+}
+
+// BackfillInstrumentInfo refreshes coins added before the instrument info was stored, their qty step is unknown (zero).
+// Coins which failed are returned, they keep the 3 decimals formatting of the qty.
+func (s *CoinService) BackfillInstrumentInfo() ([]string, error) {
+	coins, err := s.coinRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []string
+	for i := range coins {
+		if coins[i].QtyStep > 0 {
+			continue
+		}
+		if _, err := s.RefreshInstrumentInfo(coins[i].Id); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", coins[i].Symbol, err.Error()))
+		}
+	}
+	return failed, nil
+}
+
+func (s *CoinService) SetEnabled(id int64, enabled bool) (*domain.Coin, error) {
+	coin, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	coin.Enabled = enabled
+	if err := s.coinRepo.Update(coin); err != nil {
+		return nil, err
+	}
+	return coin, nil
+}
+
+// Remove only coins never traded can be removed, traded ones are referenced by transactions and have to be disabled
+func (s *CoinService) Remove(id int64) error {
+	coin, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	hasTransactions, err := s.transactionRepo.ExistsByCoin(coin.Id)
+	if err != nil {
+		return err
+	}
+	if hasTransactions {
+		return fmt.Errorf("%w: %s, disable it instead", ErrHasTransactions, coin.Symbol)
+	}
+
+	return s.coinRepo.Delete(coin.Id)
+}
+
+func (s *CoinService) Get(id int64) (*domain.Coin, error) {
+	coin, err := s.coinRepo.FindById(id)
+	if err != nil || coin == nil {
+		return nil, ErrNotFound
+	}
+	return coin, nil
+}
+
+func setInstrumentInfo(coin *domain.Coin, instrument api.InstrumentInfoDto) {
+	coin.TickSize = instrument.GetTickSize()
+	coin.QtyStep = instrument.GetQtyStep()
+	coin.MinOrderQty = instrument.GetMinOrderQty()
+	coin.MaxOrderQty = instrument.GetMaxOrderQty()
+	coin.MinNotional = instrument.GetMinNotional()
+}
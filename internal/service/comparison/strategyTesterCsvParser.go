@@ -0,0 +1,165 @@
+package comparison
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/dto/tradingview"
+)
+
+// strategyTesterDateFormats date formats of the export, seconds are present in newer exports only
+var strategyTesterDateFormats = []string{"2006-01-02 15:04", "2006-01-02 15:04:05"}
+
+// openTradeSignal exit signal of the trade which is still opened at the end of the chart
+const openTradeSignal = "open"
+
+// strategyTesterColumns indexes of the used columns, -1 if the column is absent
+type strategyTesterColumns struct {
+	tradeNumber, tradeType, signal, dateTime, price, contracts, profit, profitPercent int
+}
+
+// ParseStrategyTesterTrades reads the "List of trades" CSV export of the TradingView strategy tester.
+// Every trade has an entry and an exit row with the same trade number. Times of the export are in the chart timezone,
+// location must be the same. Trades which are still opened are skipped. Result is ordered by entry time.
+func ParseStrategyTesterTrades(reader io.Reader, location *time.Location) ([]tradingview.StrategyTesterTradeDto, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error during read of header: %w", err)
+	}
+	columns, err := findStrategyTesterColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make(map[int]*tradingview.StrategyTesterTradeDto)
+	for line := 2; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := parseStrategyTesterRow(record, columns, location, trades); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	result := make([]tradingview.StrategyTesterTradeDto, 0, len(trades))
+	for _, trade := range trades {
+		if trade.EntryTime.IsZero() || trade.ExitTime.IsZero() || strings.EqualFold(trade.ExitSignal, openTradeSignal) {
+			continue
+		}
+		result = append(result, *trade)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].EntryTime.Before(result[j].EntryTime)
+	})
+	return result, nil
+}
+
+func findStrategyTesterColumns(header []string) (strategyTesterColumns, error) {
+	columns := strategyTesterColumns{-1, -1, -1, -1, -1, -1, -1, -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch {
+		case strings.HasPrefix(name, "trade #"):
+			columns.tradeNumber = i
+		case name == "type":
+			columns.tradeType = i
+		case name == "signal":
+			columns.signal = i
+		case strings.HasPrefix(name, "date"):
+			columns.dateTime = i
+		case strings.HasPrefix(name, "price"):
+			columns.price = i
+		case strings.HasPrefix(name, "contracts"), strings.HasPrefix(name, "quantity"), strings.HasPrefix(name, "position size (qty)"):
+			columns.contracts = i
+		case strings.HasPrefix(name, "profit") || strings.HasPrefix(name, "net p&l"):
+			if strings.Contains(name, "%") {
+				columns.profitPercent = i
+			} else {
+				columns.profit = i
+			}
+		}
+	}
+
+	if columns.tradeNumber < 0 || columns.tradeType < 0 || columns.dateTime < 0 || columns.price < 0 {
+		return columns, fmt.Errorf("not a strategy tester list of trades: Trade #, Type, Date/Time and Price columns are required")
+	}
+	return columns, nil
+}
+
+func parseStrategyTesterRow(record []string, columns strategyTesterColumns, location *time.Location, trades map[int]*tradingview.StrategyTesterTradeDto) error {
+	tradeNumber, err := strconv.Atoi(getColumn(record, columns.tradeNumber))
+	if err != nil {
+		return fmt.Errorf("invalid trade number: %w", err)
+	}
+	tradeType := strings.ToLower(getColumn(record, columns.tradeType))
+	moment, err := parseStrategyTesterDate(getColumn(record, columns.dateTime), location)
+	if err != nil {
+		return err
+	}
+	price, err := parseStrategyTesterNumber(getColumn(record, columns.price))
+	if err != nil {
+		return fmt.Errorf("invalid price: %w", err)
+	}
+
+	trade, ok := trades[tradeNumber]
+	if !ok {
+		trade = &tradingview.StrategyTesterTradeDto{TradeNumber: tradeNumber}
+		trades[tradeNumber] = trade
+	}
+	trade.FuturesType = futureType.GetTypeByBool(!strings.HasSuffix(tradeType, "short"))
+
+	switch {
+	case strings.HasPrefix(tradeType, "entry"):
+		trade.EntryTime = moment
+		trade.EntryPrice = price
+		trade.EntrySignal = getColumn(record, columns.signal)
+	case strings.HasPrefix(tradeType, "exit"):
+		trade.ExitTime = moment
+		trade.ExitPrice = price
+		trade.ExitSignal = getColumn(record, columns.signal)
+		// contracts and profit are the same in both rows, newer exports have them in the exit row only
+		trade.Contracts, _ = parseStrategyTesterNumber(getColumn(record, columns.contracts))
+		trade.Profit, _ = parseStrategyTesterNumber(getColumn(record, columns.profit))
+		trade.ProfitPercent, _ = parseStrategyTesterNumber(getColumn(record, columns.profitPercent))
+	default:
+		return fmt.Errorf("unknown trade type %q", tradeType)
+	}
+	return nil
+}
+
+func getColumn(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func parseStrategyTesterDate(value string, location *time.Location) (time.Time, error) {
+	for _, format := range strategyTesterDateFormats {
+		if parsed, err := time.ParseInLocation(format, value, location); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// parseStrategyTesterNumber numbers may contain the unicode minus, thousands separators and the percent sign
+func parseStrategyTesterNumber(value string) (float64, error) {
+	value = strings.NewReplacer("−", "-", ",", "", "%", "", " ", "").Replace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
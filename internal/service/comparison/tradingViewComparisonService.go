@@ -0,0 +1,172 @@
+package comparison
+
+import (
+	"math"
+	"time"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/tradingview"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/util"
+)
+
+func NewTradingViewComparisonService(transactionRepo repository.Transaction) *TradingViewComparisonService {
+	return &TradingViewComparisonService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+// TradingViewComparisonService compares theoretical trades of the TradingView strategy tester with recorded round trips
+type TradingViewComparisonService struct {
+	transactionRepo repository.Transaction
+}
+
+// Compare round trips of the filter opened within the period of the trades are matched to the trades of the same side
+// by entry time: the closest round trip opened not farther than window from the TradingView entry.
+// Partially closed positions are several round trips, only one of them is matched.
+func (s *TradingViewComparisonService) Compare(trades []tradingview.StrategyTesterTradeDto, filter transaction.RoundTripFilter, window time.Duration) (*tradingview.TradeComparisonDto, error) {
+	result := &tradingview.TradeComparisonDto{
+		TradingViewTradesCount: len(trades),
+		Matches:                make([]tradingview.TradeMatchDto, 0),
+		MissedTrades:           make([]tradingview.StrategyTesterTradeDto, 0),
+		ExtraTrades:            make([]transaction.RoundTripDto, 0),
+	}
+	if len(trades) == 0 {
+		return result, nil
+	}
+
+	result.From = trades[0].EntryTime.Add(-window)
+	for _, trade := range trades {
+		result.To = maxTime(result.To, trade.ExitTime.Add(window))
+	}
+
+	// filter of round trips is by close time, positions opened in the period and closed after it are found too
+	filter.From = &result.From
+	filter.To = nil
+	roundTrips, err := s.findOpenedInPeriod(filter, result.From, result.To)
+	if err != nil {
+		return nil, err
+	}
+	result.RoundTripsCount = len(roundTrips)
+
+	matched := make([]bool, len(roundTrips))
+	var sumOfEntrySlippage, sumOfExitSlippage float64
+	for _, trade := range trades {
+		result.TradingViewProfit += trade.Profit
+		result.TradingViewProfitPercent += trade.ProfitPercent
+
+		index := findClosestRoundTrip(trade, roundTrips, matched, window)
+		if index < 0 {
+			result.MissedTrades = append(result.MissedTrades, trade)
+			continue
+		}
+		matched[index] = true
+
+		match := newTradeMatch(trade, roundTrips[index])
+		sumOfEntrySlippage += match.EntrySlippage
+		sumOfExitSlippage += match.ExitSlippage
+		result.Matches = append(result.Matches, match)
+	}
+
+	for i, roundTrip := range roundTrips {
+		result.Profit += util.GetDollarsByCents(roundTrip.Profit)
+		result.ProfitPercent += roundTrip.PercentProfit
+		if !matched[i] {
+			result.ExtraTrades = append(result.ExtraTrades, roundTrip)
+		}
+	}
+
+	result.MatchedCount = len(result.Matches)
+	if result.MatchedCount > 0 {
+		result.AverageEntrySlippage = round(sumOfEntrySlippage / float64(result.MatchedCount))
+		result.AverageExitSlippage = round(sumOfExitSlippage / float64(result.MatchedCount))
+	}
+	result.TradingViewProfit = round(result.TradingViewProfit)
+	result.Profit = round(result.Profit)
+	result.TradingViewProfitPercent = round(result.TradingViewProfitPercent)
+	result.ProfitPercent = round(result.ProfitPercent)
+	result.ProfitPercentGap = round(result.ProfitPercent - result.TradingViewProfitPercent)
+	return result, nil
+}
+
+func (s *TradingViewComparisonService) findOpenedInPeriod(filter transaction.RoundTripFilter, from time.Time, to time.Time) ([]transaction.RoundTripDto, error) {
+	roundTrips, err := s.transactionRepo.FindRoundTrips(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]transaction.RoundTripDto, 0, len(roundTrips))
+	for _, roundTrip := range roundTrips {
+		if !roundTrip.OpenedAt.Before(from) && roundTrip.OpenedAt.Before(to) {
+			result = append(result, roundTrip)
+		}
+	}
+	return result, nil
+}
+
+func findClosestRoundTrip(trade tradingview.StrategyTesterTradeDto, roundTrips []transaction.RoundTripDto, matched []bool, window time.Duration) int {
+	closest := -1
+	var closestDistance time.Duration
+	for i, roundTrip := range roundTrips {
+		if matched[i] || roundTrip.FuturesType != trade.FuturesType {
+			continue
+		}
+		distance := absDuration(roundTrip.OpenedAt.Sub(trade.EntryTime))
+		if distance > window {
+			continue
+		}
+		if closest < 0 || distance < closestDistance {
+			closest, closestDistance = i, distance
+		}
+	}
+	return closest
+}
+
+func newTradeMatch(trade tradingview.StrategyTesterTradeDto, roundTrip transaction.RoundTripDto) tradingview.TradeMatchDto {
+	sign := futureType.GetFuturesSignFloat64(trade.FuturesType)
+	match := tradingview.TradeMatchDto{
+		TradeNumber:              trade.TradeNumber,
+		Side:                     futureType.GetString(trade.FuturesType),
+		OpenTransactionId:        roundTrip.OpenTransactionId,
+		CloseTransactionId:       roundTrip.CloseTransactionId,
+		TradingViewEntryAt:       trade.EntryTime,
+		EntryAt:                  roundTrip.OpenedAt,
+		TradingViewExitAt:        trade.ExitTime,
+		ExitAt:                   roundTrip.ClosedAt,
+		EntryDelay:               int64(roundTrip.OpenedAt.Sub(trade.EntryTime) / time.Second),
+		ExitDelay:                int64(roundTrip.ClosedAt.Sub(trade.ExitTime) / time.Second),
+		TradingViewEntryPrice:    trade.EntryPrice,
+		EntryPrice:               roundTrip.OpenPrice,
+		TradingViewExitPrice:     trade.ExitPrice,
+		ExitPrice:                roundTrip.ClosePrice,
+		TradingViewProfitPercent: trade.ProfitPercent,
+		ProfitPercent:            roundTrip.PercentProfit,
+		ProfitPercentGap:         round(roundTrip.PercentProfit - trade.ProfitPercent),
+	}
+	// buying higher or selling lower than TradingView is a positive slippage
+	if trade.EntryPrice != 0 {
+		match.EntrySlippage = round((roundTrip.OpenPrice - trade.EntryPrice) / trade.EntryPrice * 100 * sign)
+	}
+	if trade.ExitPrice != 0 {
+		match.ExitSlippage = round((trade.ExitPrice - roundTrip.ClosePrice) / trade.ExitPrice * 100 * sign)
+	}
+	return match
+}
+
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+func absDuration(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+	return duration
+}
+
+func maxTime(first time.Time, second time.Time) time.Time {
+	if first.After(second) {
+		return first
+	}
+	return second
+}
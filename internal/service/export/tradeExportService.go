@@ -0,0 +1,228 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/constants/exportFormat"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/util"
+)
+
+// quoteCurrencies known settlement currencies of symbols, longest first
+var quoteCurrencies = []string{"USDT", "USDC", "USD"}
+
+const (
+	koinlyDateFormat       = "2006-01-02 15:04 UTC"
+	coinTrackingDateFormat = "2006-01-02 15:04:05"
+	coinTrackingExchange   = "Bybit"
+)
+
+func NewTradeExportService(transactionRepo repository.Transaction) *TradeExportService {
+	return &TradeExportService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+// TradeExportService writes closed round trips (open transaction joined with its close by RelatedTransactionId)
+type TradeExportService struct {
+	transactionRepo repository.Transaction
+}
+
+// TradeExportRowDto one closed round trip, money values are in USD
+type TradeExportRowDto struct {
+	Strategy    string    `json:"strategy"`
+	CoinSymbol  string    `json:"coin_symbol"`
+	Side        string    `json:"side"`
+	OpenedAt    time.Time `json:"opened_at"`
+	ClosedAt    time.Time `json:"closed_at"`
+	OpenPrice   float64   `json:"open_price"`
+	ClosePrice  float64   `json:"close_price"`
+	Qty         float64   `json:"qty"`
+	OpenFee     float64   `json:"open_fee"`
+	CloseFee    float64   `json:"close_fee"`
+	FundingFee  float64   `json:"funding_fee"`
+	GrossProfit float64   `json:"gross_profit"`
+	Profit      float64   `json:"profit"`
+	ExitReason  string    `json:"exit_reason"`
+	Fake        bool      `json:"fake"`
+}
+
+func (s *TradeExportService) Export(writer io.Writer, filter transaction.RoundTripFilter, format exportFormat.ExportFormat) (int, error) {
+	roundTrips, err := s.transactionRepo.FindRoundTrips(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make([]TradeExportRowDto, 0, len(roundTrips))
+	for _, roundTrip := range roundTrips {
+		rows = append(rows, toExportRow(roundTrip))
+	}
+
+	switch format {
+	case exportFormat.CSV:
+		err = writeCsv(writer, rows)
+	case exportFormat.JSONL:
+		err = writeJsonLines(writer, rows)
+	case exportFormat.KOINLY:
+		err = writeKoinly(writer, rows)
+	case exportFormat.COINTRACKING:
+		err = writeCoinTracking(writer, rows)
+	default:
+		err = fmt.Errorf("unknown export format: %s", format)
+	}
+	return len(rows), err
+}
+
+func toExportRow(roundTrip transaction.RoundTripDto) TradeExportRowDto {
+	return TradeExportRowDto{
+		Strategy:    roundTrip.TradingStrategyName,
+		CoinSymbol:  roundTrip.CoinSymbol,
+		Side:        futureType.GetString(roundTrip.FuturesType),
+		OpenedAt:    roundTrip.OpenedAt.UTC(),
+		ClosedAt:    roundTrip.ClosedAt.UTC(),
+		OpenPrice:   roundTrip.OpenPrice,
+		ClosePrice:  roundTrip.ClosePrice,
+		Qty:         roundTrip.Amount,
+		OpenFee:     roundTrip.OpenFee,
+		CloseFee:    roundTrip.CloseFee,
+		FundingFee:  roundTrip.FundingFee,
+		GrossProfit: util.GetDollarsByCents(roundTrip.GrossProfit),
+		Profit:      util.GetDollarsByCents(roundTrip.Profit),
+		ExitReason:  roundTrip.ExitReason.String,
+		Fake:        roundTrip.IsFake,
+	}
+}
+
+func writeCsv(writer io.Writer, rows []TradeExportRowDto) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write([]string{"strategy", "coin_symbol", "side", "opened_at", "closed_at", "open_price", "close_price", "qty",
+		"open_fee", "close_fee", "funding_fee", "gross_profit", "profit", "exit_reason", "fake"})
+	for _, row := range rows {
+		csvWriter.Write([]string{
+			row.Strategy,
+			row.CoinSymbol,
+			row.Side,
+			row.OpenedAt.Format(time.RFC3339),
+			row.ClosedAt.Format(time.RFC3339),
+			formatFloat(row.OpenPrice),
+			formatFloat(row.ClosePrice),
+			formatFloat(row.Qty),
+			formatFloat(row.OpenFee),
+			formatFloat(row.CloseFee),
+			formatFloat(row.FundingFee),
+			formatMoney(row.GrossProfit),
+			formatMoney(row.Profit),
+			row.ExitReason,
+			strconv.FormatBool(row.Fake),
+		})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func writeJsonLines(writer io.Writer, rows []TradeExportRowDto) error {
+	encoder := json.NewEncoder(writer)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeKoinly Koinly universal format, derivatives are imported as "realized gain" in the settlement currency.
+// Price difference minus funding is the gain, execution fees go to the fee column, so net worth equals realised P&L.
+func writeKoinly(writer io.Writer, rows []TradeExportRowDto) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write([]string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash"})
+	for _, row := range rows {
+		quote := getQuoteCurrency(row.CoinSymbol)
+		gain := row.GrossProfit - row.FundingFee
+
+		var sentAmount, sentCurrency, receivedAmount, receivedCurrency string
+		if gain >= 0 {
+			receivedAmount, receivedCurrency = formatMoney(gain), quote
+		} else {
+			sentAmount, sentCurrency = formatMoney(-gain), quote
+		}
+
+		csvWriter.Write([]string{
+			row.ClosedAt.Format(koinlyDateFormat),
+			sentAmount,
+			sentCurrency,
+			receivedAmount,
+			receivedCurrency,
+			formatFloat(row.OpenFee + row.CloseFee),
+			quote,
+			formatMoney(row.Profit),
+			"USD",
+			"realized gain",
+			getDescription(row),
+			"",
+		})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeCoinTracking CoinTracking CSV import, round trips are "Margin Profit" or "Margin Loss" with fees in the fee column
+func writeCoinTracking(writer io.Writer, rows []TradeExportRowDto) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write([]string{"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency", "Fee", "Fee Currency",
+		"Exchange", "Trade-Group", "Comment", "Date"})
+	for _, row := range rows {
+		quote := getQuoteCurrency(row.CoinSymbol)
+		gain := row.GrossProfit - row.FundingFee
+
+		txType, buyAmount, buyCurrency, sellAmount, sellCurrency := "Margin Profit", formatMoney(gain), quote, "", ""
+		if gain < 0 {
+			txType, buyAmount, buyCurrency, sellAmount, sellCurrency = "Margin Loss", "", "", formatMoney(-gain), quote
+		}
+
+		csvWriter.Write([]string{
+			txType,
+			buyAmount,
+			buyCurrency,
+			sellAmount,
+			sellCurrency,
+			formatFloat(row.OpenFee + row.CloseFee),
+			quote,
+			coinTrackingExchange,
+			row.Strategy,
+			getDescription(row),
+			row.ClosedAt.Format(coinTrackingDateFormat),
+		})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func getDescription(row TradeExportRowDto) string {
+	return fmt.Sprintf("%s %s %s qty %s @ %s -> %s", row.Strategy, row.Side, row.CoinSymbol,
+		formatFloat(row.Qty), formatFloat(row.OpenPrice), formatFloat(row.ClosePrice))
+}
+
+func getQuoteCurrency(symbol string) string {
+	for _, quote := range quoteCurrencies {
+		if strings.HasSuffix(symbol, quote) {
+			return quote
+		}
+	}
+	return "USDT"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatMoney(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
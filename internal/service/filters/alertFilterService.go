@@ -0,0 +1,417 @@
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants/filterType"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/filter"
+	"tradingViewWebhookBot/internal/indicator"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/candles"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// warmupPeriods history of a candle based filter in its periods, the EMA and the Wilder's average of the ATR
+// depend on all previous candles, so the values match the chart only after several periods
+const warmupPeriods = 5
+
+var errTickerNotAvailable = errors.New("ticker is not available")
+
+// ParseAlertFilters filters of the strategy, empty value has no filters
+func ParseAlertFilters(alertFilters string) ([]filter.AlertFilterDto, error) {
+	var result []filter.AlertFilterDto
+	if alertFilters == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(alertFilters), &result); err != nil {
+		return nil, fmt.Errorf("invalid alert filters: %w", err)
+	}
+	return result, nil
+}
+
+// ValidateAlertFilters the fields required by the type of the filter, common fields are validated by tags
+func ValidateAlertFilters(alertFilters []filter.AlertFilterDto) error {
+	for i, alertFilter := range alertFilters {
+		switch {
+		case filterType.IsCandleBased(alertFilter.Type):
+			if _, err := util.ParseKlineInterval(alertFilter.Interval); err != nil {
+				return fmt.Errorf("filter %d %s: %w", i+1, alertFilter.Type, err)
+			}
+			if alertFilter.Period <= 0 {
+				return fmt.Errorf("filter %d %s: period must be positive", i+1, alertFilter.Type)
+			}
+			if alertFilter.Max > 0 && alertFilter.Min > alertFilter.Max {
+				return fmt.Errorf("filter %d %s: min must not be greater than max", i+1, alertFilter.Type)
+			}
+		case alertFilter.Max <= 0:
+			return fmt.Errorf("filter %d %s: max must be positive", i+1, alertFilter.Type)
+		}
+	}
+	return nil
+}
+
+// NewAlertFilterService tickerApi may be nil, e.g. in the backtest, then spread and funding filters use the values recorded
+// on the alert when it was received live and can't be checked for alerts without them
+func NewAlertFilterService(
+	alertRepo repository.Alert,
+	candleSource candles.CandleSource,
+	exchangeApi api.ExchangeApi,
+	tickerApi api.TickerApi,
+	clock date.Clock,
+) *AlertFilterService {
+	return &AlertFilterService{
+		alertRepo:    alertRepo,
+		candleSource: candleSource,
+		exchangeApi:  exchangeApi,
+		tickerApi:    tickerApi,
+		clock:        clock,
+		indicators:   make(map[indicatorKey]*cachedIndicator),
+	}
+}
+
+// AlertFilterService checks the filter chain of the strategy before an alert opens a position.
+// Indicators of candle based filters are kept between alerts and updated with the candles closed since the last check.
+type AlertFilterService struct {
+	alertRepo    repository.Alert
+	candleSource candles.CandleSource
+	exchangeApi  api.ExchangeApi
+	tickerApi    api.TickerApi
+	clock        date.Clock
+
+	mu         sync.Mutex
+	indicators map[indicatorKey]*cachedIndicator
+}
+
+type indicatorKey struct {
+	coinId     int64
+	filterType filterType.FilterType
+	interval   string
+	period     int
+}
+
+type cachedIndicator struct {
+	indicator   indicator.Indicator
+	nextStartAt time.Time
+}
+
+// Check evaluates every filter of the strategy for the alert opening a futuresType position and records the verdicts
+// on the alert. Passed is false when any filter rejects the alert or the filters of the strategy are not valid.
+func (s *AlertFilterService) Check(strategy *domain.TradingStrategy, coin *domain.Coin, futuresType futureType.FuturesType,
+	alertPrice float64, alert *domain.Alert) ([]filter.FilterVerdictDto, bool) {
+	alertFilters, err := ParseAlertFilters(strategy.AlertFilters)
+	if err != nil {
+		verdicts := []filter.FilterVerdictDto{{Error: err.Error()}}
+		s.recordVerdicts(alert, verdicts)
+		return verdicts, false
+	}
+	if len(alertFilters) == 0 {
+		return nil, true
+	}
+
+	recorded := s.getRecordedVerdicts(alert)
+	passed := true
+	verdicts := make([]filter.FilterVerdictDto, 0, len(alertFilters))
+	for _, alertFilter := range alertFilters {
+		verdict := s.evaluate(alertFilter, coin, futuresType, alertPrice, recorded)
+		passed = passed && verdict.Passed
+		verdicts = append(verdicts, verdict)
+	}
+	s.recordVerdicts(alert, verdicts)
+	return verdicts, passed
+}
+
+func (s *AlertFilterService) evaluate(alertFilter filter.AlertFilterDto, coin *domain.Coin, futuresType futureType.FuturesType, alertPrice float64,
+	recorded []filter.FilterVerdictDto) filter.FilterVerdictDto {
+	verdict := filter.FilterVerdictDto{Type: alertFilter.Type}
+
+	var err error
+	switch alertFilter.Type {
+	case filterType.EMA_TREND:
+		verdict.Value, verdict.Passed, verdict.Reason, err = s.checkEmaTrend(alertFilter, coin, futuresType)
+	case filterType.ATR_PERCENT:
+		verdict.Value, verdict.Passed, verdict.Reason, err = s.checkAtrPercent(alertFilter, coin)
+	case filterType.SPREAD:
+		verdict.Value, verdict.Passed, verdict.Reason, err = s.checkSpread(alertFilter, coin, recorded)
+	case filterType.FUNDING_RATE:
+		verdict.Value, verdict.Passed, verdict.Reason, err = s.checkFundingRate(alertFilter, coin, recorded)
+	case filterType.PRICE_DEVIATION:
+		verdict.Value, verdict.Passed, verdict.Reason, err = s.checkPriceDeviation(alertFilter, coin, alertPrice)
+	default:
+		err = fmt.Errorf("unknown filter type %q", alertFilter.Type)
+	}
+
+	if err != nil {
+		verdict.Value = 0
+		verdict.Passed = alertFilter.FailOpen
+		verdict.Reason = ""
+		verdict.Error = err.Error()
+	}
+	verdict.Value = math.Round(verdict.Value*10000) / 10000
+	return verdict
+}
+
+// checkEmaTrend value is the distance of the price from the EMA in percents
+func (s *AlertFilterService) checkEmaTrend(alertFilter filter.AlertFilterDto, coin *domain.Coin, futuresType futureType.FuturesType) (float64, bool, string, error) {
+	emaValue, err := s.getIndicatorValue(alertFilter, coin, func(ema indicator.Indicator) float64 {
+		return ema.(*indicator.Ema).Value()
+	})
+	if err != nil {
+		return 0, false, "", err
+	}
+	price, err := s.exchangeApi.GetCurrentCoinPrice(coin)
+	if err != nil {
+		return 0, false, "", err
+	}
+
+	value := util.CalculateChangeInPercents(emaValue, price)
+	if futuresType == futureType.LONG && price < emaValue {
+		return value, false, fmt.Sprintf("price %.4f is below EMA %d of %s %.4f", price, alertFilter.Period, alertFilter.Interval, emaValue), nil
+	}
+	if futuresType == futureType.SHORT && price > emaValue {
+		return value, false, fmt.Sprintf("price %.4f is above EMA %d of %s %.4f", price, alertFilter.Period, alertFilter.Interval, emaValue), nil
+	}
+	return value, true, "", nil
+}
+
+func (s *AlertFilterService) checkAtrPercent(alertFilter filter.AlertFilterDto, coin *domain.Coin) (float64, bool, string, error) {
+	value, err := s.getIndicatorValue(alertFilter, coin, func(atr indicator.Indicator) float64 {
+		return atr.(*indicator.Atr).Percent()
+	})
+	if err != nil {
+		return 0, false, "", err
+	}
+
+	if value < alertFilter.Min {
+		return value, false, fmt.Sprintf("ATR %.4f%% is below %.4f%%", value, alertFilter.Min), nil
+	}
+	if alertFilter.Max > 0 && value > alertFilter.Max {
+		return value, false, fmt.Sprintf("ATR %.4f%% is above %.4f%%", value, alertFilter.Max), nil
+	}
+	return value, true, "", nil
+}
+
+func (s *AlertFilterService) checkSpread(alertFilter filter.AlertFilterDto, coin *domain.Coin, recorded []filter.FilterVerdictDto) (float64, bool, string, error) {
+	value, err := s.getSpread(coin, recorded)
+	if err != nil {
+		return 0, false, "", err
+	}
+
+	if value > alertFilter.Max {
+		return value, false, fmt.Sprintf("spread %.4f%% is above %.4f%%", value, alertFilter.Max), nil
+	}
+	return value, true, "", nil
+}
+
+// checkFundingRate value is the funding rate in percents
+func (s *AlertFilterService) checkFundingRate(alertFilter filter.AlertFilterDto, coin *domain.Coin, recorded []filter.FilterVerdictDto) (float64, bool, string, error) {
+	value, err := s.getFundingRate(coin, recorded)
+	if err != nil {
+		return 0, false, "", err
+	}
+
+	if math.Abs(value) > alertFilter.Max {
+		return value, false, fmt.Sprintf("funding rate %.4f%% is beyond %.4f%%", value, alertFilter.Max), nil
+	}
+	return value, true, "", nil
+}
+
+func (s *AlertFilterService) checkPriceDeviation(alertFilter filter.AlertFilterDto, coin *domain.Coin, alertPrice float64) (float64, bool, string, error) {
+	if alertPrice <= 0 {
+		return 0, false, "", errors.New("alert has no price")
+	}
+	price, err := s.exchangeApi.GetCurrentCoinPrice(coin)
+	if err != nil {
+		return 0, false, "", err
+	}
+
+	value := util.CalculateChangeInPercentsAbs(price, alertPrice)
+	if value > alertFilter.Max {
+		return value, false, fmt.Sprintf("alert price %.4f deviates %.4f%% from %.4f", alertPrice, value, price), nil
+	}
+	return value, true, "", nil
+}
+
+// getSpread bid-ask spread in percents of the mid price
+func (s *AlertFilterService) getSpread(coin *domain.Coin, recorded []filter.FilterVerdictDto) (float64, error) {
+	if s.tickerApi == nil {
+		return getRecordedValue(recorded, filterType.SPREAD)
+	}
+	ticker, err := s.tickerApi.GetTicker(coin)
+	if err != nil {
+		return 0, err
+	}
+	bid, ask := ticker.GetBidPrice(), ticker.GetAskPrice()
+	if bid <= 0 || ask <= 0 {
+		return 0, fmt.Errorf("no bid or ask of %s", coin.Symbol)
+	}
+	return (ask - bid) / ((ask + bid) / 2) * 100, nil
+}
+
+func (s *AlertFilterService) getFundingRate(coin *domain.Coin, recorded []filter.FilterVerdictDto) (float64, error) {
+	if s.tickerApi == nil {
+		return getRecordedValue(recorded, filterType.FUNDING_RATE)
+	}
+	ticker, err := s.tickerApi.GetTicker(coin)
+	if err != nil {
+		return 0, err
+	}
+	return ticker.GetFundingRate() * 100, nil
+}
+
+// getRecordedVerdicts verdicts recorded on the alert when it was received live, they are used without the ticker only
+func (s *AlertFilterService) getRecordedVerdicts(alert *domain.Alert) []filter.FilterVerdictDto {
+	if s.tickerApi != nil || alert == nil || alert.FilterVerdicts == "" {
+		return nil
+	}
+
+	var result []filter.FilterVerdictDto
+	if err := json.Unmarshal([]byte(alert.FilterVerdicts), &result); err != nil {
+		zap.S().Errorf("Error during unmarshal of filter verdicts of alert %d: %s", alert.Id, err.Error())
+		return nil
+	}
+	return result
+}
+
+// getRecordedValue value measured live by the first filter of the type, the ticker of the past is not available.
+// The value doesn't depend on the bounds of the filter, so it is compared with the current ones.
+func getRecordedValue(recorded []filter.FilterVerdictDto, recordedType filterType.FilterType) (float64, error) {
+	for _, verdict := range recorded {
+		if verdict.Type != recordedType {
+			continue
+		}
+		if verdict.Error != "" {
+			return 0, errors.New(verdict.Error)
+		}
+		return verdict.Value, nil
+	}
+	return 0, errTickerNotAvailable
+}
+
+// WarmUp loads the history of the candle based filters of enabled strategies for the enabled coins they allow,
+// so the first alerts don't wait for the download. Errors are logged only, the history is loaded again by the check.
+func (s *AlertFilterService) WarmUp(strategies []domain.TradingStrategy, coins []domain.Coin) {
+	for _, strategy := range strategies {
+		if !strategy.Enabled {
+			continue
+		}
+		alertFilters, err := ParseAlertFilters(strategy.AlertFilters)
+		if err != nil {
+			zap.S().Errorf("Error during warm up of filters of strategy %d: %s", strategy.Id, err.Error())
+			continue
+		}
+		for _, alertFilter := range alertFilters {
+			if !filterType.IsCandleBased(alertFilter.Type) {
+				continue
+			}
+			for i := range coins {
+				if !coins[i].Enabled || !strategy.IsSymbolAllowed(coins[i].Symbol) {
+					continue
+				}
+				if err := s.updateIndicator(alertFilter, &coins[i]); err != nil {
+					zap.S().Warnf("Error during warm up of %s %d filter of %s: %s", alertFilter.Type, alertFilter.Period, coins[i].Symbol, err.Error())
+				}
+			}
+		}
+	}
+}
+
+// getIndicatorValue value of the indicator of the filter updated with the candles closed before now,
+// the value is read under the lock as the indicator may be updated by a concurrent check
+func (s *AlertFilterService) getIndicatorValue(alertFilter filter.AlertFilterDto, coin *domain.Coin, value func(indicator.Indicator) float64) (float64, error) {
+	if err := s.updateIndicator(alertFilter, coin); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached := s.indicators[newIndicatorKey(alertFilter, coin)]
+	if !cached.indicator.IsReady() {
+		return 0, fmt.Errorf("not enough %s candles of %s for %s %d", alertFilter.Interval, coin.Symbol, alertFilter.Type, alertFilter.Period)
+	}
+	return value(cached.indicator), nil
+}
+
+// updateIndicator updates the indicator of the filter with the candles closed before now, the history is loaded on the first call.
+// Candles are fetched without the lock, so a slow download doesn't hold the checks of other coins. Concurrent checks may fetch
+// the same candles, the indicator replaces a kline with the same start and ignores older ones.
+func (s *AlertFilterService) updateIndicator(alertFilter filter.AlertFilterDto, coin *domain.Coin) error {
+	intervalDuration, err := util.ParseKlineInterval(alertFilter.Interval)
+	if err != nil {
+		return err
+	}
+
+	key := newIndicatorKey(alertFilter, coin)
+	closedTo := s.clock.NowTime().Truncate(intervalDuration)
+	s.mu.Lock()
+	cached, ok := s.indicators[key]
+	if !ok {
+		cached = &cachedIndicator{
+			indicator:   newIndicator(alertFilter),
+			nextStartAt: closedTo.Add(-intervalDuration * time.Duration(alertFilter.Period*warmupPeriods)),
+		}
+		s.indicators[key] = cached
+	}
+	fetchFrom := cached.nextStartAt
+	s.mu.Unlock()
+
+	if !fetchFrom.Before(closedTo) {
+		return nil
+	}
+	candles, err := s.candleSource.GetCandles(coin, alertFilter.Interval, fetchFrom, closedTo)
+	if err != nil {
+		return fmt.Errorf("error during get of %s candles of %s: %w", alertFilter.Interval, coin.Symbol, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, kline := range indicator.FromCandles(candles) {
+		cached.indicator.Update(kline)
+	}
+	// the last candle may be not available yet, so it is requested again by the next check
+	if len(candles) > 0 {
+		nextStartAt := candles[len(candles)-1].StartAt.Add(intervalDuration)
+		if nextStartAt.After(cached.nextStartAt) {
+			cached.nextStartAt = nextStartAt
+		}
+	}
+	return nil
+}
+
+func newIndicatorKey(alertFilter filter.AlertFilterDto, coin *domain.Coin) indicatorKey {
+	return indicatorKey{coinId: coin.Id, filterType: alertFilter.Type, interval: alertFilter.Interval, period: alertFilter.Period}
+}
+
+func newIndicator(alertFilter filter.AlertFilterDto) indicator.Indicator {
+	if alertFilter.Type == filterType.ATR_PERCENT {
+		return indicator.NewAtr(alertFilter.Period)
+	}
+	return indicator.NewEma(alertFilter.Period)
+}
+
+// recordVerdicts the alert is processed anyway when the verdicts are not saved
+func (s *AlertFilterService) recordVerdicts(alert *domain.Alert, verdicts []filter.FilterVerdictDto) {
+	if alert == nil || alert.Id == 0 {
+		return
+	}
+
+	filterVerdicts, err := json.Marshal(verdicts)
+	if err != nil {
+		zap.S().Errorf("Error during marshal of filter verdicts: %s", err.Error())
+		return
+	}
+	alert.FilterVerdicts = string(filterVerdicts)
+	if err := s.alertRepo.UpdateFilterVerdicts(alert.Id, alert.FilterVerdicts); err != nil {
+		zap.S().Errorf("Error during save of filter verdicts of alert %d: %s", alert.Id, err.Error())
+	}
+}
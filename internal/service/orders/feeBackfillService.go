@@ -0,0 +1,95 @@
+package orders
+
+import (
+	"database/sql"
+	"fmt"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const feeBackfillPageSize = 100
+
+func NewFeeBackfillService(transactionRepo repository.Transaction, coinRepo repository.Coin, exchangeApi api.ExchangeApi) *FeeBackfillService {
+	return &FeeBackfillService{
+		transactionRepo: transactionRepo,
+		coinRepo:        coinRepo,
+		exchangeApi:     exchangeApi,
+	}
+}
+
+// FeeBackfillService fetches real execution fees and funding of closed round trips
+// saved before the fee accounting or when the exchange did not respond, and recalculates their profit.
+type FeeBackfillService struct {
+	transactionRepo repository.Transaction
+	coinRepo        repository.Coin
+	exchangeApi     api.ExchangeApi
+}
+
+// BackfillFees returns count of updated round trips
+func (s *FeeBackfillService) BackfillFees() (int, error) {
+	updated := 0
+	lastId := int64(0)
+	for {
+		closeTransactions, err := s.transactionRepo.FindClosedTransactionsWithoutFees(lastId, feeBackfillPageSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(closeTransactions) == 0 {
+			return updated, nil
+		}
+
+		for _, closeTransaction := range closeTransactions {
+			lastId = closeTransaction.Id
+			if err := s.backfillRoundTrip(closeTransaction); err != nil {
+				zap.S().Errorf("Fees of transaction %d are not backfilled: %s", closeTransaction.Id, err.Error())
+				continue
+			}
+			updated++
+		}
+	}
+}
+
+func (s *FeeBackfillService) backfillRoundTrip(closeTransaction *domain.Transaction) error {
+	openedTransaction, err := s.transactionRepo.FindById(closeTransaction.RelatedTransactionId.Int64)
+	if err != nil || openedTransaction == nil {
+		return fmt.Errorf("open transaction %d not found", closeTransaction.RelatedTransactionId.Int64)
+	}
+
+	coin, err := s.coinRepo.FindById(closeTransaction.CoinId)
+	if err != nil {
+		return err
+	}
+
+	if !openedTransaction.ExecFee.Valid && openedTransaction.ClientOrderId.Valid {
+		if execFee, err := s.exchangeApi.GetOrderExecFee(coin, openedTransaction.ClientOrderId.String); err == nil {
+			openedTransaction.ExecFee = sql.NullFloat64{Float64: execFee, Valid: true}
+			if err := s.transactionRepo.SaveTransaction(openedTransaction); err != nil {
+				return err
+			}
+		} else {
+			zap.S().Warnf("Exec fee of order %s is not fetched: %s", openedTransaction.ClientOrderId.String, err.Error())
+		}
+	}
+
+	if !closeTransaction.ExecFee.Valid && closeTransaction.ClientOrderId.Valid {
+		if execFee, err := s.exchangeApi.GetOrderExecFee(coin, closeTransaction.ClientOrderId.String); err == nil {
+			closeTransaction.ExecFee = sql.NullFloat64{Float64: execFee, Valid: true}
+		} else {
+			zap.S().Warnf("Exec fee of order %s is not fetched: %s", closeTransaction.ClientOrderId.String, err.Error())
+		}
+	}
+
+	if !closeTransaction.FundingFee.Valid {
+		fundingFee, err := s.exchangeApi.GetFundingFee(coin, openedTransaction.FuturesType, closeTransaction.Amount, openedTransaction.CreatedAt, closeTransaction.CreatedAt)
+		if err != nil {
+			return err
+		}
+		closeTransaction.FundingFee = sql.NullFloat64{Float64: fundingFee, Valid: true}
+	}
+
+	CalculateRealisedProfit(openedTransaction, closeTransaction)
+	return s.transactionRepo.SaveTransaction(closeTransaction)
+}
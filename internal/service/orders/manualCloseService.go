@@ -0,0 +1,211 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/exitReason"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/trade"
+	"tradingViewWebhookBot/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPositionNotFound = errors.New("opened position not found")
+	ErrStrategyNotFound = errors.New("strategy not found")
+)
+
+func NewManualCloseService(
+	strategyRepo repository.TradingStrategy,
+	transactionRepo repository.Transaction,
+	coinRepo repository.Coin,
+	exchangeApi api.ExchangeApi,
+	orderManagerService *OrderManagerService,
+) *ManualCloseService {
+	return &ManualCloseService{
+		strategyRepo:        strategyRepo,
+		transactionRepo:     transactionRepo,
+		coinRepo:            coinRepo,
+		exchangeApi:         exchangeApi,
+		orderManagerService: orderManagerService,
+	}
+}
+
+type strategyPositions struct {
+	strategy           *domain.TradingStrategy
+	openedTransactions []*domain.Transaction
+}
+
+// ManualCloseService closes positions on operator request through CloseOrder, so they are recorded as any other close
+// with the "manual" exit reason. Dry run only shows what would be closed.
+type ManualCloseService struct {
+	strategyRepo        repository.TradingStrategy
+	transactionRepo     repository.Transaction
+	coinRepo            repository.Coin
+	exchangeApi         api.ExchangeApi
+	orderManagerService *OrderManagerService
+}
+
+func (s *ManualCloseService) ClosePosition(transactionId int64, dryRun bool) (*trade.ManualCloseResultDto, error) {
+	openedTransaction, err := s.transactionRepo.FindById(transactionId)
+	if err != nil {
+		return nil, err
+	}
+	if openedTransaction == nil || openedTransaction.RelatedTransactionId.Valid || openedTransaction.Profit.Valid {
+		return nil, fmt.Errorf("%w: %d", ErrPositionNotFound, transactionId)
+	}
+
+	strategy, err := s.strategyRepo.GetByID(openedTransaction.TradingStrategyId.Int64)
+	if err != nil || strategy == nil {
+		return nil, fmt.Errorf("%w: %d", ErrStrategyNotFound, openedTransaction.TradingStrategyId.Int64)
+	}
+
+	return s.closePositions([]strategyPositions{{strategy, []*domain.Transaction{openedTransaction}}}, dryRun), nil
+}
+
+func (s *ManualCloseService) CloseStrategyPositions(strategyId int64, dryRun bool) (*trade.ManualCloseResultDto, error) {
+	strategy, err := s.strategyRepo.GetByID(strategyId)
+	if err != nil || strategy == nil {
+		return nil, fmt.Errorf("%w: %d", ErrStrategyNotFound, strategyId)
+	}
+
+	openedTransactions, err := s.transactionRepo.FindAllOpenedTransactions(*strategy)
+	if err != nil {
+		return nil, err
+	}
+	return s.closePositions([]strategyPositions{{strategy, openedTransactions}}, dryRun), nil
+}
+
+// FlattenAll closes every opened position of every strategy, disabled ones included
+func (s *ManualCloseService) FlattenAll(dryRun bool) (*trade.ManualCloseResultDto, error) {
+	strategies, err := s.strategyRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []strategyPositions
+	for i := range strategies {
+		openedTransactions, err := s.transactionRepo.FindAllOpenedTransactions(strategies[i])
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, strategyPositions{&strategies[i], openedTransactions})
+	}
+	return s.closePositions(positions, dryRun), nil
+}
+
+// CloseTransactions closes the opened positions of the transactions, e.g. the ones of a confirmed flatten preview.
+// Positions closed since the preview are skipped, positions opened since it are not closed.
+func (s *ManualCloseService) CloseTransactions(transactionIds []int64, dryRun bool) (*trade.ManualCloseResultDto, error) {
+	var positions []strategyPositions
+	var closedIds []int64
+	strategies := make(map[int64]int)
+	for _, transactionId := range transactionIds {
+		openedTransaction, err := s.transactionRepo.FindById(transactionId)
+		if err != nil {
+			return nil, err
+		}
+		if openedTransaction == nil || openedTransaction.RelatedTransactionId.Valid || openedTransaction.Profit.Valid {
+			closedIds = append(closedIds, transactionId)
+			continue
+		}
+
+		strategyId := openedTransaction.TradingStrategyId.Int64
+		index, found := strategies[strategyId]
+		if !found {
+			strategy, err := s.strategyRepo.GetByID(strategyId)
+			if err != nil || strategy == nil {
+				return nil, fmt.Errorf("%w: %d", ErrStrategyNotFound, strategyId)
+			}
+			index = len(positions)
+			strategies[strategyId] = index
+			positions = append(positions, strategyPositions{strategy: strategy})
+		}
+		positions[index].openedTransactions = append(positions[index].openedTransactions, openedTransaction)
+	}
+
+	result := s.closePositions(positions, dryRun)
+	for _, transactionId := range closedIds {
+		result.Positions = append(result.Positions, trade.ManualClosePosition{TransactionId: transactionId, Error: "already closed"})
+		result.Skipped++
+	}
+	return result, nil
+}
+
+// closePositions one failed position doesn't stop closing of the others
+func (s *ManualCloseService) closePositions(positions []strategyPositions, dryRun bool) *trade.ManualCloseResultDto {
+	result := &trade.ManualCloseResultDto{
+		DryRun:    dryRun,
+		Positions: make([]trade.ManualClosePosition, 0),
+	}
+
+	coins := make(map[int64]*domain.Coin)
+	for _, item := range positions {
+		strategy := item.strategy
+		for _, openedTransaction := range item.openedTransactions {
+			position := trade.ManualClosePosition{
+				TransactionId:     openedTransaction.Id,
+				TradingStrategyId: strategy.Id,
+				Side:              futureType.GetString(openedTransaction.FuturesType),
+				Amount:            openedTransaction.Amount,
+				OpenPrice:         openedTransaction.Price,
+			}
+
+			coin, err := s.findCoin(coins, openedTransaction.CoinId)
+			if err == nil {
+				position.CoinSymbol = coin.Symbol
+				position.CurrentPrice, err = s.exchangeApi.GetCurrentCoinPrice(coin)
+			}
+			if err != nil {
+				position.Error = err.Error()
+				result.Failed++
+				result.Positions = append(result.Positions, position)
+				continue
+			}
+			position.EstimatedProfit = (position.CurrentPrice - openedTransaction.Price) * openedTransaction.Amount *
+				futureType.GetFuturesSignFloat64(openedTransaction.FuturesType)
+
+			if !dryRun {
+				zap.S().Infof("Manual close of transaction %d of strategy %s [%s]", openedTransaction.Id, strategy.Tag, coin.Symbol)
+				closeTransaction := s.orderManagerService.CloseOrder(strategy, openedTransaction, coin, position.CurrentPrice, constants.FUTURES, exitReason.MANUAL, nil)
+				if closeTransaction == nil && s.isClosedConcurrently(openedTransaction.Id) {
+					position.Error = "already closed or being closed by another process"
+					result.Skipped++
+				} else if closeTransaction == nil {
+					position.Error = "close order failed, see logs"
+					result.Failed++
+				} else {
+					position.CloseTransactionId = closeTransaction.Id
+					result.Closed++
+				}
+			}
+			result.Positions = append(result.Positions, position)
+		}
+	}
+	return result
+}
+
+// isClosedConcurrently the close claim of CloseOrder was taken by another close after the transaction was read
+func (s *ManualCloseService) isClosedConcurrently(transactionId int64) bool {
+	transaction, err := s.transactionRepo.FindById(transactionId)
+	if err != nil || transaction == nil {
+		return false
+	}
+	return transaction.RelatedTransactionId.Valid || transaction.ClosingAt.Valid
+}
+
+func (s *ManualCloseService) findCoin(coins map[int64]*domain.Coin, coinId int64) (*domain.Coin, error) {
+	if coin, found := coins[coinId]; found {
+		return coin, nil
+	}
+	coin, err := s.coinRepo.FindById(coinId)
+	if err != nil {
+		return nil, err
+	}
+	coins[coinId] = coin
+	return coin, nil
+}
@@ -0,0 +1,32 @@
+package orders
+
+import "time"
+
+// notExecutedOrderDto order with unknown status whose state was never received
+type notExecutedOrderDto struct {
+	orderId string
+}
+
+func (d *notExecutedOrderDto) CalculateAvgPrice() float64 {
+	return 0
+}
+
+func (d *notExecutedOrderDto) CalculateTotalCost() float64 {
+	return 0
+}
+
+func (d *notExecutedOrderDto) CalculateCommissionInUsd() float64 {
+	return 0
+}
+
+func (d *notExecutedOrderDto) GetAmount() float64 {
+	return 0
+}
+
+func (d *notExecutedOrderDto) GetCreatedAt() *time.Time {
+	return nil
+}
+
+func (d *notExecutedOrderDto) GetOrderId() string {
+	return d.orderId
+}
@@ -2,34 +2,47 @@ package orders
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"math"
 	"time"
 	"tradingViewWebhookBot/internal/api"
 	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/exitReason"
 	"tradingViewWebhookBot/internal/constants/futureType"
 	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/notification"
 	"tradingViewWebhookBot/internal/repository"
 	"tradingViewWebhookBot/internal/service/date"
-	telegramApi "tradingViewWebhookBot/internal/telegram"
 	"tradingViewWebhookBot/internal/util"
 )
 
 var orderManagerServiceImpl *OrderManagerService
 
+// netExposureTolerance allowed difference between opened transactions amount and exchange position size
+const netExposureTolerance = 0.0000001
+
+// closeClaimTimeout claim of a close older than this is treated as left by a crashed process and can be taken over
+const closeClaimTimeout = 5 * time.Minute
+
+// amountTolerance executed amount less than requested by more than this value is treated as partial fill
+const amountTolerance = 0.0000001
+
 func NewOrderManagerService(transactionRepo repository.Transaction,
+	strategyRepo repository.TradingStrategy,
 	exchangeApi api.ExchangeApi,
 	clock date.Clock,
-	telegramClient *telegramApi.TelegramClient,
+	notifier notification.Notifier,
 	leverage int64) *OrderManagerService {
 	if orderManagerServiceImpl != nil {
 		panic("Unexpected try to create second service instance")
 	}
 	orderManagerServiceImpl = &OrderManagerService{
 		transactionRepo: transactionRepo,
+		strategyRepo:    strategyRepo,
 		exchangeApi:     exchangeApi,
-		telegramClient:  telegramClient,
+		notifier:        notifier,
 		Clock:           clock,
 		leverage:        leverage,
 	}
@@ -38,8 +51,9 @@ func NewOrderManagerService(transactionRepo repository.Transaction,
 
 type OrderManagerService struct {
 	transactionRepo repository.Transaction
+	strategyRepo    repository.TradingStrategy
 	exchangeApi     api.ExchangeApi
-	telegramClient  *telegramApi.TelegramClient
+	notifier        notification.Notifier
 	Clock           date.Clock
 	leverage        int64
 }
@@ -73,7 +87,7 @@ func (s *OrderManagerService) OpenFuturesOrderWithPercentStopLoss(tradingStrateg
 }
 
 func (s *OrderManagerService) OpenFuturesOrderWithFixedStopLoss(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, tradingKey string, futuresType futureType.FuturesType, stopLossPrice float64) {
-	s.openOrderWithCostAndFixedStopLossAndTakeProfit(tradingStrategy, coin, tradingKey, futuresType, stopLossPrice, 0, s.getCostOfOrder(), constants.FUTURES)
+	s.openOrderWithCostAndFixedStopLossAndTakeProfit(tradingStrategy, coin, tradingKey, futuresType, stopLossPrice, 0, s.getCostOfOrder(tradingStrategy, coin), constants.FUTURES)
 }
 
 func (s *OrderManagerService) OpenFuturesOrderWithCostAndFixedStopLossAndTakeProfit(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, tradingKey string, futuresType futureType.FuturesType, cost float64, stopLossPrice float64, profitPrice float64) {
@@ -88,12 +102,52 @@ func (s *OrderManagerService) OpenOrderWithCost(tradingStrategy *domain.TradingS
 	s.openOrderWithCostAndFixedStopLossAndTakeProfit(tradingStrategy, coin, tradingKey, futuresType, 0, 0, cost, tradingType)
 }
 
-func (s *OrderManagerService) OpenOrderAllIn(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, futuresType futureType.FuturesType) {
-	s.openOrderWithCostAndFixedStopLossAndTakeProfit(tradingStrategy, coin, "", futuresType, 0, 0, s.getCostOfOrder(), constants.FUTURES)
+// OpenOrderAllIn alert is the one which triggered the order, nil if it was not triggered by an alert
+func (s *OrderManagerService) OpenOrderAllIn(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, tradingKey string, futuresType futureType.FuturesType, alert *domain.Alert) {
+	_, _ = s.openOrderWithCostAndFixedStopLossAndTakeProfitAndLink(tradingStrategy, coin, tradingKey, futuresType, 0, 0, s.getCostOfOrder(tradingStrategy, coin), constants.FUTURES, nil, alert)
+}
+
+// ReverseOrder closes the opened position and opens all-in position of the opposite side.
+// Both legs are linked: the new open transaction refers to the close transaction by ReversedTransactionId.
+func (s *OrderManagerService) ReverseOrder(tradingStrategy *domain.TradingStrategy, openTransaction *domain.Transaction, coin *domain.Coin, price float64, alert *domain.Alert) *domain.Transaction {
+	closeTransaction := s.CloseOrder(tradingStrategy, openTransaction, coin, price, constants.FUTURES, exitReason.REVERSE, alert)
+	if closeTransaction == nil {
+		return nil
+	}
+	if openTransaction.Amount-closeTransaction.Amount > amountTolerance {
+		s.notifier.Notify(notification.Critical(coin.Symbol+" reverse is not completed", "Position was partially closed during reverse, opposite position is not opened"))
+		return nil
+	}
+
+	oppositeType := futureType.GetTypeByBool(openTransaction.FuturesType == futureType.SHORT)
+	reversedTransaction, err := s.openOrderWithCostAndFixedStopLossAndTakeProfitAndLink(tradingStrategy, coin, openTransaction.TradingKey, oppositeType,
+		0, 0, s.getCostOfOrder(tradingStrategy, coin), constants.FUTURES, closeTransaction, alert)
+	if _, isStatusUnknown := asOrderStatusUnknown(err); isStatusUnknown && reversedTransaction != nil {
+		// the opposite position may be opened, it's recorded and linked to the close, the status unknown notification is sent by the open
+		zap.S().Errorf("Status of the reverse open of transaction %d is unknown: %s", openTransaction.Id, err.Error())
+		return reversedTransaction
+	}
+	if err != nil {
+		zap.S().Errorf("Error during reverse of transaction %d: %s", openTransaction.Id, err.Error())
+		closeTransaction.ApiError = sql.NullString{String: "reverse open failed: " + err.Error(), Valid: true}
+		if errT := s.transactionRepo.SaveTransaction(closeTransaction); errT != nil {
+			zap.S().Errorf("Error during SaveTransaction: %s", errT.Error())
+		}
+		s.notifier.Notify(notification.Critical(coin.Symbol+" reverse is not completed", fmt.Sprintf("Position was closed but %s position was not opened during reverse: %s",
+			futureType.GetString(oppositeType), err.Error())))
+		return nil
+	}
+
+	return reversedTransaction
 }
 
 func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfit(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, tradingKey string, futuresType futureType.FuturesType,
 	stopLossPrice float64, takeProfitPrice float64, cost float64, tradingType constants.TradingType) {
+	_, _ = s.openOrderWithCostAndFixedStopLossAndTakeProfitAndLink(tradingStrategy, coin, tradingKey, futuresType, stopLossPrice, takeProfitPrice, cost, tradingType, nil, nil)
+}
+
+func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfitAndLink(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, tradingKey string, futuresType futureType.FuturesType,
+	stopLossPrice float64, takeProfitPrice float64, cost float64, tradingType constants.TradingType, reversedTransaction *domain.Transaction, alert *domain.Alert) (*domain.Transaction, error) {
 	if stopLossPrice > 0 {
 		zap.S().Debugf("stopLossPrice %.2f  [%v]", stopLossPrice, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
 	}
@@ -104,7 +158,7 @@ func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfit(tra
 	currentPrice, err := s.exchangeApi.GetCurrentCoinPrice(coin)
 	if err != nil {
 		zap.S().Errorf("Error during GetCurrentCoinPrice at %v: %s", s.Clock.NowTime(), err.Error())
-		return
+		return nil, err
 	}
 
 	amountTransaction := util.CalculateAmountByPriceAndCost(currentPrice, cost)
@@ -114,42 +168,113 @@ func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfit(tra
 	} else if tradingType == constants.SPOT {
 		orderDto, err = s.exchangeApi.BuyCoinByMarket(coin, amountTransaction, currentPrice)
 	}
-	if err != nil {
+	statusErr, isStatusUnknown := asOrderStatusUnknown(err)
+	if err != nil && !isStatusUnknown {
 		zap.S().Errorf("Error during OpenFuturesOrder: %s", err.Error())
-		s.telegramClient.SendMessage(fmt.Sprintf("Error during OpenFuturesOrder: %s", err.Error()))
-		return
+		s.notifier.Notify(notification.Error("Error during OpenFuturesOrder of "+coin.Symbol, err))
+		return nil, err
+	}
+	if isStatusUnknown {
+		// the order may still be executed, it's recorded as opened with the executed part known so far and resolved on close
+		orderDto = getLastOrderState(statusErr)
+		zap.S().Errorf("Error during OpenFuturesOrder: %s", err.Error())
+		s.notifier.Notify(notification.Critical(coin.Symbol+" open order status is unknown",
+			fmt.Sprintf("Order %s is recorded as opened with executed amount %v of %v, check the position on the exchange", statusErr.OrderId, orderDto.GetAmount(), amountTransaction)))
 	}
 
-	transaction := s.createOpenTransactionByOrderResponseDto(tradingStrategy, coin, tradingKey, futuresType, orderDto, stopLossPrice, takeProfitPrice)
+	transaction := s.createOpenTransactionByOrderResponseDto(tradingStrategy, coin, tradingKey, futuresType, orderDto, amountTransaction, stopLossPrice, takeProfitPrice)
+	if isStatusUnknown {
+		transaction.OrderStatus = sql.NullString{String: api.ORDER_STATUS_UNKNOWN, Valid: true}
+		transaction.ApiError = sql.NullString{String: err.Error(), Valid: true}
+	}
+	if reversedTransaction != nil {
+		transaction.ReversedTransactionId = sql.NullInt64{Int64: reversedTransaction.Id, Valid: true}
+	}
+	if alert != nil {
+		transaction.AlertId = sql.NullInt64{Int64: alert.Id, Valid: true}
+	}
 	if err3 := s.transactionRepo.SaveTransaction(&transaction); err3 != nil {
 		zap.S().Errorf("Error during SaveTransaction: %s", err3.Error())
-		return
+		return nil, err3
+	}
+	if isStatusUnknown {
+		s.checkNetExposure(coin, tradingType)
+		return &transaction, err
 	}
 
 	zap.S().Infof("at %s Order opened [%s] with price %v and type [%v] (0-L, 1-S)", s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT), coin.Symbol, currentPrice, futuresType)
-	s.telegramClient.SendMessage(coin.Symbol + " " + transaction.String())
+	s.notifier.Notify(notification.OrderOpened(s.getTradeDetails(tradingStrategy, coin, &transaction, nil)))
+	s.checkNetExposure(coin, tradingType)
+
+	return &transaction, nil
 }
 
-func (s *OrderManagerService) CloseFuturesOrderWithCurrentPriceWithInterval(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, openTransaction *domain.Transaction, interval int) *domain.Transaction {
+func (s *OrderManagerService) CloseFuturesOrderWithCurrentPriceWithInterval(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, openTransaction *domain.Transaction, interval int, reason exitReason.ExitReason) *domain.Transaction {
 	currentPrice, _ := s.exchangeApi.GetCurrentCoinPrice(coin)
-	return s.CloseOrder(tradingStrategy, openTransaction, coin, currentPrice, constants.FUTURES)
+	return s.CloseOrder(tradingStrategy, openTransaction, coin, currentPrice, constants.FUTURES, reason, nil)
 }
 
-func (s *OrderManagerService) CloseOrder(tradingStrategy *domain.TradingStrategy, openTransaction *domain.Transaction, coin *domain.Coin, price float64, tradingType constants.TradingType) *domain.Transaction {
+// CloseOrder alert is the one which triggered the order, nil for watchdog and manual closes
+// The opened transaction is claimed in the repository before the order is sent, so the watchdog, alerts and manual closes
+// of other goroutines or processes which read the same transaction earlier don't close the position twice.
+func (s *OrderManagerService) CloseOrder(tradingStrategy *domain.TradingStrategy, openTransaction *domain.Transaction, coin *domain.Coin, price float64, tradingType constants.TradingType, reason exitReason.ExitReason, alert *domain.Alert) *domain.Transaction {
+	now := s.Clock.NowTime()
+	claimed, err := s.transactionRepo.ClaimForClose(openTransaction.Id, now, now.Add(-closeClaimTimeout))
+	if err != nil {
+		zap.S().Errorf("Error during ClaimForClose: %s", err.Error())
+		return nil
+	}
+	if !claimed {
+		zap.S().Warnf("Transaction %d of %s is already closed or being closed, %s close is skipped", openTransaction.Id, coin.Symbol, reason)
+		return nil
+	}
+	openTransaction.ClosingAt = sql.NullTime{Time: now, Valid: true}
+
+	if openTransaction.OrderStatus.String == api.ORDER_STATUS_UNKNOWN {
+		if isExecuted := s.resolveUnknownOrderStatus(coin, openTransaction); !isExecuted {
+			return nil
+		}
+	}
+
 	var orderResponseDto api.OrderResponseDto
-	var err error
 	if tradingType == constants.SPOT {
 		orderResponseDto, err = s.exchangeApi.SellCoinByMarket(coin, openTransaction.Amount, price)
 	} else if tradingType == constants.FUTURES {
 		orderResponseDto, err = s.exchangeApi.CloseFuturesOrder(coin, openTransaction, price)
 	}
-	if err != nil {
+	statusErr, isStatusUnknown := asOrderStatusUnknown(err)
+	if isStatusUnknown && getLastOrderState(statusErr).GetAmount() > 0 {
+		// the executed part is recorded as a partial close, the remainder stays opened
+		orderResponseDto = getLastOrderState(statusErr)
+		zap.S().Errorf("Error during CloseFuturesOrder: %s", err.Error())
+		s.notifier.Notify(notification.Critical(coin.Symbol+" close order status is unknown",
+			fmt.Sprintf("Order %s is recorded with executed amount %v of %v, check the position on the exchange", statusErr.OrderId, orderResponseDto.GetAmount(), openTransaction.Amount)))
+	} else if isStatusUnknown {
+		// the order may still be executed, the claim is kept until it expires, so the position is not closed twice meanwhile
+		zap.S().Errorf("Error during CloseFuturesOrder: %s", err.Error())
+		s.notifier.Notify(notification.Critical(coin.Symbol+" close order status is unknown",
+			fmt.Sprintf("Order %s closing transaction %d may be executed later, check the position on the exchange", statusErr.OrderId, openTransaction.Id)))
+		openTransaction.ApiError = sql.NullString{String: "close " + err.Error(), Valid: true}
+		if errT := s.transactionRepo.SaveTransaction(openTransaction); errT != nil {
+			zap.S().Errorf("Error during SaveTransaction: %s", errT.Error())
+		}
+		return nil
+	} else if err != nil {
 		zap.S().Errorf("Error during CloseFuturesOrder: %s", err.Error())
-		s.telegramClient.SendMessage(fmt.Sprintf("Error during CloseFuturesOrder: %s", err.Error()))
+		s.notifier.Notify(notification.Error("Error during CloseFuturesOrder of "+coin.Symbol, err))
+		s.releaseCloseClaim(openTransaction)
 		return nil
 	}
 
 	closeTransaction := s.createCloseTransactionByOrderResponseDto(tradingStrategy, coin, openTransaction, orderResponseDto)
+	if isStatusUnknown {
+		closeTransaction.OrderStatus = sql.NullString{String: api.ORDER_STATUS_UNKNOWN, Valid: true}
+		closeTransaction.ApiError = sql.NullString{String: err.Error(), Valid: true}
+	}
+	closeTransaction.ExitReason = sql.NullString{String: string(reason), Valid: true}
+	if alert != nil {
+		closeTransaction.AlertId = sql.NullInt64{Int64: alert.Id, Valid: true}
+	}
 	if errT := s.transactionRepo.SaveTransaction(closeTransaction); errT != nil {
 		zap.S().Errorf("Error during SaveTransaction: %s", errT.Error())
 		return nil
@@ -157,14 +282,166 @@ func (s *OrderManagerService) CloseOrder(tradingStrategy *domain.TradingStrategy
 
 	openTransaction.RelatedTransactionId = sql.NullInt64{Int64: closeTransaction.Id, Valid: true}
 	_ = s.transactionRepo.SaveTransaction(openTransaction)
-	s.telegramClient.SendMessage(coin.Symbol + " " + closeTransaction.String())
+	s.notifier.Notify(notification.OrderClosed(s.getTradeDetails(tradingStrategy, coin, openTransaction, closeTransaction)))
+
+	if remainingAmount := openTransaction.Amount - closeTransaction.Amount; remainingAmount > amountTolerance {
+		s.saveRemainderOfPartiallyClosedTransaction(openTransaction, remainingAmount)
+	}
+	s.checkNetExposure(coin, tradingType)
 
 	return closeTransaction
 }
 
+// resolveUnknownOrderStatus updates the opened transaction by the current state of its order whose status was unknown on open.
+// The transaction of the order which was not executed at all is linked to itself, so it's not opened anymore.
+// False if there is nothing to close, the claim is released then.
+func (s *OrderManagerService) resolveUnknownOrderStatus(coin *domain.Coin, openTransaction *domain.Transaction) bool {
+	orderDto, err := s.exchangeApi.GetOrder(coin, openTransaction.ClientOrderId.String)
+	if err != nil {
+		zap.S().Errorf("Error during GetOrder %s of transaction %d: %s", openTransaction.ClientOrderId.String, openTransaction.Id, err.Error())
+		s.releaseCloseClaim(openTransaction)
+		return false
+	}
+
+	openTransaction.Amount = orderDto.GetAmount()
+	openTransaction.Price = orderDto.CalculateAvgPrice()
+	openTransaction.TotalCost = orderDto.CalculateTotalCost()
+	s.setOrderDetails(openTransaction, orderDto, openTransaction.RequestedAmount.Float64)
+	s.setExecFee(coin, openTransaction, orderDto)
+	if openTransaction.Amount == 0 {
+		zap.S().Warnf("Order %s of transaction %d was not executed: %s", openTransaction.ClientOrderId.String, openTransaction.Id, openTransaction.OrderStatus.String)
+		openTransaction.RelatedTransactionId = sql.NullInt64{Int64: openTransaction.Id, Valid: true}
+	}
+	if errT := s.transactionRepo.SaveTransaction(openTransaction); errT != nil {
+		zap.S().Errorf("Error during SaveTransaction: %s", errT.Error())
+		return false
+	}
+	return openTransaction.Amount > 0
+}
+
+func asOrderStatusUnknown(err error) (*api.OrderStatusUnknownError, bool) {
+	var statusErr *api.OrderStatusUnknownError
+	if errors.As(err, &statusErr) {
+		return statusErr, true
+	}
+	return nil, false
+}
+
+// getLastOrderState the last received state of the order with unknown status, nothing is executed if no state was received
+func getLastOrderState(statusErr *api.OrderStatusUnknownError) api.OrderResponseDto {
+	if statusErr.Order != nil {
+		return statusErr.Order
+	}
+	return &notExecutedOrderDto{orderId: statusErr.OrderId}
+}
+
+// releaseCloseClaim lets the next close try again after the order was not sent
+func (s *OrderManagerService) releaseCloseClaim(openTransaction *domain.Transaction) {
+	if err := s.transactionRepo.ReleaseCloseClaim(openTransaction.Id); err != nil {
+		zap.S().Errorf("Error during ReleaseCloseClaim: %s", err.Error())
+		return
+	}
+	openTransaction.ClosingAt = sql.NullTime{}
+}
+
+// getTradeDetails of the opened position or of the closed one if closeTransaction is set
+func (s *OrderManagerService) getTradeDetails(tradingStrategy *domain.TradingStrategy, coin *domain.Coin, openTransaction *domain.Transaction, closeTransaction *domain.Transaction) notification.TradeDetails {
+	trade := notification.TradeDetails{
+		Strategy:   tradingStrategy.Name,
+		Symbol:     coin.Symbol,
+		Side:       futureType.GetString(openTransaction.FuturesType),
+		Leverage:   s.leverage,
+		Amount:     openTransaction.Amount,
+		EntryPrice: openTransaction.Price,
+		StopLoss:   openTransaction.StopLossPrice.Float64,
+		TakeProfit: openTransaction.TakeProfitPrice.Float64,
+		Fee:        openTransaction.GetFee(),
+		AlertId:    openTransaction.AlertId.Int64,
+	}
+
+	lastTransaction := openTransaction
+	if closeTransaction != nil {
+		closedPart := float64(1)
+		if openTransaction.Amount > 0 && closeTransaction.Amount < openTransaction.Amount {
+			closedPart = closeTransaction.Amount / openTransaction.Amount
+		}
+		trade.Amount = closeTransaction.Amount
+		trade.ExitPrice = closeTransaction.Price
+		trade.Fee = openTransaction.GetFee()*closedPart + closeTransaction.GetFee()
+		trade.ProfitUsd = util.GetDollarsByCents(closeTransaction.Profit.Int64)
+		trade.ProfitPercent = closeTransaction.PercentProfit.Float64
+		trade.HoldingTime = closeTransaction.CreatedAt.Sub(openTransaction.CreatedAt)
+		trade.ExitReason = closeTransaction.ExitReason.String
+		trade.AlertId = closeTransaction.AlertId.Int64
+		lastTransaction = closeTransaction
+	}
+
+	if lastTransaction.RequestedAmount.Valid && lastTransaction.RequestedAmount.Float64-lastTransaction.Amount > amountTolerance {
+		trade.PartiallyFilled = true
+		trade.RequestedAmount = lastTransaction.RequestedAmount.Float64
+	}
+	return trade
+}
+
+// checkNetExposure compares the sum of opened sub-positions of all strategies with the exchange position
+func (s *OrderManagerService) checkNetExposure(coin *domain.Coin, tradingType constants.TradingType) {
+	if tradingType != constants.FUTURES {
+		return
+	}
+
+	expectedSize, err := s.transactionRepo.CalculateNetOpenedAmountByCoin(coin.Id)
+	if err != nil {
+		zap.S().Errorf("Error during CalculateNetOpenedAmountByCoin: %s", err.Error())
+		return
+	}
+
+	exchangeSize, err := s.exchangeApi.GetNetPositionSize(coin)
+	if err != nil {
+		zap.S().Errorf("Error during GetNetPositionSize: %s", err.Error())
+		return
+	}
+
+	if math.Abs(expectedSize-exchangeSize) > netExposureTolerance {
+		zap.S().Warnf("Net exposure mismatch of %s: transactions %v, exchange %v", coin.Symbol, expectedSize, exchangeSize)
+		s.notifier.Notify(notification.Critical(coin.Symbol+" net exposure mismatch",
+			fmt.Sprintf("Opened transactions %v, exchange position %v", expectedSize, exchangeSize)))
+	}
+}
+
+// saveRemainderOfPartiallyClosedTransaction keeps the not closed part of the position tracked as a new open transaction
+func (s *OrderManagerService) saveRemainderOfPartiallyClosedTransaction(openTransaction *domain.Transaction, remainingAmount float64) {
+	remainder := *openTransaction
+	remainder.Id = 0
+	remainder.RelatedTransactionId = sql.NullInt64{}
+	remainder.ReversedTransactionId = sql.NullInt64{}
+	remainder.ParentTransactionId = sql.NullInt64{Int64: openTransaction.Id, Valid: true}
+	remainder.Amount = remainingAmount
+	remainder.TotalCost = openTransaction.Price * remainingAmount
+	remainder.Commission = openTransaction.Commission * remainingAmount / openTransaction.Amount
+	if openTransaction.ExecFee.Valid {
+		remainder.ExecFee = sql.NullFloat64{Float64: openTransaction.ExecFee.Float64 * remainingAmount / openTransaction.Amount, Valid: true}
+	}
+
+	if err := s.transactionRepo.SaveTransaction(&remainder); err != nil {
+		zap.S().Errorf("Error during SaveTransaction of remainder of transaction %d: %s", openTransaction.Id, err.Error())
+		s.notifier.Notify(notification.Critical("Remainder of partially closed position is not tracked",
+			fmt.Sprintf("Remainder %v of transaction %d: %s", remainingAmount, openTransaction.Id, err.Error())))
+	}
+}
+
+func (s *OrderManagerService) setOrderDetails(transaction *domain.Transaction, orderDto api.OrderResponseDto, requestedAmount float64) {
+	if orderId := orderDto.GetOrderId(); orderId != "" {
+		transaction.ClientOrderId = sql.NullString{String: orderId, Valid: true}
+	}
+	if statusDto, ok := orderDto.(api.OrderStatusDto); ok && statusDto.GetOrderStatus() != "" {
+		transaction.OrderStatus = sql.NullString{String: statusDto.GetOrderStatus(), Valid: true}
+	}
+	transaction.RequestedAmount = sql.NullFloat64{Float64: requestedAmount, Valid: true}
+}
+
 func (s *OrderManagerService) createOpenTransactionByOrderResponseDto(
 	tradingStrategy *domain.TradingStrategy, coin *domain.Coin, tradingKey string, futuresType futureType.FuturesType,
-	orderDto api.OrderResponseDto, stopLossPrice float64, takeProfitPrice float64) domain.Transaction {
+	orderDto api.OrderResponseDto, requestedAmount float64, stopLossPrice float64, takeProfitPrice float64) domain.Transaction {
 
 	var createdAt time.Time
 	if orderDto.GetCreatedAt() != nil {
@@ -181,9 +458,10 @@ func (s *OrderManagerService) createOpenTransactionByOrderResponseDto(
 		Amount:            orderDto.GetAmount(),
 		Price:             orderDto.CalculateAvgPrice(),
 		TotalCost:         orderDto.CalculateTotalCost(),
-		Commission:        orderDto.CalculateCommissionInUsd(),
 		CreatedAt:         createdAt,
 	}
+	s.setOrderDetails(&transaction, orderDto, requestedAmount)
+	s.setExecFee(coin, &transaction, orderDto)
 
 	if futuresType == futureType.LONG {
 		transaction.TransactionType = constants.BUY
@@ -202,22 +480,13 @@ func (s *OrderManagerService) createOpenTransactionByOrderResponseDto(
 func (s *OrderManagerService) createCloseTransactionByOrderResponseDto(tradingStrategy *domain.TradingStrategy,
 	coin *domain.Coin, openedTransaction *domain.Transaction, orderDto api.OrderResponseDto) *domain.Transaction {
 
-	var buyCost float64
-	var sellCost float64
 	var transactionType constants.TransactionType
-
 	if openedTransaction.FuturesType == futureType.LONG {
-		buyCost = openedTransaction.TotalCost
-		sellCost = orderDto.CalculateTotalCost()
 		transactionType = constants.SELL
 	} else {
-		buyCost = orderDto.CalculateTotalCost()
-		sellCost = openedTransaction.TotalCost
 		transactionType = constants.BUY
 	}
 
-	profitInUsd := sellCost - buyCost - orderDto.CalculateCommissionInUsd() - openedTransaction.Commission
-
 	var createdAt time.Time
 	if orderDto.GetCreatedAt() != nil {
 		createdAt = *orderDto.GetCreatedAt()
@@ -225,8 +494,6 @@ func (s *OrderManagerService) createCloseTransactionByOrderResponseDto(tradingSt
 		createdAt = s.Clock.NowTime()
 	}
 
-	percentProfit := float64(profitInUsd) / float64(openedTransaction.TotalCost) * 100
-
 	transaction := domain.Transaction{
 		TradingKey:           openedTransaction.TradingKey,
 		TradingStrategyId:    sql.NullInt64{Int64: tradingStrategy.Id, Valid: true},
@@ -236,29 +503,108 @@ func (s *OrderManagerService) createCloseTransactionByOrderResponseDto(tradingSt
 		Amount:               orderDto.GetAmount(),
 		Price:                orderDto.CalculateAvgPrice(),
 		TotalCost:            orderDto.CalculateTotalCost(),
-		Commission:           orderDto.CalculateCommissionInUsd(),
 		RelatedTransactionId: sql.NullInt64{Int64: openedTransaction.Id, Valid: true},
-		Profit:               sql.NullInt64{Int64: util.GetCents(profitInUsd), Valid: true},
-		PercentProfit:        sql.NullFloat64{Float64: math.Round(percentProfit*100) / 100, Valid: true},
 		CreatedAt:            createdAt,
 		IsFake:               openedTransaction.IsFake,
 	}
+	s.setOrderDetails(&transaction, orderDto, openedTransaction.Amount)
+	s.setExecFee(coin, &transaction, orderDto)
+
+	fundingFee, err := s.exchangeApi.GetFundingFee(coin, openedTransaction.FuturesType, transaction.Amount, openedTransaction.CreatedAt, createdAt)
+	if err != nil {
+		zap.S().Warnf("Funding fee of %s is not fetched and has to be backfilled: %s", coin.Symbol, err.Error())
+	} else {
+		transaction.FundingFee = sql.NullFloat64{Float64: fundingFee, Valid: true}
+	}
+
+	CalculateRealisedProfit(openedTransaction, &transaction)
 	return &transaction
 }
 
-func (s *OrderManagerService) getCostOfOrder() float64 {
+// setExecFee real fee of the order executions, the estimated commission is used in profit until it's backfilled
+func (s *OrderManagerService) setExecFee(coin *domain.Coin, transaction *domain.Transaction, orderDto api.OrderResponseDto) {
+	transaction.Commission = orderDto.CalculateCommissionInUsd()
+	if orderDto.GetOrderId() == "" {
+		return
+	}
+
+	execFee, err := s.exchangeApi.GetOrderExecFee(coin, orderDto.GetOrderId())
+	if err != nil {
+		zap.S().Warnf("Exec fee of order %s is not fetched and has to be backfilled: %s", orderDto.GetOrderId(), err.Error())
+		return
+	}
+	transaction.ExecFee = sql.NullFloat64{Float64: execFee, Valid: true}
+}
+
+// CalculateRealisedProfit sets gross profit, profit and percent profit of the close transaction:
+// profit = gross profit - fees of both legs - funding.
+// Only the closed part of the opened transaction is counted for partially filled close orders.
+func CalculateRealisedProfit(openedTransaction *domain.Transaction, closeTransaction *domain.Transaction) {
+	closedPart := float64(1)
+	if openedTransaction.Amount > 0 && closeTransaction.Amount < openedTransaction.Amount {
+		closedPart = closeTransaction.Amount / openedTransaction.Amount
+	}
+	openCost := openedTransaction.TotalCost * closedPart
+
+	grossProfitInUsd := (closeTransaction.TotalCost - openCost) * futureType.GetFuturesSignFloat64(openedTransaction.FuturesType)
+	profitInUsd := grossProfitInUsd - openedTransaction.GetFee()*closedPart - closeTransaction.GetFee() - closeTransaction.FundingFee.Float64
+
+	percentProfit := float64(0)
+	if openCost != 0 {
+		percentProfit = profitInUsd / openCost * 100
+	}
+
+	closeTransaction.GrossProfit = sql.NullInt64{Int64: util.GetCents(grossProfitInUsd), Valid: true}
+	closeTransaction.Profit = sql.NullInt64{Int64: util.GetCents(profitInUsd), Valid: true}
+	closeTransaction.PercentProfit = sql.NullFloat64{Float64: math.Round(percentProfit*100) / 100, Valid: true}
+}
+
+// getCostOfOrder the available balance is shared by the strategy and the other enabled strategies allowed to trade the coin
+// which have no position of it yet, so the first alert doesn't take the whole wallet from the others.
+// The margin of opened positions is already excluded from the available balance.
+func (s *OrderManagerService) getCostOfOrder(tradingStrategy *domain.TradingStrategy, coin *domain.Coin) float64 {
 	walletBalanceDto, err := s.exchangeApi.GetWalletBalance()
 	if err != nil {
 		zap.S().Errorf("Error during GetWalletBalance at %v: %s", s.Clock.NowTime(), err.Error())
-		s.telegramClient.SendMessage(fmt.Sprintf("Error getting wallet balance: %s", err.Error()))
+		s.notifier.Notify(notification.Error("Error getting wallet balance", err))
+		return 0
+	}
+
+	waitingStrategies, err := s.countWaitingStrategies(tradingStrategy, coin)
+	if err != nil {
+		zap.S().Errorf("Error during count of strategies of %s: %s", coin.Symbol, err.Error())
+		s.notifier.Notify(notification.Error("Error getting strategies of "+coin.Symbol, err))
 		return 0
 	}
 
-	maxOrderCost := (walletBalanceDto.GetAvailableBalance() - 1) * float64(s.leverage)
+	maxOrderCost := (walletBalanceDto.GetAvailableBalance() - 1) * float64(s.leverage) / float64(waitingStrategies+1)
 
 	return maxOrderCost
 }
 
+// countWaitingStrategies other enabled strategies allowed to trade the coin without an opened position of it
+func (s *OrderManagerService) countWaitingStrategies(tradingStrategy *domain.TradingStrategy, coin *domain.Coin) (int, error) {
+	strategies, err := s.strategyRepo.List()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range strategies {
+		if strategies[i].Id == tradingStrategy.Id || !strategies[i].Enabled || !strategies[i].IsSymbolAllowed(coin.Symbol) {
+			continue
+		}
+		openedTransaction, err := s.transactionRepo.FindOpenedTransactionByCoin(strategies[i].Id, coin.Id)
+		if err != nil {
+			return 0, err
+		}
+		if openedTransaction == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (s *OrderManagerService) CalculateCurrentProfitInPercentWithoutLeverage(coin *domain.Coin, openedTransaction *domain.Transaction) (float64, error) {
 	currentPrice, err := s.exchangeApi.GetCurrentCoinPrice(coin)
 	if err != nil {
@@ -278,7 +624,10 @@ func (s *OrderManagerService) CalculateCurrentProfitInPercentWithLeverage(coin *
 		return 0, err
 	}
 
-	currentProfitInPercent := util.CalculateProfitInPercentWithLeverage(openedTransaction.Price, currentPrice, openedTransaction.FuturesType, s.leverage)
+	return s.CalculateProfitInPercentWithLeverageByPrice(openedTransaction, currentPrice), nil
+}
 
-	return currentProfitInPercent, nil
+// CalculateProfitInPercentWithLeverageByPrice when the current price is already known, e.g. for many positions of one coin
+func (s *OrderManagerService) CalculateProfitInPercentWithLeverageByPrice(openedTransaction *domain.Transaction, currentPrice float64) float64 {
+	return util.CalculateProfitInPercentWithLeverage(openedTransaction.Price, currentPrice, openedTransaction.FuturesType, s.leverage)
 }
@@ -0,0 +1,223 @@
+package positions
+
+import (
+	"errors"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/trade"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/orders"
+
+	"go.uber.org/zap"
+)
+
+var ErrTransactionNotFound = errors.New("transaction not found")
+var ErrAlertNotFound = errors.New("alert not found")
+
+func NewPositionService(
+	strategyRepo repository.TradingStrategy,
+	transactionRepo repository.Transaction,
+	coinRepo repository.Coin,
+	alertRepo repository.Alert,
+	exchangeApi api.ExchangeApi,
+	orderManagerService *orders.OrderManagerService,
+	clock date.Clock,
+) *PositionService {
+	return &PositionService{
+		strategyRepo:        strategyRepo,
+		transactionRepo:     transactionRepo,
+		coinRepo:            coinRepo,
+		alertRepo:           alertRepo,
+		exchangeApi:         exchangeApi,
+		orderManagerService: orderManagerService,
+		clock:               clock,
+	}
+}
+
+// PositionService read-only view of what the bot holds and has traded
+type PositionService struct {
+	strategyRepo        repository.TradingStrategy
+	transactionRepo     repository.Transaction
+	coinRepo            repository.Coin
+	alertRepo           repository.Alert
+	exchangeApi         api.ExchangeApi
+	orderManagerService *orders.OrderManagerService
+	clock               date.Clock
+}
+
+// GetOpenedPositions of one strategy or of all strategies when strategyId is 0.
+// Current price is requested once per coin, a failed price doesn't hide the position.
+func (s *PositionService) GetOpenedPositions(strategyId int64) ([]trade.PositionDto, error) {
+	strategies, err := s.strategyRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	coins := make(map[int64]*domain.Coin)
+	prices := make(map[int64]float64)
+	priceErrors := make(map[int64]error)
+	now := s.clock.NowTime()
+
+	result := make([]trade.PositionDto, 0)
+	for _, strategy := range strategies {
+		if strategyId != 0 && strategy.Id != strategyId {
+			continue
+		}
+
+		openedTransactions, err := s.transactionRepo.FindAllOpenedTransactions(strategy)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, openedTransaction := range openedTransactions {
+			coin, found := coins[openedTransaction.CoinId]
+			if !found {
+				if coin, err = s.coinRepo.FindById(openedTransaction.CoinId); err != nil {
+					return nil, err
+				}
+				coins[coin.Id] = coin
+				prices[coin.Id], priceErrors[coin.Id] = s.exchangeApi.GetCurrentCoinPrice(coin)
+			}
+
+			position := trade.PositionDto{
+				TransactionId:       openedTransaction.Id,
+				TradingStrategyId:   strategy.Id,
+				TradingStrategyName: strategy.Name,
+				CoinSymbol:          coin.Symbol,
+				Side:                futureType.GetString(openedTransaction.FuturesType),
+				TradingKey:          openedTransaction.TradingKey,
+				Amount:              openedTransaction.Amount,
+				OpenPrice:           openedTransaction.Price,
+				OpenedAt:            openedTransaction.CreatedAt,
+				HoldingSeconds:      int64(now.Sub(openedTransaction.CreatedAt).Seconds()),
+				IsFake:              openedTransaction.IsFake,
+			}
+
+			if priceErr := priceErrors[coin.Id]; priceErr != nil {
+				zap.S().Errorf("Error during GetCurrentCoinPrice of %s: %s", coin.Symbol, priceErr.Error())
+				position.PriceError = priceErr.Error()
+			} else {
+				currentPrice := prices[coin.Id]
+				position.CurrentPrice = currentPrice
+				position.UnrealisedProfit = (currentPrice - openedTransaction.Price) * openedTransaction.Amount *
+					futureType.GetFuturesSignFloat64(openedTransaction.FuturesType)
+				position.UnrealisedPercent = s.orderManagerService.CalculateProfitInPercentWithLeverageByPrice(openedTransaction, currentPrice)
+			}
+			result = append(result, position)
+		}
+	}
+	return result, nil
+}
+
+func (s *PositionService) GetTransactions(filter transaction.TransactionFilter, limit int, offset int) (*trade.TransactionPageDto, error) {
+	transactions, total, err := s.transactionRepo.FindTransactions(filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols, err := s.getCoinSymbols()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &trade.TransactionPageDto{
+		Items:  make([]trade.TransactionDto, 0, len(transactions)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, item := range transactions {
+		page.Items = append(page.Items, trade.NewTransactionDto(item, symbols[item.CoinId]))
+	}
+	return page, nil
+}
+
+// GetRoundTrip by id of either leg, close is empty while the position is opened
+func (s *PositionService) GetRoundTrip(transactionId int64) (*trade.RoundTripDetailDto, error) {
+	requested, err := s.transactionRepo.FindById(transactionId)
+	if err != nil {
+		return nil, err
+	}
+	if requested == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	openTransaction, closeTransaction := requested, (*domain.Transaction)(nil)
+	if requested.Profit.Valid {
+		closeTransaction = requested
+		if openTransaction, err = s.transactionRepo.FindById(requested.RelatedTransactionId.Int64); err != nil {
+			return nil, err
+		}
+		if openTransaction == nil {
+			return nil, ErrTransactionNotFound
+		}
+	} else if requested.RelatedTransactionId.Valid {
+		if closeTransaction, err = s.transactionRepo.FindById(requested.RelatedTransactionId.Int64); err != nil {
+			return nil, err
+		}
+	}
+
+	symbols, err := s.getCoinSymbols()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &trade.RoundTripDetailDto{
+		Open: trade.NewTransactionDto(openTransaction, symbols[openTransaction.CoinId]),
+	}
+	if closeTransaction != nil {
+		closeDto := trade.NewTransactionDto(closeTransaction, symbols[closeTransaction.CoinId])
+		result.Close = &closeDto
+		result.HoldingSeconds = int64(closeTransaction.CreatedAt.Sub(openTransaction.CreatedAt).Seconds())
+	}
+
+	remainders, err := s.transactionRepo.FindByParentTransactionId(openTransaction.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, remainder := range remainders {
+		result.Remainders = append(result.Remainders, trade.NewTransactionDto(remainder, symbols[remainder.CoinId]))
+	}
+	return result, nil
+}
+
+// GetAlert with transactions opened or closed by the alert
+func (s *PositionService) GetAlert(alertId int64) (*trade.AlertDto, error) {
+	alert, err := s.alertRepo.FindById(alertId)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil {
+		return nil, ErrAlertNotFound
+	}
+
+	transactions, err := s.transactionRepo.FindByAlertId(alertId)
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := s.getCoinSymbols()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &trade.AlertDto{Alert: *alert, Transactions: make([]trade.TransactionDto, 0, len(transactions))}
+	for _, item := range transactions {
+		result.Transactions = append(result.Transactions, trade.NewTransactionDto(item, symbols[item.CoinId]))
+	}
+	return result, nil
+}
+
+func (s *PositionService) getCoinSymbols() (map[int64]string, error) {
+	coins, err := s.coinRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[int64]string, len(coins))
+	for _, coin := range coins {
+		symbols[coin.Id] = coin.Symbol
+	}
+	return symbols, nil
+}
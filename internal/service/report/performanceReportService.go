@@ -0,0 +1,250 @@
+package report
+
+import (
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants/reportPeriod"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/report"
+	"tradingViewWebhookBot/internal/notification"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/positions"
+	"tradingViewWebhookBot/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// checkInterval how often the schedule is checked
+const checkInterval = time.Minute
+
+// NewPerformanceReportService dailyAt "HH:MM", weeklyAt "Mon HH:MM" in UTC, empty - the report is not sent
+func NewPerformanceReportService(
+	strategyRepo repository.TradingStrategy,
+	transactionRepo repository.Transaction,
+	exchangeApi api.ExchangeApi,
+	positionService *positions.PositionService,
+	notifier notification.Notifier,
+	clock date.Clock,
+	dailyAt string,
+	weeklyAt string,
+) *PerformanceReportService {
+	service := &PerformanceReportService{
+		strategyRepo:    strategyRepo,
+		transactionRepo: transactionRepo,
+		exchangeApi:     exchangeApi,
+		positionService: positionService,
+		notifier:        notifier,
+		clock:           clock,
+	}
+
+	var err error
+	if service.daily, err = parseSchedule(dailyAt, false); err != nil {
+		zap.S().Errorf("Daily report is disabled: %s", err.Error())
+	}
+	if service.weekly, err = parseSchedule(weeklyAt, true); err != nil {
+		zap.S().Errorf("Weekly report is disabled: %s", err.Error())
+	}
+	return service
+}
+
+// PerformanceReportService sends the summary of the closed days on schedule: the daily report covers the previous UTC day,
+// the weekly one 7 days before the report day
+type PerformanceReportService struct {
+	strategyRepo    repository.TradingStrategy
+	transactionRepo repository.Transaction
+	exchangeApi     api.ExchangeApi
+	positionService *positions.PositionService
+	notifier        notification.Notifier
+	clock           date.Clock
+
+	daily      *schedule
+	weekly     *schedule
+	nextDaily  time.Time
+	nextWeekly time.Time
+}
+
+func (s *PerformanceReportService) Start() {
+	if s.daily == nil && s.weekly == nil {
+		zap.S().Info("Performance reports are disabled")
+		return
+	}
+	s.scheduleNext(s.clock.NowTime())
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.CheckSchedule()
+		}
+	}()
+	zap.S().Infof("Performance reports scheduled, daily at %v, weekly at %v", s.nextDaily, s.nextWeekly)
+}
+
+// CheckSchedule sends the reports whose time has come, a report missed while the bot was down is not sent
+func (s *PerformanceReportService) CheckSchedule() {
+	now := s.clock.NowTime()
+	if s.daily != nil && !now.Before(s.nextDaily) {
+		s.SendReport(reportPeriod.DAILY, now)
+		s.nextDaily = s.daily.next(now)
+	}
+	if s.weekly != nil && !now.Before(s.nextWeekly) {
+		s.SendReport(reportPeriod.WEEKLY, now)
+		s.nextWeekly = s.weekly.next(now)
+	}
+}
+
+func (s *PerformanceReportService) scheduleNext(now time.Time) {
+	if s.daily != nil {
+		s.nextDaily = s.daily.next(now)
+	}
+	if s.weekly != nil {
+		s.nextWeekly = s.weekly.next(now)
+	}
+}
+
+func (s *PerformanceReportService) SendReport(period reportPeriod.ReportPeriod, now time.Time) {
+	performanceReport, err := s.BuildReport(period, now)
+	if err != nil {
+		zap.S().Errorf("Error during build of %s report: %s", period, err.Error())
+		s.notifier.Notify(notification.Error("Performance report is not built", err))
+		return
+	}
+
+	message := FormatReport(performanceReport)
+	if period == reportPeriod.WEEKLY {
+		s.notifier.Notify(notification.WeeklySummary(message))
+	} else {
+		s.notifier.Notify(notification.DailySummary(message))
+	}
+}
+
+// BuildReport of the whole UTC days before now. Wallet balance and current prices are optional:
+// the report is built without them if the exchange is not available.
+func (s *PerformanceReportService) BuildReport(period reportPeriod.ReportPeriod, now time.Time) (*report.PerformanceReportDto, error) {
+	to := getDayStart(now)
+	from := to.AddDate(0, 0, -reportPeriod.GetDays(period))
+	result := &report.PerformanceReportDto{Period: period, From: from, To: to}
+
+	roundTrips, err := s.transactionRepo.FindRoundTrips(transaction.RoundTripFilter{From: &from, To: &to})
+	if err != nil {
+		return nil, err
+	}
+	s.addTrades(result, roundTrips)
+
+	if err := s.addStrategyProfits(result, roundTrips); err != nil {
+		return nil, err
+	}
+
+	openedPositions, err := s.positionService.GetOpenedPositions(0)
+	if err != nil {
+		return nil, err
+	}
+	result.OpenedPositions = openedPositions
+	for _, position := range openedPositions {
+		result.UnrealisedProfit += position.UnrealisedProfit
+	}
+
+	walletBalance, err := s.exchangeApi.GetWalletBalance()
+	if err != nil {
+		zap.S().Warnf("Wallet balance is not included to the report: %s", err.Error())
+	} else {
+		balance := walletBalance.GetAvailableBalance()
+		result.WalletBalance = &balance
+	}
+	return result, nil
+}
+
+// addTrades totals of the closed trades and realised profit per coin
+func (s *PerformanceReportService) addTrades(result *report.PerformanceReportDto, roundTrips []transaction.RoundTripDto) {
+	coins := make(map[string]*report.ProfitRowDto)
+	var profitCents int64
+	for _, roundTrip := range roundTrips {
+		result.Trades++
+		if roundTrip.Profit > 0 {
+			result.Wins++
+		}
+		profitCents += roundTrip.Profit
+		result.Fees += roundTrip.OpenFee + roundTrip.CloseFee + roundTrip.FundingFee
+
+		row, ok := coins[roundTrip.CoinSymbol]
+		if !ok {
+			row = &report.ProfitRowDto{Name: roundTrip.CoinSymbol}
+			coins[roundTrip.CoinSymbol] = row
+		}
+		row.Trades++
+		row.Profit += util.GetDollarsByCents(roundTrip.Profit)
+	}
+
+	result.RealisedProfit = util.GetDollarsByCents(profitCents)
+	if result.Trades > 0 {
+		result.WinRate = float64(result.Wins) / float64(result.Trades) * 100
+	}
+	result.Coins = sortProfitRows(coins)
+}
+
+// addStrategyProfits realised profit of every strategy and day by CalculateSumOfProfitByDate,
+// strategies without trades in the period are skipped
+func (s *PerformanceReportService) addStrategyProfits(result *report.PerformanceReportDto, roundTrips []transaction.RoundTripDto) error {
+	tradesByStrategy := make(map[int64]int)
+	for _, roundTrip := range roundTrips {
+		tradesByStrategy[roundTrip.TradingStrategyId]++
+	}
+
+	strategies, err := s.strategyRepo.List()
+	if err != nil {
+		return err
+	}
+
+	days := int(result.To.Sub(result.From).Hours() / 24)
+	dailyProfit := make([]int64, days)
+	rows := make(map[string]*report.ProfitRowDto)
+	for _, strategy := range strategies {
+		if tradesByStrategy[strategy.Id] == 0 {
+			continue
+		}
+		row := &report.ProfitRowDto{Name: strategy.Name, Trades: tradesByStrategy[strategy.Id]}
+		for day := 0; day < days; day++ {
+			profit, err := s.transactionRepo.CalculateSumOfProfitByDate(result.From.AddDate(0, 0, day), strategy)
+			if err != nil {
+				return err
+			}
+			row.Profit += util.GetDollarsByCents(profit)
+			dailyProfit[day] += profit
+		}
+		rows[strategy.Name] = row
+	}
+	result.Strategies = sortProfitRows(rows)
+
+	if days > 1 {
+		for day := 0; day < days; day++ {
+			result.DailyProfit = append(result.DailyProfit, report.DailyProfitDto{
+				Date:   result.From.AddDate(0, 0, day),
+				Profit: util.GetDollarsByCents(dailyProfit[day]),
+			})
+		}
+	}
+	return nil
+}
+
+// sortProfitRows the most profitable first
+func sortProfitRows(rows map[string]*report.ProfitRowDto) []report.ProfitRowDto {
+	result := make([]report.ProfitRowDto, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Profit != result[j].Profit {
+			return result[i].Profit > result[j].Profit
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func getDayStart(moment time.Time) time.Time {
+	moment = moment.UTC()
+	return time.Date(moment.Year(), moment.Month(), moment.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,233 @@
+package report
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants/eventType"
+	"tradingViewWebhookBot/internal/constants/futureType"
+	"tradingViewWebhookBot/internal/constants/reportPeriod"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/dto/report"
+	"tradingViewWebhookBot/internal/notification"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/repository/memory"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/positions"
+)
+
+// testExchangeApi only the wallet balance is used by the report without opened positions
+type testExchangeApi struct {
+	api.ExchangeApi
+	balance float64
+	err     error
+}
+
+func (e *testExchangeApi) GetWalletBalance() (api.WalletBalanceDto, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &testWalletBalanceDto{e.balance}, nil
+}
+
+type testWalletBalanceDto struct {
+	availableBalance float64
+}
+
+func (d *testWalletBalanceDto) GetAvailableBalance() float64 {
+	return d.availableBalance
+}
+
+type testNotifier struct {
+	events []notification.Event
+}
+
+func (n *testNotifier) Notify(event notification.Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func newTestReportService(clock date.Clock, exchangeApi api.ExchangeApi, dailyAt string, weeklyAt string) (*PerformanceReportService, *repository.Repository, *testNotifier) {
+	repos := memory.NewRepositories(
+		[]domain.TradingStrategy{{Id: 1, Name: "Trend", Tag: "trend", Enabled: true}, {Id: 2, Name: "Scalp", Tag: "scalp", Enabled: true}},
+		[]domain.Coin{{Id: 1, Symbol: "BTCUSDT"}, {Id: 2, Symbol: "ETHUSDT"}},
+		nil)
+	notifier := &testNotifier{}
+	positionService := positions.NewPositionService(repos.TradingStrategy, repos.Transaction, repos.Coin, repos.Alert, exchangeApi, nil, clock)
+	service := NewPerformanceReportService(repos.TradingStrategy, repos.Transaction, exchangeApi, positionService, notifier, clock, dailyAt, weeklyAt)
+	return service, repos, notifier
+}
+
+// addRoundTrip closed position of the strategy, profit in cents
+func addRoundTrip(t *testing.T, repos *repository.Repository, strategyId int64, coinId int64, openedAt time.Time, closedAt time.Time, profit int64) {
+	t.Helper()
+	openTransaction := &domain.Transaction{
+		TradingStrategyId: sql.NullInt64{Int64: strategyId, Valid: true},
+		CoinId:            coinId,
+		FuturesType:       futureType.LONG,
+		Amount:            1,
+		Price:             100,
+		CreatedAt:         openedAt,
+	}
+	if err := repos.Transaction.SaveTransaction(openTransaction); err != nil {
+		t.Fatal(err)
+	}
+	closeTransaction := &domain.Transaction{
+		TradingStrategyId:    sql.NullInt64{Int64: strategyId, Valid: true},
+		CoinId:               coinId,
+		FuturesType:          futureType.LONG,
+		Amount:               1,
+		Price:                100 + float64(profit)/100,
+		Profit:               sql.NullInt64{Int64: profit, Valid: true},
+		RelatedTransactionId: sql.NullInt64{Int64: openTransaction.Id, Valid: true},
+		CreatedAt:            closedAt,
+	}
+	if err := repos.Transaction.SaveTransaction(closeTransaction); err != nil {
+		t.Fatal(err)
+	}
+	openTransaction.RelatedTransactionId = sql.NullInt64{Int64: closeTransaction.Id, Valid: true}
+	if err := repos.Transaction.SaveTransaction(openTransaction); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Date(2024, 5, 2, 0, 5, 0, 0, time.UTC)
+	service, repos, _ := newTestReportService(date.NewClockMock(now), &testExchangeApi{balance: 1234.5}, "", "")
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	addRoundTrip(t, repos, 1, 1, day.Add(-time.Hour), day.Add(-time.Minute), 700)
+	addRoundTrip(t, repos, 1, 1, day.Add(time.Hour), day.Add(2*time.Hour), 1000)
+	addRoundTrip(t, repos, 2, 2, day.Add(3*time.Hour), day.Add(4*time.Hour), -400)
+	addRoundTrip(t, repos, 2, 1, day.Add(5*time.Hour), day.Add(24*time.Hour-time.Second), 250)
+	addRoundTrip(t, repos, 1, 2, day.Add(23*time.Hour), now, 5000)
+
+	performanceReport, err := service.BuildReport(reportPeriod.DAILY, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !performanceReport.From.Equal(day) || !performanceReport.To.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("period %v - %v, expected the day %v", performanceReport.From, performanceReport.To, day)
+	}
+	if performanceReport.Trades != 3 || performanceReport.Wins != 2 {
+		t.Errorf("trades %d, wins %d, expected 3 and 2", performanceReport.Trades, performanceReport.Wins)
+	}
+	if performanceReport.RealisedProfit != 8.5 {
+		t.Errorf("realised profit %v, expected 8.5", performanceReport.RealisedProfit)
+	}
+	checkProfitRows(t, "strategies", performanceReport.Strategies, []report.ProfitRowDto{{Name: "Trend", Trades: 1, Profit: 10}, {Name: "Scalp", Trades: 2, Profit: -1.5}})
+	checkProfitRows(t, "coins", performanceReport.Coins, []report.ProfitRowDto{{Name: "BTCUSDT", Trades: 2, Profit: 12.5}, {Name: "ETHUSDT", Trades: 1, Profit: -4}})
+	if performanceReport.DailyProfit != nil {
+		t.Errorf("daily profit %v of the daily report, expected nil", performanceReport.DailyProfit)
+	}
+	if len(performanceReport.OpenedPositions) != 0 {
+		t.Errorf("opened positions %v, expected none", performanceReport.OpenedPositions)
+	}
+	if performanceReport.WalletBalance == nil || *performanceReport.WalletBalance != 1234.5 {
+		t.Errorf("wallet balance %v, expected 1234.5", performanceReport.WalletBalance)
+	}
+}
+
+func TestBuildWeeklyReport(t *testing.T) {
+	now := time.Date(2024, 5, 6, 0, 10, 0, 0, time.UTC)
+	service, repos, _ := newTestReportService(date.NewClockMock(now), &testExchangeApi{err: errors.New("not available")}, "", "")
+
+	from := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	addRoundTrip(t, repos, 1, 1, from.Add(-2*time.Hour), from.Add(-time.Hour), 999)
+	addRoundTrip(t, repos, 1, 1, from.Add(time.Hour), from.Add(2*time.Hour), 300)
+	addRoundTrip(t, repos, 2, 2, from.AddDate(0, 0, 2), from.AddDate(0, 0, 2).Add(time.Hour), -100)
+	addRoundTrip(t, repos, 1, 2, from.AddDate(0, 0, 6), from.AddDate(0, 0, 6).Add(time.Hour), 200)
+
+	performanceReport, err := service.BuildReport(reportPeriod.WEEKLY, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !performanceReport.From.Equal(from) || !performanceReport.To.Equal(from.AddDate(0, 0, 7)) {
+		t.Errorf("period %v - %v, expected the week from %v", performanceReport.From, performanceReport.To, from)
+	}
+	if performanceReport.Trades != 3 || performanceReport.RealisedProfit != 4 {
+		t.Errorf("trades %d with profit %v, expected 3 with 4", performanceReport.Trades, performanceReport.RealisedProfit)
+	}
+	expectedDailyProfit := []float64{3, 0, -1, 0, 0, 0, 2}
+	if len(performanceReport.DailyProfit) != len(expectedDailyProfit) {
+		t.Fatalf("daily profit %v, expected %v", performanceReport.DailyProfit, expectedDailyProfit)
+	}
+	for i, dailyProfit := range performanceReport.DailyProfit {
+		if !dailyProfit.Date.Equal(from.AddDate(0, 0, i)) || dailyProfit.Profit != expectedDailyProfit[i] {
+			t.Errorf("daily profit #%d %v %v, expected %v %v", i, dailyProfit.Date, dailyProfit.Profit, from.AddDate(0, 0, i), expectedDailyProfit[i])
+		}
+	}
+	if performanceReport.WalletBalance != nil {
+		t.Errorf("wallet balance %v, expected nil when the exchange is not available", *performanceReport.WalletBalance)
+	}
+}
+
+func checkProfitRows(t *testing.T, name string, rows []report.ProfitRowDto, expected []report.ProfitRowDto) {
+	t.Helper()
+	if len(rows) != len(expected) {
+		t.Fatalf("%s %+v, expected %+v", name, rows, expected)
+	}
+	for i := range rows {
+		if rows[i] != expected[i] {
+			t.Errorf("%s #%d %+v, expected %+v", name, i, rows[i], expected[i])
+		}
+	}
+}
+
+func TestCheckSchedule(t *testing.T) {
+	// 2024-05-05 is Sunday
+	clock := date.NewClockMock(time.Date(2024, 5, 5, 8, 0, 0, 0, time.UTC))
+	service, _, notifier := newTestReportService(clock, &testExchangeApi{balance: 100}, "09:00", "Mon 09:00")
+	service.scheduleNext(clock.NowTime())
+
+	steps := []struct {
+		now      time.Time
+		expected []eventType.EventType
+	}{
+		{time.Date(2024, 5, 5, 8, 59, 59, 0, time.UTC), nil},
+		{time.Date(2024, 5, 5, 9, 0, 0, 0, time.UTC), []eventType.EventType{eventType.DAILY_SUMMARY}},
+		{time.Date(2024, 5, 5, 9, 1, 0, 0, time.UTC), nil},
+		{time.Date(2024, 5, 6, 9, 0, 30, 0, time.UTC), []eventType.EventType{eventType.DAILY_SUMMARY, eventType.WEEKLY_SUMMARY}},
+		{time.Date(2024, 5, 6, 9, 1, 0, 0, time.UTC), nil},
+		// reports missed while the bot was down are sent once
+		{time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC), []eventType.EventType{eventType.DAILY_SUMMARY, eventType.WEEKLY_SUMMARY}},
+		{time.Date(2024, 5, 15, 8, 59, 0, 0, time.UTC), nil},
+		{time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC), []eventType.EventType{eventType.DAILY_SUMMARY}},
+	}
+
+	for _, step := range steps {
+		notifier.events = nil
+		clock.SetTime(step.now)
+		service.CheckSchedule()
+
+		if len(notifier.events) != len(step.expected) {
+			t.Fatalf("at %v sent %d events, expected %v", step.now, len(notifier.events), step.expected)
+		}
+		for i, event := range notifier.events {
+			if event.Type != step.expected[i] {
+				t.Errorf("at %v event #%d %s, expected %s", step.now, i, event.Type, step.expected[i])
+			}
+		}
+	}
+}
+
+func TestInvalidWeeklyScheduleDisablesReport(t *testing.T) {
+	clock := date.NewClockMock(time.Date(2024, 5, 5, 8, 0, 0, 0, time.UTC))
+	service, _, notifier := newTestReportService(clock, &testExchangeApi{balance: 100}, "", "09:00")
+	if service.weekly != nil {
+		t.Fatalf("weekly schedule %+v, expected nil without the week day", service.weekly)
+	}
+
+	service.scheduleNext(clock.NowTime())
+	for day := 0; day < 8; day++ {
+		clock.SetTime(time.Date(2024, 5, 5+day, 9, 0, 0, 0, time.UTC))
+		service.CheckSchedule()
+	}
+	if len(notifier.events) != 0 {
+		t.Errorf("sent %d events, expected none", len(notifier.events))
+	}
+}
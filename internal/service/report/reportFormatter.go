@@ -0,0 +1,64 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"tradingViewWebhookBot/internal/constants/reportPeriod"
+	"tradingViewWebhookBot/internal/dto/report"
+)
+
+// FormatReport plain text of the report, channels escape it themselves
+func FormatReport(performanceReport *report.PerformanceReportDto) string {
+	var text strings.Builder
+	if performanceReport.Period == reportPeriod.WEEKLY {
+		fmt.Fprintf(&text, "Period: %s - %s UTC\n", performanceReport.From.Format("2006-01-02"),
+			performanceReport.To.AddDate(0, 0, -1).Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(&text, "Day: %s UTC\n", performanceReport.From.Format("2006-01-02"))
+	}
+
+	fmt.Fprintf(&text, "Trades: %d, wins: %d (%.1f%%)\n", performanceReport.Trades, performanceReport.Wins, performanceReport.WinRate)
+	fmt.Fprintf(&text, "Realised P&L: %s\n", formatUsd(performanceReport.RealisedProfit))
+	fmt.Fprintf(&text, "Fees and funding: %s\n", formatUsd(performanceReport.Fees))
+	if performanceReport.WalletBalance != nil {
+		fmt.Fprintf(&text, "Wallet balance: %s\n", formatUsd(*performanceReport.WalletBalance))
+	}
+
+	if len(performanceReport.Strategies) > 0 {
+		text.WriteString("\nBy strategy:\n")
+		for _, row := range performanceReport.Strategies {
+			fmt.Fprintf(&text, "  %s: %s (%d trades)\n", row.Name, formatUsd(row.Profit), row.Trades)
+		}
+	}
+	if len(performanceReport.Coins) > 0 {
+		text.WriteString("\nBy coin:\n")
+		for _, row := range performanceReport.Coins {
+			fmt.Fprintf(&text, "  %s: %s (%d trades)\n", row.Name, formatUsd(row.Profit), row.Trades)
+		}
+	}
+	if len(performanceReport.DailyProfit) > 0 {
+		text.WriteString("\nBy day:\n")
+		for _, day := range performanceReport.DailyProfit {
+			fmt.Fprintf(&text, "  %s: %s\n", day.Date.Format("Mon 01-02"), formatUsd(day.Profit))
+		}
+	}
+
+	fmt.Fprintf(&text, "\nOpened positions: %d, unrealised P&L: %s\n", len(performanceReport.OpenedPositions),
+		formatUsd(performanceReport.UnrealisedProfit))
+	for _, position := range performanceReport.OpenedPositions {
+		if position.PriceError != "" {
+			fmt.Fprintf(&text, "  %s %s %s: price is not available\n", position.TradingStrategyName, position.CoinSymbol, position.Side)
+			continue
+		}
+		fmt.Fprintf(&text, "  %s %s %s: %s (%+.2f%%)\n", position.TradingStrategyName, position.CoinSymbol, position.Side,
+			formatUsd(position.UnrealisedProfit), position.UnrealisedPercent)
+	}
+	return strings.TrimRight(text.String(), "\n")
+}
+
+func formatUsd(value float64) string {
+	if value < 0 {
+		return fmt.Sprintf("-$%.2f", -value)
+	}
+	return fmt.Sprintf("$%.2f", value)
+}
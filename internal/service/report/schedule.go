@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// schedule UTC time of the day, weekly schedule also has the week day
+type schedule struct {
+	weekday *time.Weekday
+	hour    int
+	minute  int
+}
+
+// parseSchedule "HH:MM" every day or "Mon HH:MM" every week, empty - disabled (nil).
+// Weekly schedule without the week day would send the weekly report every day, so it is rejected.
+func parseSchedule(value string, weekly bool) (*schedule, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if weekly && len(fields) != 2 {
+		return nil, fmt.Errorf("invalid weekly schedule %q, expected Mon HH:MM", value)
+	}
+	if !weekly && len(fields) != 1 {
+		return nil, fmt.Errorf("invalid daily schedule %q, expected HH:MM", value)
+	}
+
+	result := &schedule{}
+	if weekly {
+		weekday, err := parseWeekday(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		result.weekday = &weekday
+	}
+
+	moment, err := time.Parse("15:04", fields[len(fields)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q of schedule, expected HH:MM", fields[len(fields)-1])
+	}
+	result.hour = moment.Hour()
+	result.minute = moment.Minute()
+	return result, nil
+}
+
+// parseWeekday short English name, e.g. Mon, time.Parse ignores the week day
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String()[:3], value) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid week day %q of schedule, expected Mon, Tue, ...", value)
+}
+
+// next scheduled moment after now
+func (s *schedule) next(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.hour, s.minute, 0, 0, time.UTC)
+	if s.weekday != nil {
+		next = next.AddDate(0, 0, (int(*s.weekday)-int(next.Weekday())+7)%7)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
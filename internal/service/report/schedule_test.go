@@ -0,0 +1,90 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	monday := time.Monday
+	tests := []struct {
+		value    string
+		weekly   bool
+		expected *schedule
+		isError  bool
+	}{
+		{"", false, nil, false},
+		{"", true, nil, false},
+		{"00:05", false, &schedule{hour: 0, minute: 5}, false},
+		{" 23:59 ", false, &schedule{hour: 23, minute: 59}, false},
+		{"Mon 00:10", true, &schedule{weekday: &monday, hour: 0, minute: 10}, false},
+		{"mon 00:10", true, &schedule{weekday: &monday, hour: 0, minute: 10}, false},
+		{"00:10", true, nil, true},
+		{"Mon 00:10", false, nil, true},
+		{"Monday 00:10", true, nil, true},
+		{"Mon Tue 00:10", true, nil, true},
+		{"24:00", false, nil, true},
+		{"Mon 9am", true, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, err := parseSchedule(test.value, test.weekly)
+			if test.isError {
+				if err == nil {
+					t.Fatalf("parseSchedule(%q, %v) = %+v, expected error", test.value, test.weekly, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSchedule(%q, %v) error: %s", test.value, test.weekly, err.Error())
+			}
+			if !equalSchedules(result, test.expected) {
+				t.Errorf("parseSchedule(%q, %v) = %+v, expected %+v", test.value, test.weekly, result, test.expected)
+			}
+		})
+	}
+}
+
+func equalSchedules(first *schedule, second *schedule) bool {
+	if first == nil || second == nil {
+		return first == second
+	}
+	if (first.weekday == nil) != (second.weekday == nil) || (first.weekday != nil && *first.weekday != *second.weekday) {
+		return false
+	}
+	return first.hour == second.hour && first.minute == second.minute
+}
+
+func TestScheduleNext(t *testing.T) {
+	daily, _ := parseSchedule("09:30", false)
+	weekly, _ := parseSchedule("Mon 09:30", true)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	// 2024-05-06 is Monday
+	tests := []struct {
+		name     string
+		schedule *schedule
+		now      time.Time
+		expected time.Time
+	}{
+		{"daily before", daily, time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC), time.Date(2024, 5, 6, 9, 30, 0, 0, time.UTC)},
+		{"daily at", daily, time.Date(2024, 5, 6, 9, 30, 0, 0, time.UTC), time.Date(2024, 5, 7, 9, 30, 0, 0, time.UTC)},
+		{"daily after", daily, time.Date(2024, 5, 6, 23, 59, 0, 0, time.UTC), time.Date(2024, 5, 7, 9, 30, 0, 0, time.UTC)},
+		{"daily end of month", daily, time.Date(2024, 5, 31, 10, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)},
+		{"daily not UTC", daily, time.Date(2024, 5, 7, 1, 0, 0, 0, moscow), time.Date(2024, 5, 7, 9, 30, 0, 0, time.UTC)},
+		{"weekly before on the day", weekly, time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC), time.Date(2024, 5, 6, 9, 30, 0, 0, time.UTC)},
+		{"weekly at", weekly, time.Date(2024, 5, 6, 9, 30, 0, 0, time.UTC), time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC)},
+		{"weekly after on the day", weekly, time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC), time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC)},
+		{"weekly on sunday", weekly, time.Date(2024, 5, 12, 23, 0, 0, 0, time.UTC), time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC)},
+		{"weekly on tuesday", weekly, time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if next := test.schedule.next(test.now); !next.Equal(test.expected) {
+				t.Errorf("next(%v) = %v, expected %v", test.now, next, test.expected)
+			}
+		})
+	}
+}
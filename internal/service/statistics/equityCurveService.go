@@ -0,0 +1,123 @@
+package statistics
+
+import (
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/constants/bucketInterval"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/statistic"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/util"
+)
+
+func NewEquityCurveService(transactionRepo repository.Transaction) *EquityCurveService {
+	return &EquityCurveService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+// EquityCurveService buckets realised P&L in Go instead of date_trunc, so day boundaries follow the requested timezone, not the DB one
+type EquityCurveService struct {
+	transactionRepo repository.Transaction
+}
+
+// GetEquityCurve returns buckets of the whole strategy, or a separate curve per coin when groupByCoin is set
+func (s *EquityCurveService) GetEquityCurve(
+	filter transaction.RoundTripFilter,
+	interval bucketInterval.BucketInterval,
+	location *time.Location,
+	groupByCoin bool,
+) ([]statistic.EquityPointDto, error) {
+	roundTrips, err := s.transactionRepo.FindRoundTrips(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(roundTrips) == 0 {
+		return []statistic.EquityPointDto{}, nil
+	}
+
+	from, to := getPeriod(roundTrips, filter.From, filter.To)
+	if !groupByCoin {
+		return CalculateEquityCurve(roundTrips, interval, location, from, to, ""), nil
+	}
+
+	roundTripsByCoin := make(map[string][]transaction.RoundTripDto)
+	for _, roundTrip := range roundTrips {
+		roundTripsByCoin[roundTrip.CoinSymbol] = append(roundTripsByCoin[roundTrip.CoinSymbol], roundTrip)
+	}
+	symbols := make([]string, 0, len(roundTripsByCoin))
+	for symbol := range roundTripsByCoin {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var result []statistic.EquityPointDto
+	for _, symbol := range symbols {
+		result = append(result, CalculateEquityCurve(roundTripsByCoin[symbol], interval, location, from, to, symbol)...)
+	}
+	return result, nil
+}
+
+// CalculateEquityCurve round trips must be sorted by close time, buckets without trades are kept to have a continuous curve
+func CalculateEquityCurve(
+	roundTrips []transaction.RoundTripDto,
+	interval bucketInterval.BucketInterval,
+	location *time.Location,
+	from time.Time,
+	to time.Time,
+	coinSymbol string,
+) []statistic.EquityPointDto {
+	var result []statistic.EquityPointDto
+	var equity, peakEquity float64
+
+	index := 0
+	lastBucket := GetBucketStart(to, interval, location)
+	for bucket := GetBucketStart(from, interval, location); !bucket.After(lastBucket); bucket = nextBucketStart(bucket, interval) {
+		point := statistic.EquityPointDto{
+			Period:     bucket,
+			CoinSymbol: coinSymbol,
+		}
+
+		nextBucket := nextBucketStart(bucket, interval)
+		for index < len(roundTrips) && roundTrips[index].ClosedAt.Before(nextBucket) {
+			point.Profit += util.GetDollarsByCents(roundTrips[index].Profit)
+			point.TradesCount++
+			index++
+		}
+
+		equity += point.Profit
+		peakEquity = util.Max(peakEquity, equity)
+
+		point.Profit = roundCents(point.Profit)
+		point.Equity = roundCents(equity)
+		point.Drawdown = roundCents(peakEquity - equity)
+		result = append(result, point)
+	}
+	return result
+}
+
+// GetBucketStart beginning of the day, the week (Monday) or the month of the moment in the location
+func GetBucketStart(moment time.Time, interval bucketInterval.BucketInterval, location *time.Location) time.Time {
+	local := moment.In(location)
+	switch interval {
+	case bucketInterval.WEEK:
+		daysSinceMonday := (int(local.Weekday()) + 6) % 7
+		return time.Date(local.Year(), local.Month(), local.Day()-daysSinceMonday, 0, 0, 0, 0, location)
+	case bucketInterval.MONTH:
+		return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, location)
+	default:
+		return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	}
+}
+
+// nextBucketStart uses calendar arithmetic, so days with DST changes keep their real length
+func nextBucketStart(bucket time.Time, interval bucketInterval.BucketInterval) time.Time {
+	switch interval {
+	case bucketInterval.WEEK:
+		return bucket.AddDate(0, 0, 7)
+	case bucketInterval.MONTH:
+		return bucket.AddDate(0, 1, 0)
+	default:
+		return bucket.AddDate(0, 0, 1)
+	}
+}
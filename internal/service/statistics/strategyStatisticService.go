@@ -0,0 +1,227 @@
+package statistics
+
+import (
+	"math"
+	"sort"
+	"time"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/statistic"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/util"
+)
+
+// tradingDaysPerYear crypto is traded every day
+const tradingDaysPerYear = 365
+
+func NewStrategyStatisticService(transactionRepo repository.Transaction) *StrategyStatisticService {
+	return &StrategyStatisticService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+type StrategyStatisticService struct {
+	transactionRepo repository.Transaction
+}
+
+func (s *StrategyStatisticService) GetStatistic(filter transaction.RoundTripFilter) (*statistic.StrategyStatisticDto, error) {
+	roundTrips, err := s.transactionRepo.FindRoundTrips(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := CalculateStatistic(roundTrips, filter.From, filter.To)
+	result.TradingStrategyId = filter.TradingStrategyId
+	return result, nil
+}
+
+// CalculateStatistic round trips must be sorted by close time.
+// Period is limited by from and to, or by the first open and the last close when they are not set.
+func CalculateStatistic(roundTrips []transaction.RoundTripDto, from *time.Time, to *time.Time) *statistic.StrategyStatisticDto {
+	result := &statistic.StrategyStatisticDto{}
+	if len(roundTrips) == 0 {
+		return result
+	}
+
+	result.From, result.To = getPeriod(roundTrips, from, to)
+
+	var sumOfWins, sumOfLosses, equity, peakEquity float64
+	losingStreak := 0
+	for _, roundTrip := range roundTrips {
+		profit := util.GetDollarsByCents(roundTrip.Profit)
+
+		result.TradesCount++
+		result.GrossProfit += util.GetDollarsByCents(roundTrip.GrossProfit)
+		result.NetProfit += profit
+		result.Fees += roundTrip.OpenFee + roundTrip.CloseFee
+		result.Funding += roundTrip.FundingFee
+
+		// breakeven trade neither extends nor breaks the losing streak
+		switch {
+		case profit > 0:
+			result.WinsCount++
+			sumOfWins += profit
+			losingStreak = 0
+		case profit == 0:
+			result.BreakevenCount++
+		default:
+			result.LossesCount++
+			sumOfLosses += -profit
+			losingStreak++
+			if losingStreak > result.LongestLosingStreak {
+				result.LongestLosingStreak = losingStreak
+			}
+		}
+
+		equity += profit
+		peakEquity = util.Max(peakEquity, equity)
+		result.MaxDrawdown = util.Max(result.MaxDrawdown, peakEquity-equity)
+	}
+
+	result.WinRate = roundPercent(float64(result.WinsCount) / float64(result.TradesCount) * 100)
+	result.Expectancy = roundCents(result.NetProfit / float64(result.TradesCount))
+	if result.WinsCount > 0 {
+		result.AverageWin = roundCents(sumOfWins / float64(result.WinsCount))
+	}
+	if result.LossesCount > 0 {
+		result.AverageLoss = roundCents(-sumOfLosses / float64(result.LossesCount))
+	}
+	if sumOfLosses > 0 {
+		profitFactor := roundPercent(sumOfWins / sumOfLosses)
+		result.ProfitFactor = &profitFactor
+	}
+
+	dailyReturns := getDailyReturns(roundTrips, result.From, result.To)
+	result.SharpeRatio = roundPercent(calculateSharpeRatio(dailyReturns))
+	result.SortinoRatio = roundPercent(calculateSortinoRatio(dailyReturns))
+
+	exposure := calculateExposure(roundTrips, result.From, result.To)
+	result.ExposureSeconds = int64(exposure.Seconds())
+	if period := result.To.Sub(result.From); period > 0 {
+		result.ExposurePercent = roundPercent(float64(exposure) / float64(period) * 100)
+	}
+
+	result.GrossProfit = roundCents(result.GrossProfit)
+	result.NetProfit = roundCents(result.NetProfit)
+	result.Fees = roundCents(result.Fees)
+	result.Funding = roundCents(result.Funding)
+	result.MaxDrawdown = roundCents(result.MaxDrawdown)
+	return result
+}
+
+func getPeriod(roundTrips []transaction.RoundTripDto, from *time.Time, to *time.Time) (time.Time, time.Time) {
+	var periodFrom, periodTo time.Time
+	if from != nil {
+		periodFrom = *from
+	} else {
+		periodFrom = roundTrips[0].OpenedAt
+		for _, roundTrip := range roundTrips {
+			if roundTrip.OpenedAt.Before(periodFrom) {
+				periodFrom = roundTrip.OpenedAt
+			}
+		}
+	}
+	if to != nil {
+		periodTo = *to
+	} else {
+		periodTo = roundTrips[len(roundTrips)-1].ClosedAt
+	}
+	return periodFrom, periodTo
+}
+
+// getDailyReturns sum of percent profit of round trips closed each day, days without trades have zero return
+func getDailyReturns(roundTrips []transaction.RoundTripDto, from time.Time, to time.Time) []float64 {
+	returnsByDay := make(map[string]float64)
+	for _, roundTrip := range roundTrips {
+		returnsByDay[roundTrip.ClosedAt.Format(constants.DATE_FORMAT)] += roundTrip.PercentProfit / 100
+	}
+
+	var dailyReturns []float64
+	lastDay := to.Format(constants.DATE_FORMAT)
+	for day := from; ; day = day.AddDate(0, 0, 1) {
+		dayKey := day.Format(constants.DATE_FORMAT)
+		dailyReturns = append(dailyReturns, returnsByDay[dayKey])
+		if dayKey >= lastDay {
+			break
+		}
+	}
+	return dailyReturns
+}
+
+// calculateSharpeRatio annualized, risk-free rate is zero
+func calculateSharpeRatio(dailyReturns []float64) float64 {
+	if len(dailyReturns) < 2 {
+		return 0
+	}
+	deviation := util.StandardDeviation(dailyReturns)
+	if deviation == 0 {
+		return 0
+	}
+	average := util.SumFloat64(dailyReturns) / float64(len(dailyReturns))
+	return average / deviation * math.Sqrt(tradingDaysPerYear)
+}
+
+// calculateSortinoRatio annualized, only negative returns are counted as risk
+func calculateSortinoRatio(dailyReturns []float64) float64 {
+	if len(dailyReturns) < 2 {
+		return 0
+	}
+	sumOfSquares := float64(0)
+	for _, dailyReturn := range dailyReturns {
+		if dailyReturn < 0 {
+			sumOfSquares += dailyReturn * dailyReturn
+		}
+	}
+	downsideDeviation := math.Sqrt(sumOfSquares / float64(len(dailyReturns)))
+	if downsideDeviation == 0 {
+		return 0
+	}
+	average := util.SumFloat64(dailyReturns) / float64(len(dailyReturns))
+	return average / downsideDeviation * math.Sqrt(tradingDaysPerYear)
+}
+
+// calculateExposure time with at least one opened position within the period, overlapping positions are counted once
+func calculateExposure(roundTrips []transaction.RoundTripDto, from time.Time, to time.Time) time.Duration {
+	type interval struct{ start, end time.Time }
+	intervals := make([]interval, 0, len(roundTrips))
+	for _, roundTrip := range roundTrips {
+		start, end := roundTrip.OpenedAt, roundTrip.ClosedAt
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			intervals = append(intervals, interval{start, end})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	var exposure time.Duration
+	var current interval
+	for i, next := range intervals {
+		if i == 0 {
+			current = next
+			continue
+		}
+		if next.start.After(current.end) {
+			exposure += current.end.Sub(current.start)
+			current = next
+		} else if next.end.After(current.end) {
+			current.end = next.end
+		}
+	}
+	if len(intervals) > 0 {
+		exposure += current.end.Sub(current.start)
+	}
+	return exposure
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,166 @@
+package strategy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"tradingViewWebhookBot/internal/domain"
+	strategyDto "tradingViewWebhookBot/internal/dto/strategy"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/filters"
+	"tradingViewWebhookBot/internal/service/watchdog"
+)
+
+var (
+	ErrNotFound         = errors.New("strategy not found")
+	ErrInvalid          = errors.New("strategy is not valid")
+	ErrTagAlreadyExists = errors.New("strategy with this tag already exists")
+	ErrOpenedPositions  = errors.New("strategy has opened positions")
+	ErrHasTransactions  = errors.New("strategy has transactions")
+)
+
+func NewTradingStrategyService(
+	strategyRepo repository.TradingStrategy,
+	transactionRepo repository.Transaction,
+	coinRepo repository.Coin,
+) *TradingStrategyService {
+	return &TradingStrategyService{
+		strategyRepo:    strategyRepo,
+		transactionRepo: transactionRepo,
+		coinRepo:        coinRepo,
+	}
+}
+
+type TradingStrategyService struct {
+	strategyRepo    repository.TradingStrategy
+	transactionRepo repository.Transaction
+	coinRepo        repository.Coin
+}
+
+func (s *TradingStrategyService) Create(request strategyDto.TradingStrategyRequestDto) (*domain.TradingStrategy, error) {
+	strategy := &domain.TradingStrategy{Enabled: true}
+	if err := s.apply(strategy, request); err != nil {
+		return nil, err
+	}
+
+	if err := s.strategyRepo.Create(strategy); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+func (s *TradingStrategyService) Update(id int64, request strategyDto.TradingStrategyRequestDto) (*domain.TradingStrategy, error) {
+	strategy, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(strategy, request); err != nil {
+		return nil, err
+	}
+
+	if err := s.strategyRepo.Update(strategy); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+func (s *TradingStrategyService) SetEnabled(id int64, enabled bool) (*domain.TradingStrategy, error) {
+	strategy, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy.Enabled = enabled
+	if err := s.strategyRepo.Update(strategy); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+// Delete refuses while the strategy has opened positions, they would be left without anyone to close them,
+// and when it has any transactions, as their history is needed for the statistics and the tax export
+func (s *TradingStrategyService) Delete(id int64) error {
+	strategy, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	openedTransactions, err := s.transactionRepo.FindAllOpenedTransactions(*strategy)
+	if err != nil {
+		return err
+	}
+	if len(openedTransactions) > 0 {
+		return fmt.Errorf("%w: %d opened, close them or disable the strategy", ErrOpenedPositions, len(openedTransactions))
+	}
+
+	hasTransactions, err := s.transactionRepo.ExistsByStrategy(id)
+	if err != nil {
+		return err
+	}
+	if hasTransactions {
+		return fmt.Errorf("%w: %s, disable it instead", ErrHasTransactions, strategy.Tag)
+	}
+
+	return s.strategyRepo.Delete(id)
+}
+
+func (s *TradingStrategyService) Get(id int64) (*domain.TradingStrategy, error) {
+	strategy, err := s.strategyRepo.GetByID(id)
+	if err != nil || strategy == nil {
+		return nil, ErrNotFound
+	}
+	return strategy, nil
+}
+
+func (s *TradingStrategyService) List() ([]domain.TradingStrategy, error) {
+	return s.strategyRepo.List()
+}
+
+func (s *TradingStrategyService) apply(strategy *domain.TradingStrategy, request strategyDto.TradingStrategyRequestDto) error {
+	tagExists, err := s.strategyRepo.ExistsByTag(request.Tag, strategy.Id)
+	if err != nil {
+		return err
+	}
+	if tagExists {
+		return fmt.Errorf("%w: %s", ErrTagAlreadyExists, request.Tag)
+	}
+
+	if err := watchdog.ValidateSessionEnd(request.SessionEndTimes, request.SessionEndDays); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
+
+	allowedSymbols := make([]string, 0, len(request.AllowedSymbols))
+	for _, symbol := range request.AllowedSymbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if coin, err := s.coinRepo.FindBySymbol(symbol); err != nil || coin == nil {
+			return fmt.Errorf("%w: unknown symbol %s", ErrInvalid, symbol)
+		}
+		allowedSymbols = append(allowedSymbols, symbol)
+	}
+
+	if err := filters.ValidateAlertFilters(request.AlertFilters); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
+	alertFilters := ""
+	if len(request.AlertFilters) > 0 {
+		marshaled, err := json.Marshal(request.AlertFilters)
+		if err != nil {
+			return err
+		}
+		alertFilters = string(marshaled)
+	}
+
+	strategy.Name = request.Name
+	strategy.Description = request.Description
+	strategy.Tag = request.Tag
+	if request.Enabled != nil {
+		strategy.Enabled = *request.Enabled
+	}
+	strategy.MaxHoldingMinutes = request.MaxHoldingMinutes
+	strategy.SessionEndTimes = request.SessionEndTimes
+	strategy.SessionEndDays = request.SessionEndDays
+	strategy.AllowedSymbols = strings.Join(allowedSymbols, ",")
+	strategy.AlertFilters = alertFilters
+	return nil
+}
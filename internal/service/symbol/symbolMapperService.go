@@ -0,0 +1,122 @@
+package symbol
+
+import (
+	"fmt"
+	"strings"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+)
+
+// perpetualSuffixes TradingView marks perpetual contracts, the exchange symbol doesn't have them
+var perpetualSuffixes = []string{".P", ".PERP"}
+
+// quoteCurrencies "PERP" is stripped only after a quote currency: BTCUSDTPERP is BTCUSDT, but BTCPERP is a real Bybit symbol
+var quoteCurrencies = []string{"USDT", "USDC", "USD"}
+
+func NewSymbolMapperService(coinRepo repository.Coin, coinAliasRepo repository.CoinAlias) *SymbolMapperService {
+	return &SymbolMapperService{
+		coinRepo:      coinRepo,
+		coinAliasRepo: coinAliasRepo,
+	}
+}
+
+// SymbolMapperService maps TradingView tickers (BYBIT:BTCUSDT.P, BTCUSDTPERP, BTC/USDT) to coins.
+// Aliases are checked first, so a wrong normalisation can always be overridden without deploy.
+type SymbolMapperService struct {
+	coinRepo      repository.Coin
+	coinAliasRepo repository.CoinAlias
+}
+
+func (s *SymbolMapperService) FindCoin(ticker string) (*domain.Coin, error) {
+	exchange, rawSymbol := SplitExchange(ticker)
+	normalized := NormalizeSymbol(ticker)
+
+	for _, alias := range uniqueNonEmpty(rawSymbol, normalized) {
+		coinAlias, err := s.coinAliasRepo.FindByExchangeAndAlias(exchange, alias)
+		if err != nil {
+			return nil, err
+		}
+		if coinAlias != nil {
+			return s.coinRepo.FindById(coinAlias.CoinId)
+		}
+	}
+
+	for _, symbol := range uniqueNonEmpty(rawSymbol, normalized) {
+		if coin, err := s.coinRepo.FindBySymbol(symbol); err == nil && coin != nil {
+			return coin, nil
+		}
+	}
+
+	return nil, fmt.Errorf("coin not found for ticker: %s", ticker)
+}
+
+// SplitExchange "BYBIT:BTCUSDT.P" -> "BYBIT", "BTCUSDT.P"
+func SplitExchange(ticker string) (string, string) {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	if index := strings.Index(ticker, ":"); index >= 0 {
+		return ticker[:index], ticker[index+1:]
+	}
+	return "", ticker
+}
+
+// NormalizeSymbol TradingView ticker to the exchange symbol by rules: exchange prefix, perpetual suffixes and separators are removed
+func NormalizeSymbol(ticker string) string {
+	_, symbol := SplitExchange(ticker)
+
+	for _, suffix := range perpetualSuffixes {
+		symbol = strings.TrimSuffix(symbol, suffix)
+	}
+	symbol = strings.NewReplacer("/", "", "-", "", "_", "").Replace(symbol)
+
+	if withoutPerp := strings.TrimSuffix(symbol, "PERP"); withoutPerp != symbol {
+		for _, quote := range quoteCurrencies {
+			if strings.HasSuffix(withoutPerp, quote) {
+				return withoutPerp
+			}
+		}
+	}
+	return symbol
+}
+
+func uniqueNonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		duplicate := false
+		for _, added := range result {
+			duplicate = duplicate || added == value
+		}
+		if !duplicate {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func (s *SymbolMapperService) ListAliases() ([]domain.CoinAlias, error) {
+	return s.coinAliasRepo.FindAll()
+}
+
+// AddAlias alias is stored without the exchange prefix, upper case as TradingView sends it
+func (s *SymbolMapperService) AddAlias(exchange string, alias string, coinSymbol string) (*domain.CoinAlias, error) {
+	coin, err := s.coinRepo.FindBySymbol(strings.ToUpper(strings.TrimSpace(coinSymbol)))
+	if err != nil || coin == nil {
+		return nil, fmt.Errorf("coin not found: %s", coinSymbol)
+	}
+
+	coinAlias := &domain.CoinAlias{
+		Exchange: strings.ToUpper(strings.TrimSpace(exchange)),
+		Alias:    strings.ToUpper(strings.TrimSpace(alias)),
+		CoinId:   coin.Id,
+	}
+	if err := s.coinAliasRepo.Create(coinAlias); err != nil {
+		return nil, err
+	}
+	return coinAlias, nil
+}
+
+func (s *SymbolMapperService) RemoveAlias(id int64) error {
+	return s.coinAliasRepo.Delete(id)
+}
@@ -0,0 +1,102 @@
+package symbol
+
+import (
+	"testing"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository/memory"
+)
+
+func TestSplitExchange(t *testing.T) {
+	tests := []struct {
+		ticker   string
+		exchange string
+		symbol   string
+	}{
+		{"BYBIT:BTCUSDT.P", "BYBIT", "BTCUSDT.P"},
+		{"BTCUSDT.P", "", "BTCUSDT.P"},
+		{"bybit:btcusdt", "BYBIT", "BTCUSDT"},
+		{" BINANCE:ETHUSDT ", "BINANCE", "ETHUSDT"},
+		{":BTCUSDT", "", "BTCUSDT"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ticker, func(t *testing.T) {
+			exchange, symbol := SplitExchange(test.ticker)
+			if exchange != test.exchange || symbol != test.symbol {
+				t.Errorf("SplitExchange(%q) = %q, %q, expected %q, %q", test.ticker, exchange, symbol, test.exchange, test.symbol)
+			}
+		})
+	}
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	tests := []struct {
+		ticker string
+		symbol string
+	}{
+		{"BYBIT:BTCUSDT.P", "BTCUSDT"},
+		{"BTCUSDT.P", "BTCUSDT"},
+		{"BTCUSDT.PERP", "BTCUSDT"},
+		{"BTCUSDTPERP", "BTCUSDT"},
+		{"ETHUSDCPERP", "ETHUSDC"},
+		{"BTCUSDPERP", "BTCUSD"},
+		{"BTCPERP", "BTCPERP"},
+		{"BYBIT:BTCPERP", "BTCPERP"},
+		{"BTC/USDT", "BTCUSDT"},
+		{"BTC-USDT", "BTCUSDT"},
+		{"BTC_USDT", "BTCUSDT"},
+		{"btcusdt.p", "BTCUSDT"},
+		{"bybit:eth/usdt", "ETHUSDT"},
+		{"BTCUSDT", "BTCUSDT"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ticker, func(t *testing.T) {
+			if symbol := NormalizeSymbol(test.ticker); symbol != test.symbol {
+				t.Errorf("NormalizeSymbol(%q) = %q, expected %q", test.ticker, symbol, test.symbol)
+			}
+		})
+	}
+}
+
+func TestFindCoin(t *testing.T) {
+	coinRepo := memory.NewCoinRepository([]domain.Coin{
+		{Id: 1, Symbol: "BTCUSDT"},
+		{Id: 2, Symbol: "BTCPERP"},
+		{Id: 3, Symbol: "1000PEPEUSDT"},
+	})
+	coinAliasRepo := memory.NewCoinAliasRepository([]domain.CoinAlias{
+		{Id: 1, Exchange: "", Alias: "PEPEUSDT", CoinId: 3},
+		{Id: 2, Exchange: "BINANCE", Alias: "BTCUSDT", CoinId: 2},
+	})
+	service := NewSymbolMapperService(coinRepo, coinAliasRepo)
+
+	tests := []struct {
+		ticker string
+		coinId int64
+	}{
+		{"BYBIT:BTCUSDT.P", 1},
+		{"BTCPERP", 2},
+		{"PEPEUSDT.P", 3},
+		{"BYBIT:PEPEUSDT", 3},
+		// the alias of the exchange overrides the normalized symbol
+		{"BINANCE:BTCUSDT", 2},
+		{"ETHUSDT", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ticker, func(t *testing.T) {
+			coin, err := service.FindCoin(test.ticker)
+			if test.coinId == 0 {
+				if err == nil {
+					t.Errorf("FindCoin(%q) = %v, error expected", test.ticker, coin)
+				}
+				return
+			}
+			if err != nil || coin.Id != test.coinId {
+				t.Errorf("FindCoin(%q) = %v, %v, coin %d expected", test.ticker, coin, err, test.coinId)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package watchdog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/constants"
+	"tradingViewWebhookBot/internal/constants/exitReason"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/orders"
+
+	"go.uber.org/zap"
+)
+
+// sessionLookbackDays how far back session ends are searched when the bot was down for a while
+const sessionLookbackDays = 7
+
+func NewPositionWatchdogService(
+	strategyRepo repository.TradingStrategy,
+	transactionRepo repository.Transaction,
+	coinRepo repository.Coin,
+	exchangeApi api.ExchangeApi,
+	orderManagerService *orders.OrderManagerService,
+	clock date.Clock,
+	interval time.Duration,
+) *PositionWatchdogService {
+	return &PositionWatchdogService{
+		strategyRepo:        strategyRepo,
+		transactionRepo:     transactionRepo,
+		coinRepo:            coinRepo,
+		exchangeApi:         exchangeApi,
+		orderManagerService: orderManagerService,
+		clock:               clock,
+		interval:            interval,
+	}
+}
+
+// PositionWatchdogService closes positions which exceeded the max holding period
+// or stayed opened after the session end of their strategy.
+// It protects from positions left open forever when the exit alert is lost.
+type PositionWatchdogService struct {
+	strategyRepo        repository.TradingStrategy
+	transactionRepo     repository.Transaction
+	coinRepo            repository.Coin
+	exchangeApi         api.ExchangeApi
+	orderManagerService *orders.OrderManagerService
+	clock               date.Clock
+	interval            time.Duration
+}
+
+func (s *PositionWatchdogService) Start() {
+	if s.interval <= 0 {
+		zap.S().Info("Position watchdog is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.CheckOpenedPositions()
+		}
+	}()
+	zap.S().Infof("Position watchdog started with interval %v", s.interval)
+}
+
+func (s *PositionWatchdogService) CheckOpenedPositions() {
+	strategies, err := s.strategyRepo.List()
+	if err != nil {
+		zap.S().Errorf("Error during strategies List: %s", err.Error())
+		return
+	}
+
+	now := s.clock.NowTime()
+	for i := range strategies {
+		strategy := &strategies[i]
+		if !strategy.HasTimeExits() {
+			continue
+		}
+
+		openedTransactions, err := s.transactionRepo.FindAllOpenedTransactions(*strategy)
+		if err != nil {
+			zap.S().Errorf("Error during FindAllOpenedTransactions for strategy %s: %s", strategy.Tag, err.Error())
+			continue
+		}
+
+		for _, openedTransaction := range openedTransactions {
+			reason, shouldClose := GetTimeExitReason(strategy, openedTransaction, now)
+			if !shouldClose {
+				continue
+			}
+			s.closePosition(strategy, openedTransaction, reason)
+		}
+	}
+}
+
+func (s *PositionWatchdogService) closePosition(strategy *domain.TradingStrategy, openedTransaction *domain.Transaction, reason exitReason.ExitReason) {
+	coin, err := s.coinRepo.FindById(openedTransaction.CoinId)
+	if err != nil {
+		zap.S().Errorf("Error during FindById coin %d: %s", openedTransaction.CoinId, err.Error())
+		return
+	}
+
+	currentPrice, err := s.exchangeApi.GetCurrentCoinPrice(coin)
+	if err != nil {
+		zap.S().Errorf("Error during GetCurrentCoinPrice at %v: %s", s.clock.NowTime(), err.Error())
+		return
+	}
+
+	zap.S().Infof("Watchdog closes transaction %d of strategy %s [%s] by %s", openedTransaction.Id, strategy.Tag, coin.Symbol, reason)
+	s.orderManagerService.CloseOrder(strategy, openedTransaction, coin, currentPrice, constants.FUTURES, reason, nil)
+}
+
+// GetTimeExitReason checks if the opened transaction has to be closed at the given moment
+func GetTimeExitReason(strategy *domain.TradingStrategy, openedTransaction *domain.Transaction, now time.Time) (exitReason.ExitReason, bool) {
+	if strategy.MaxHoldingMinutes > 0 {
+		maxHolding := time.Duration(strategy.MaxHoldingMinutes) * time.Minute
+		if now.Sub(openedTransaction.CreatedAt) >= maxHolding {
+			return exitReason.MAX_HOLDING_PERIOD, true
+		}
+	}
+
+	if strategy.SessionEndTimes != "" {
+		sessionEnd, found, err := findLastSessionEnd(strategy, now)
+		if err != nil {
+			zap.S().Errorf("Invalid session end of strategy %s: %s", strategy.Tag, err.Error())
+			return "", false
+		}
+		if found && openedTransaction.CreatedAt.Before(sessionEnd) {
+			return exitReason.SESSION_END, true
+		}
+	}
+
+	return "", false
+}
+
+// findLastSessionEnd returns the latest session end which is not after now
+func findLastSessionEnd(strategy *domain.TradingStrategy, now time.Time) (time.Time, bool, error) {
+	sessionTimes, err := parseSessionTimes(strategy.SessionEndTimes)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	sessionDays, err := parseSessionDays(strategy.SessionEndDays)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	nowUtc := now.UTC()
+	for daysAgo := 0; daysAgo <= sessionLookbackDays; daysAgo++ {
+		day := nowUtc.AddDate(0, 0, -daysAgo)
+		if len(sessionDays) > 0 && !sessionDays[day.Weekday()] {
+			continue
+		}
+
+		var latest time.Time
+		for _, sessionTime := range sessionTimes {
+			sessionEnd := time.Date(day.Year(), day.Month(), day.Day(), sessionTime.Hour(), sessionTime.Minute(), 0, 0, time.UTC)
+			if !sessionEnd.After(nowUtc) && sessionEnd.After(latest) {
+				latest = sessionEnd
+			}
+		}
+		if !latest.IsZero() {
+			return latest, true, nil
+		}
+	}
+
+	return time.Time{}, false, nil
+}
+
+func parseSessionTimes(sessionEndTimes string) ([]time.Time, error) {
+	var result []time.Time
+	for _, value := range strings.Split(sessionEndTimes, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("15:04", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session end time %q: %w", value, err)
+		}
+		result = append(result, parsed)
+	}
+	return result, nil
+}
+
+// parseSessionDays empty map means every day, so a value which doesn't match exactly one day is an error
+// instead of being skipped, e.g. "Mon;Fri" must not close positions every day
+func parseSessionDays(sessionEndDays string) (map[time.Weekday]bool, error) {
+	result := make(map[time.Weekday]bool)
+	for _, value := range strings.Split(sessionEndDays, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		var matched []time.Weekday
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(strings.ToLower(day.String()), strings.ToLower(value)) {
+				matched = append(matched, day)
+			}
+		}
+		if len(matched) != 1 {
+			return nil, fmt.Errorf("invalid session end day %q", value)
+		}
+		result[matched[0]] = true
+	}
+	return result, nil
+}
+
+// ValidateSessionEnd checks session end settings of a strategy before they are saved
+func ValidateSessionEnd(sessionEndTimes string, sessionEndDays string) error {
+	if _, err := parseSessionTimes(sessionEndTimes); err != nil {
+		return err
+	}
+	_, err := parseSessionDays(sessionEndDays)
+	return err
+}
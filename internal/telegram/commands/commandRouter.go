@@ -0,0 +1,412 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tradingViewWebhookBot/internal/api"
+	"tradingViewWebhookBot/internal/dto/postgres/transaction"
+	"tradingViewWebhookBot/internal/dto/trade"
+	"tradingViewWebhookBot/internal/repository"
+	"tradingViewWebhookBot/internal/service/date"
+	"tradingViewWebhookBot/internal/service/orders"
+	"tradingViewWebhookBot/internal/service/positions"
+	"tradingViewWebhookBot/internal/service/strategy"
+	"tradingViewWebhookBot/internal/service/symbol"
+	"tradingViewWebhookBot/internal/telegram"
+	"tradingViewWebhookBot/internal/util"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// confirmationTimeout old confirmation buttons must not close positions opened after the preview
+const confirmationTimeout = 2 * time.Minute
+
+const (
+	callbackClose   = "close"
+	callbackFlatten = "flatten"
+	callbackCancel  = "cancel"
+)
+
+const helpText = `Commands:
+/positions - opened positions with unrealised P&L
+/pnl [today|week|month|all|7d] - realised P&L by strategy, today by default
+/strategies - strategies and their state
+/pause <tag> - stop opening positions by the strategy
+/resume <tag> - resume the strategy
+/close <transaction id> - close the position
+/flatten - close all positions of all strategies
+/price <symbol> - current price
+/balance - available wallet balance`
+
+func NewCommandRouter(
+	transactionRepo repository.Transaction,
+	exchangeApi api.ExchangeApi,
+	strategyService *strategy.TradingStrategyService,
+	positionService *positions.PositionService,
+	manualCloseService *orders.ManualCloseService,
+	symbolMapperService *symbol.SymbolMapperService,
+	clock date.Clock,
+) *CommandRouter {
+	return &CommandRouter{
+		transactionRepo:     transactionRepo,
+		exchangeApi:         exchangeApi,
+		strategyService:     strategyService,
+		positionService:     positionService,
+		manualCloseService:  manualCloseService,
+		symbolMapperService: symbolMapperService,
+		clock:               clock,
+		flattenPreviews:     make(map[int64]flattenPreview),
+	}
+}
+
+// CommandRouter telegram bot commands, destructive ones show a preview and are executed after the inline keyboard confirmation
+type CommandRouter struct {
+	transactionRepo     repository.Transaction
+	exchangeApi         api.ExchangeApi
+	strategyService     *strategy.TradingStrategyService
+	positionService     *positions.PositionService
+	manualCloseService  *orders.ManualCloseService
+	symbolMapperService *symbol.SymbolMapperService
+	clock               date.Clock
+
+	/* Positions of flatten previews by preview id, the confirmation closes only them */
+	mu              sync.Mutex
+	flattenPreviews map[int64]flattenPreview
+	lastPreviewId   int64
+}
+
+type flattenPreview struct {
+	transactionIds []int64
+	createdAt      time.Time
+}
+
+func (r *CommandRouter) HandleMessage(message *tgbotapi.Message) *telegram.Reply {
+	if !message.IsCommand() {
+		return textReply("Unknown command\n\n" + helpText)
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	switch message.Command() {
+	case "positions":
+		return r.positions()
+	case "pnl":
+		return r.pnl(args)
+	case "strategies":
+		return r.strategies()
+	case "pause":
+		return r.setStrategyEnabled(args, false)
+	case "resume":
+		return r.setStrategyEnabled(args, true)
+	case "close":
+		return r.closePreview(args)
+	case "flatten":
+		return r.flattenPreview()
+	case "price":
+		return r.price(args)
+	case "balance":
+		return r.balance()
+	default:
+		return textReply(helpText)
+	}
+}
+
+// HandleCallback callback data: "<action>:<id>:<unix time of the preview>"
+func (r *CommandRouter) HandleCallback(callback *tgbotapi.CallbackQuery) *telegram.Reply {
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 || parts[0] == callbackCancel {
+		return textReply("Cancelled")
+	}
+
+	createdAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || r.clock.NowTime().Sub(time.Unix(createdAt, 0)) > confirmationTimeout {
+		return textReply("Confirmation expired, repeat the command")
+	}
+
+	zap.S().Infof("Telegram user %d confirmed %s", callback.From.ID, callback.Data)
+	switch parts[0] {
+	case callbackClose:
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return textReply("Invalid transaction id")
+		}
+		result, err := r.manualCloseService.ClosePosition(id, false)
+		return closeResultReply(result, err)
+	case callbackFlatten:
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return textReply("Invalid preview id")
+		}
+		transactionIds, found := r.takeFlattenPreview(id)
+		if !found {
+			return textReply("Confirmation expired, repeat the command")
+		}
+		result, err := r.manualCloseService.CloseTransactions(transactionIds, false)
+		return closeResultReply(result, err)
+	default:
+		return textReply("Unknown action")
+	}
+}
+
+func (r *CommandRouter) positions() *telegram.Reply {
+	openedPositions, err := r.positionService.GetOpenedPositions(0)
+	if err != nil {
+		return errorReply(err)
+	}
+	if len(openedPositions) == 0 {
+		return textReply("No opened positions")
+	}
+
+	var text strings.Builder
+	total := float64(0)
+	for _, position := range openedPositions {
+		text.WriteString(fmt.Sprintf("#%d %s %s %s %v @ %v\n", position.TransactionId, position.TradingStrategyName,
+			position.CoinSymbol, position.Side, position.Amount, position.OpenPrice))
+		if position.PriceError != "" {
+			text.WriteString("  price is not available\n")
+			continue
+		}
+		text.WriteString(fmt.Sprintf("  now %v: $%.2f (%.2f%%)\n", position.CurrentPrice, position.UnrealisedProfit, position.UnrealisedPercent))
+		total += position.UnrealisedProfit
+	}
+	text.WriteString(fmt.Sprintf("\nTotal unrealised: $%.2f", total))
+	return textReply(text.String())
+}
+
+func (r *CommandRouter) pnl(args []string) *telegram.Reply {
+	period := "today"
+	if len(args) > 0 {
+		period = strings.ToLower(args[0])
+	}
+	from, err := getPeriodStart(period, r.clock.NowTime())
+	if err != nil {
+		return textReply(err.Error())
+	}
+
+	isFake := false
+	roundTrips, err := r.transactionRepo.FindRoundTrips(transaction.RoundTripFilter{From: from, IsFake: &isFake})
+	if err != nil {
+		return errorReply(err)
+	}
+	if len(roundTrips) == 0 {
+		return textReply(fmt.Sprintf("No closed trades for %s", period))
+	}
+
+	var strategyNames []string
+	profits := make(map[string]int64)
+	counts := make(map[string]int)
+	total := int64(0)
+	for _, roundTrip := range roundTrips {
+		if _, found := profits[roundTrip.TradingStrategyName]; !found {
+			strategyNames = append(strategyNames, roundTrip.TradingStrategyName)
+		}
+		profits[roundTrip.TradingStrategyName] += roundTrip.Profit
+		counts[roundTrip.TradingStrategyName]++
+		total += roundTrip.Profit
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Realised P&L for %s:\n", period))
+	for _, name := range strategyNames {
+		text.WriteString(fmt.Sprintf("%s: %s (%d trades)\n", name, util.RoundCentsToUsd(profits[name]), counts[name]))
+	}
+	text.WriteString(fmt.Sprintf("\nTotal: %s", util.RoundCentsToUsd(total)))
+	return textReply(text.String())
+}
+
+func (r *CommandRouter) strategies() *telegram.Reply {
+	strategies, err := r.strategyService.List()
+	if err != nil {
+		return errorReply(err)
+	}
+
+	var text strings.Builder
+	for _, tradingStrategy := range strategies {
+		state := "active"
+		if !tradingStrategy.Enabled {
+			state = "paused"
+		}
+		text.WriteString(fmt.Sprintf("#%d %s [%s] - %s\n", tradingStrategy.Id, tradingStrategy.Name, tradingStrategy.Tag, state))
+	}
+	if text.Len() == 0 {
+		return textReply("No strategies")
+	}
+	return textReply(text.String())
+}
+
+// setStrategyEnabled paused strategy doesn't react on alerts, opened positions stay opened
+func (r *CommandRouter) setStrategyEnabled(args []string, enabled bool) *telegram.Reply {
+	if len(args) != 1 {
+		return textReply("Usage: /pause <tag> or /resume <tag>")
+	}
+
+	strategies, err := r.strategyService.List()
+	if err != nil {
+		return errorReply(err)
+	}
+	for _, tradingStrategy := range strategies {
+		if tradingStrategy.Tag != args[0] {
+			continue
+		}
+		if _, err := r.strategyService.SetEnabled(tradingStrategy.Id, enabled); err != nil {
+			return errorReply(err)
+		}
+		if enabled {
+			return textReply(fmt.Sprintf("Strategy %s resumed", tradingStrategy.Tag))
+		}
+		return textReply(fmt.Sprintf("Strategy %s paused, opened positions are kept", tradingStrategy.Tag))
+	}
+	return textReply(fmt.Sprintf("Strategy not found: %s", args[0]))
+}
+
+func (r *CommandRouter) closePreview(args []string) *telegram.Reply {
+	if len(args) != 1 {
+		return textReply("Usage: /close <transaction id>")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return textReply("Invalid transaction id")
+	}
+
+	preview, err := r.manualCloseService.ClosePosition(id, true)
+	if err != nil {
+		return errorReply(err)
+	}
+	return r.confirmationReply("Close position?", preview, fmt.Sprintf("%s:%d", callbackClose, id))
+}
+
+func (r *CommandRouter) flattenPreview() *telegram.Reply {
+	preview, err := r.manualCloseService.FlattenAll(true)
+	if err != nil {
+		return errorReply(err)
+	}
+	if len(preview.Positions) == 0 {
+		return textReply("No opened positions")
+	}
+	transactionIds := make([]int64, 0, len(preview.Positions))
+	for _, position := range preview.Positions {
+		transactionIds = append(transactionIds, position.TransactionId)
+	}
+	id := r.storeFlattenPreview(transactionIds)
+	return r.confirmationReply("Close ALL positions?", preview, fmt.Sprintf("%s:%d", callbackFlatten, id))
+}
+
+// storeFlattenPreview callback data is limited to 64 bytes, so the previewed positions are kept here, expired previews are dropped
+func (r *CommandRouter) storeFlattenPreview(transactionIds []int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.NowTime()
+	for id, preview := range r.flattenPreviews {
+		if now.Sub(preview.createdAt) > confirmationTimeout {
+			delete(r.flattenPreviews, id)
+		}
+	}
+	r.lastPreviewId++
+	r.flattenPreviews[r.lastPreviewId] = flattenPreview{transactionIds: transactionIds, createdAt: now}
+	return r.lastPreviewId
+}
+
+// takeFlattenPreview the preview is confirmed once
+func (r *CommandRouter) takeFlattenPreview(id int64) ([]int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	preview, found := r.flattenPreviews[id]
+	delete(r.flattenPreviews, id)
+	return preview.transactionIds, found
+}
+
+func (r *CommandRouter) price(args []string) *telegram.Reply {
+	if len(args) != 1 {
+		return textReply("Usage: /price <symbol>")
+	}
+	coin, err := r.symbolMapperService.FindCoin(args[0])
+	if err != nil {
+		return textReply(err.Error())
+	}
+	currentPrice, err := r.exchangeApi.GetCurrentCoinPrice(coin)
+	if err != nil {
+		return errorReply(err)
+	}
+	return textReply(fmt.Sprintf("%s: %v", coin.Symbol, currentPrice))
+}
+
+func (r *CommandRouter) balance() *telegram.Reply {
+	walletBalance, err := r.exchangeApi.GetWalletBalance()
+	if err != nil {
+		return errorReply(err)
+	}
+	return textReply(fmt.Sprintf("Available balance: $%.2f", walletBalance.GetAvailableBalance()))
+}
+
+func (r *CommandRouter) confirmationReply(question string, preview *trade.ManualCloseResultDto, action string) *telegram.Reply {
+	var text strings.Builder
+	text.WriteString(question + "\n\n")
+	for _, position := range preview.Positions {
+		text.WriteString(formatClosePosition(position))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Confirm", fmt.Sprintf("%s:%d", action, r.clock.NowTime().Unix())),
+		tgbotapi.NewInlineKeyboardButtonData("Cancel", callbackCancel),
+	))
+	return &telegram.Reply{Text: text.String(), Keyboard: &keyboard}
+}
+
+func closeResultReply(result *trade.ManualCloseResultDto, err error) *telegram.Reply {
+	if err != nil {
+		return errorReply(err)
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Closed: %d, failed: %d, skipped: %d\n\n", result.Closed, result.Failed, result.Skipped))
+	for _, position := range result.Positions {
+		text.WriteString(formatClosePosition(position))
+	}
+	return textReply(text.String())
+}
+
+func formatClosePosition(position trade.ManualClosePosition) string {
+	if position.Error != "" {
+		return fmt.Sprintf("#%d %s %s: %s\n", position.TransactionId, position.CoinSymbol, position.Side, position.Error)
+	}
+	return fmt.Sprintf("#%d %s %s %v @ %v, now %v: ~$%.2f\n", position.TransactionId, position.CoinSymbol, position.Side,
+		position.Amount, position.OpenPrice, position.CurrentPrice, position.EstimatedProfit)
+}
+
+// getPeriodStart today, week, month, all or number of days like 7d, in UTC
+func getPeriodStart(period string, now time.Time) (*time.Time, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var from time.Time
+	switch period {
+	case "all":
+		return nil, nil
+	case "today":
+		from = today
+	case "week":
+		from = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	case "month":
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		days, err := strconv.Atoi(strings.TrimSuffix(period, "d"))
+		if err != nil || days <= 0 || !strings.HasSuffix(period, "d") {
+			return nil, fmt.Errorf("Unknown period %s, use today, week, month, all or 7d", period)
+		}
+		from = now.AddDate(0, 0, -days)
+	}
+	return &from, nil
+}
+
+func textReply(text string) *telegram.Reply {
+	return &telegram.Reply{Text: text}
+}
+
+func errorReply(err error) *telegram.Reply {
+	zap.S().Errorf("Telegram command failed: %s", err.Error())
+	return textReply("Error: " + err.Error())
+}
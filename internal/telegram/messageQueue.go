@@ -0,0 +1,268 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"tradingViewWebhookBot/internal/domain"
+	"tradingViewWebhookBot/internal/repository"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const failedMessageChannel = "telegram"
+
+// maxBackoff upper limit of the delay between retries
+const maxBackoff = time.Minute
+
+var errQueueIsFull = errors.New("telegram message queue is full")
+var errQueueIsStopped = errors.New("telegram message queue is stopped")
+
+type queueConfig struct {
+	size        int
+	maxAttempts int
+	// minInterval between messages to the same chat, Telegram allows about one message per second
+	minInterval time.Duration
+	// batchWindow messages received within the window after the first one are sent together
+	batchWindow time.Duration
+	maxBatch    int
+}
+
+// newQueueConfig telegram.queue section of config.yml, defaults are used for missing values
+func newQueueConfig() queueConfig {
+	config := queueConfig{
+		size:        viper.GetInt("telegram.queue.size"),
+		maxAttempts: viper.GetInt("telegram.queue.maxAttempts"),
+		minInterval: viper.GetDuration("telegram.queue.minInterval"),
+		batchWindow: viper.GetDuration("telegram.queue.batchWindow"),
+		maxBatch:    viper.GetInt("telegram.queue.maxBatch"),
+	}
+	if config.size <= 0 {
+		config.size = 1000
+	}
+	if config.maxAttempts <= 0 {
+		config.maxAttempts = 5
+	}
+	if config.minInterval <= 0 {
+		config.minInterval = time.Second
+	}
+	if config.batchWindow <= 0 {
+		config.batchWindow = 2 * time.Second
+	}
+	if config.maxBatch <= 0 {
+		config.maxBatch = 20
+	}
+	return config
+}
+
+type queuedMessage struct {
+	chatID string
+	text   string
+}
+
+// messageQueue delivers messages in background: bursts are batched, long messages split,
+// failed sends retried with exponential backoff or Telegram retry_after.
+// Messages which were not delivered are saved to failedMessageRepo for replay.
+type messageQueue struct {
+	config            queueConfig
+	send              func(chatID string, text string) error
+	failedMessageRepo repository.FailedMessage
+	logger            *zap.Logger
+
+	messages chan queuedMessage
+	lastSent map[string]time.Time
+	done     chan struct{}
+	// mutex guards messages from sending after they are closed by stop
+	mutex   sync.RWMutex
+	stopped bool
+}
+
+func newMessageQueue(config queueConfig, send func(chatID string, text string) error, failedMessageRepo repository.FailedMessage, logger *zap.Logger) *messageQueue {
+	return &messageQueue{
+		config:            config,
+		send:              send,
+		failedMessageRepo: failedMessageRepo,
+		logger:            logger,
+		messages:          make(chan queuedMessage, config.size),
+		lastSent:          make(map[string]time.Time),
+		done:              make(chan struct{}),
+	}
+}
+
+func (q *messageQueue) start() {
+	go q.run()
+}
+
+// enqueue never blocks the caller, the message is saved as failed if the queue is full
+func (q *messageQueue) enqueue(chatID string, text string) error {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	if q.stopped {
+		q.saveFailed(chatID, text, errQueueIsStopped, 0)
+		return errQueueIsStopped
+	}
+
+	select {
+	case q.messages <- queuedMessage{chatID: chatID, text: text}:
+		return nil
+	default:
+		q.saveFailed(chatID, text, errQueueIsFull, 0)
+		return errQueueIsFull
+	}
+}
+
+// stop delivers queued messages, waiting not longer than timeout. Not delivered messages are lost.
+func (q *messageQueue) stop(timeout time.Duration) {
+	q.mutex.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.messages)
+	}
+	q.mutex.Unlock()
+
+	select {
+	case <-q.done:
+	case <-time.After(timeout):
+		q.logger.Warn("Telegram message queue is not drained", zap.Int("messages", len(q.messages)))
+	}
+}
+
+func (q *messageQueue) run() {
+	defer close(q.done)
+	for {
+		first, ok := <-q.messages
+		if !ok {
+			return
+		}
+		batch, closed := q.collectBatch(first)
+		q.deliverBatch(batch)
+		if closed {
+			return
+		}
+	}
+}
+
+// collectBatch waits for messages of the burst during batchWindow, closed - the queue is stopped
+func (q *messageQueue) collectBatch(first queuedMessage) ([]queuedMessage, bool) {
+	batch := []queuedMessage{first}
+	timer := time.NewTimer(q.config.batchWindow)
+	defer timer.Stop()
+
+	for len(batch) < q.config.maxBatch {
+		select {
+		case message, ok := <-q.messages:
+			if !ok {
+				return batch, true
+			}
+			batch = append(batch, message)
+		case <-timer.C:
+			return batch, false
+		}
+	}
+	return batch, false
+}
+
+// deliverBatch messages of the same chat are packed together keeping their order
+func (q *messageQueue) deliverBatch(batch []queuedMessage) {
+	var chatIDs []string
+	texts := make(map[string][]string)
+	for _, message := range batch {
+		if _, ok := texts[message.chatID]; !ok {
+			chatIDs = append(chatIDs, message.chatID)
+		}
+		texts[message.chatID] = append(texts[message.chatID], message.text)
+	}
+
+	for _, chatID := range chatIDs {
+		for _, text := range packMessages(texts[chatID], maxMessageLength) {
+			q.deliver(chatID, text)
+		}
+	}
+}
+
+func (q *messageQueue) deliver(chatID string, text string) {
+	var err error
+	attempt := 1
+	for ; attempt <= q.config.maxAttempts; attempt++ {
+		q.waitForRateLimit(chatID)
+		err = q.send(chatID, text)
+		q.lastSent[chatID] = time.Now()
+		if err == nil {
+			return
+		}
+
+		var apiErr *apiError
+		isApiErr := errors.As(err, &apiErr)
+		if isApiErr && !apiErr.isRetryable() {
+			break
+		}
+		if attempt == q.config.maxAttempts {
+			break
+		}
+
+		delay := getBackoff(attempt)
+		if isApiErr && apiErr.retryAfter > 0 {
+			delay = apiErr.retryAfter
+		}
+		q.logger.Warn("Telegram message is not sent, retrying",
+			zap.Error(err), zap.Int("attempt", attempt), zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
+	q.logger.Error("Telegram message is not delivered", zap.Error(err), zap.Int("attempts", attempt))
+	q.saveFailed(chatID, text, err, attempt)
+}
+
+// waitForRateLimit keeps minInterval between messages of the chat
+func (q *messageQueue) waitForRateLimit(chatID string) {
+	if lastSent, ok := q.lastSent[chatID]; ok {
+		if wait := q.config.minInterval - time.Since(lastSent); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+}
+
+func (q *messageQueue) saveFailed(chatID string, text string, err error, attempts int) {
+	if q.failedMessageRepo == nil {
+		return
+	}
+	message := &domain.FailedMessage{
+		Channel:   failedMessageChannel,
+		ChatId:    chatID,
+		Text:      text,
+		Error:     err.Error(),
+		Attempts:  attempts,
+		CreatedAt: time.Now().UTC(),
+	}
+	if errS := q.failedMessageRepo.Create(message); errS != nil {
+		q.logger.Error("Error during save of failed telegram message", zap.Error(errS))
+	}
+}
+
+// getBackoff 1s, 2s, 4s, ... up to maxBackoff
+func getBackoff(attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt-1))) * time.Second
+	if delay > maxBackoff || delay <= 0 {
+		return maxBackoff
+	}
+	return delay
+}
+
+// apiError not successful response of Telegram API
+type apiError struct {
+	statusCode  int
+	description string
+	retryAfter  time.Duration
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("telegram API error: status=%d, description=%s", e.statusCode, e.description)
+}
+
+// isRetryable rate limit and server errors, other client errors fail the same way again
+func (e *apiError) isRetryable() bool {
+	return e.statusCode == 429 || e.statusCode >= 500
+}
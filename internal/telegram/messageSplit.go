@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageLength limit of the text of one Telegram message
+const maxMessageLength = 4096
+
+// batchSeparator between messages joined to one
+const batchSeparator = "\n\n"
+
+// splitMessage splits the text by lines to parts not longer than the limit, a longer line is cut as is.
+// Templates keep HTML tags within a line, so splitting by lines doesn't break them.
+func splitMessage(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	currentLength := 0
+	flush := func() {
+		if currentLength > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			currentLength = 0
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		lineLength := utf8.RuneCountInString(line)
+		for lineLength > limit {
+			flush()
+			runes := []rune(line)
+			parts = append(parts, string(runes[:limit]))
+			line = string(runes[limit:])
+			lineLength -= limit
+		}
+
+		if currentLength > 0 && currentLength+1+lineLength > limit {
+			flush()
+		}
+		if currentLength > 0 {
+			current.WriteString("\n")
+			currentLength++
+		}
+		current.WriteString(line)
+		currentLength += lineLength
+	}
+	flush()
+	return parts
+}
+
+// packMessages joins a burst of messages to as few Telegram messages as possible, long messages are split
+func packMessages(texts []string, limit int) []string {
+	var packed []string
+	current := ""
+	for _, text := range texts {
+		for _, part := range splitMessage(text, limit) {
+			if current != "" && utf8.RuneCountInString(current)+len(batchSeparator)+utf8.RuneCountInString(part) <= limit {
+				current += batchSeparator + part
+				continue
+			}
+			if current != "" {
+				packed = append(packed, current)
+			}
+			current = part
+		}
+	}
+	if current != "" {
+		packed = append(packed, current)
+	}
+	return packed
+}
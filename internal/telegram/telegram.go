@@ -1,57 +1,79 @@
 package telegram
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	"tradingViewWebhookBot/internal/repository"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 )
 
+// closeTimeout max time of delivering queued messages on Close
+const closeTimeout = 30 * time.Second
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
 type TelegramClient struct {
-	logger     *zap.Logger
-	apiKey     string
-	chatID     string
-	apiBaseURL string
-	enabled    bool
-	bot        *tgbotapi.BotAPI
+	logger            *zap.Logger
+	apiKey            string
+	chatID            string
+	apiBaseURL        string
+	enabled           bool
+	bot               *tgbotapi.BotAPI
+	queue             *messageQueue
+	failedMessageRepo repository.FailedMessage
 }
 
-func NewTelegramClient() *TelegramClient {
-	bot, err := tgbotapi.NewBotAPI(os.Getenv("TELEGRAM_BOT_API_KEY"))
+// NewTelegramClient failedMessageRepo is optional, without it undelivered messages are only logged
+func NewTelegramClient(failedMessageRepo repository.FailedMessage) *TelegramClient {
+	telegramClient := &TelegramClient{
+		logger:            zap.L(),
+		apiKey:            os.Getenv("TELEGRAM_BOT_API_KEY"),
+		chatID:            os.Getenv("TELEGRAM_BOT_CHAT_ID"),
+		apiBaseURL:        os.Getenv("TELEGRAM_API_BASE_URL"),
+		enabled:           os.Getenv("TELEGRAM_ENABLED") == "true",
+		failedMessageRepo: failedMessageRepo,
+	}
+	telegramClient.queue = newMessageQueue(newQueueConfig(), telegramClient.postMessage, failedMessageRepo, telegramClient.logger)
+	telegramClient.queue.start()
+
+	bot, err := tgbotapi.NewBotAPI(telegramClient.apiKey)
 	if err != nil {
 		zap.L().Error("Failed to initialize Telegram bot", zap.Error(err))
-		return &TelegramClient{
-			logger:     zap.L(),
-			apiKey:     os.Getenv("TELEGRAM_BOT_API_KEY"),
-			chatID:     os.Getenv("TELEGRAM_BOT_CHAT_ID"),
-			apiBaseURL: os.Getenv("TELEGRAM_API_BASE_URL"),
-			enabled:    os.Getenv("TELEGRAM_ENABLED") == "true",
-		}
+		return telegramClient
 	}
 
 	bot.Debug = true // Set to false in production
 	zap.L().Info("Authorized on account", zap.String("username", bot.Self.UserName))
+	telegramClient.bot = bot
 
-	telegramClient := &TelegramClient{
-		logger:     zap.L(),
-		apiKey:     os.Getenv("TELEGRAM_BOT_API_KEY"),
-		chatID:     os.Getenv("TELEGRAM_BOT_CHAT_ID"),
-		apiBaseURL: os.Getenv("TELEGRAM_API_BASE_URL"),
-		enabled:    os.Getenv("TELEGRAM_ENABLED") == "true",
-		bot:        bot,
-	}
+	return telegramClient
+}
 
-	telegramClient.StartMessageHandler()
+// Reply answer of the command handler, Keyboard is optional inline keyboard, e.g. for confirmations
+type Reply struct {
+	Text     string
+	Keyboard *tgbotapi.InlineKeyboardMarkup
+}
 
-	return telegramClient
+// CommandHandler handles messages and inline keyboard callbacks of allowed users only
+type CommandHandler interface {
+	HandleMessage(message *tgbotapi.Message) *Reply
+	HandleCallback(callback *tgbotapi.CallbackQuery) *Reply
 }
 
-// StartMessageHandler starts listening for messages
-func (t *TelegramClient) StartMessageHandler() {
+// StartMessageHandler starts listening for messages of the configured chat and TELEGRAM_ALLOWED_USER_IDS,
+// other senders are ignored
+func (t *TelegramClient) StartMessageHandler(handler CommandHandler) {
 	if !t.enabled || t.bot == nil {
 		t.logger.Info("Telegram bot is disabled or not initialized")
 		return
@@ -64,6 +86,10 @@ func (t *TelegramClient) StartMessageHandler() {
 
 	go func() {
 		for update := range updates {
+			if update.CallbackQuery != nil {
+				t.handleCallback(handler, update.CallbackQuery)
+				continue
+			}
 			if update.Message == nil {
 				continue
 			}
@@ -79,68 +105,199 @@ func (t *TelegramClient) StartMessageHandler() {
 				zap.String("text", update.Message.Text),
 			)
 
-			if update.Message.ForwardFrom != nil {
-				t.logger.Info("Forwarded Message Info",
-					zap.String("from_username", update.Message.ForwardFrom.UserName),
-					zap.Int64("from_user_id", update.Message.ForwardFrom.ID),
-				)
+			if !t.isAllowed(update.Message.Chat.ID, update.Message.From.ID) {
+				t.logger.Warn("Message from not allowed user is ignored", zap.Int64("user_id", update.Message.From.ID))
+				continue
 			}
 
-			// Optional: Send confirmation reply
-			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-				fmt.Sprintf("Received your message: %s", update.Message.Text))
-			msg.ReplyToMessageID = update.Message.MessageID
-
-			if _, err := t.bot.Send(msg); err != nil {
-				t.logger.Error("Error sending reply",
-					zap.Error(err),
-					zap.Int64("chat_id", update.Message.Chat.ID),
-				)
-			}
+			reply := handler.HandleMessage(update.Message)
+			t.sendReply(update.Message.Chat.ID, update.Message.MessageID, reply)
 		}
 	}()
 }
 
+func (t *TelegramClient) handleCallback(handler CommandHandler, callback *tgbotapi.CallbackQuery) {
+	if callback.Message == nil || !t.isAllowedCallback(callback.Message.Chat, callback.From.ID) {
+		t.logger.Warn("Callback from not allowed user is ignored", zap.Int64("user_id", callback.From.ID))
+		return
+	}
+
+	// removes the loading state of the button
+	if _, err := t.bot.Request(tgbotapi.NewCallback(callback.ID, "")); err != nil {
+		t.logger.Error("Error answering callback", zap.Error(err))
+	}
+	// confirmation buttons must not be pressed twice
+	removeKeyboard := tgbotapi.NewEditMessageReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	if _, err := t.bot.Request(removeKeyboard); err != nil {
+		t.logger.Error("Error removing inline keyboard", zap.Error(err))
+	}
+
+	reply := handler.HandleCallback(callback)
+	t.sendReply(callback.Message.Chat.ID, callback.Message.MessageID, reply)
+}
+
+// sendReply long replies (e.g. the list of positions) are split by maxMessageLength,
+// the keyboard is attached to the last part, so the buttons are below the whole text
+func (t *TelegramClient) sendReply(chatID int64, replyToMessageID int, reply *Reply) {
+	if reply == nil || reply.Text == "" {
+		return
+	}
+
+	parts := splitMessage(reply.Text, maxMessageLength)
+	for i, part := range parts {
+		msg := tgbotapi.NewMessage(chatID, part)
+		msg.ReplyToMessageID = replyToMessageID
+		if reply.Keyboard != nil && i == len(parts)-1 {
+			msg.ReplyMarkup = *reply.Keyboard
+		}
+
+		// the rest is not sent, a reply without its beginning or with the keyboard under a wrong text is misleading
+		if _, err := t.bot.Send(msg); err != nil {
+			t.logger.Error("Error sending reply",
+				zap.Error(err),
+				zap.Int64("chat_id", chatID),
+				zap.Int("part", i+1),
+				zap.Int("parts", len(parts)),
+			)
+			return
+		}
+	}
+}
+
+func (t *TelegramClient) isAllowed(chatID int64, userID int64) bool {
+	if strconv.FormatInt(chatID, 10) == t.chatID {
+		return true
+	}
+	return isAllowedUser(userID)
+}
+
+// isAllowedCallback confirmations close positions, so in a group chat any member could press the button,
+// there only TELEGRAM_ALLOWED_USER_IDS may confirm
+func (t *TelegramClient) isAllowedCallback(chat *tgbotapi.Chat, userID int64) bool {
+	if chat == nil {
+		return false
+	}
+	if chat.IsPrivate() {
+		return t.isAllowed(chat.ID, userID)
+	}
+	return isAllowedUser(userID)
+}
+
+func isAllowedUser(userID int64) bool {
+	for _, allowedID := range strings.Split(os.Getenv("TELEGRAM_ALLOWED_USER_IDS"), ",") {
+		if allowedID = strings.TrimSpace(allowedID); allowedID != "" && allowedID == strconv.FormatInt(userID, 10) {
+			return true
+		}
+	}
+	return false
+}
+
+// SendMessage queues the text with HTML parse mode to the configured chat, errors are logged only
 func (t *TelegramClient) SendMessage(text string) {
+	if err := t.Send(text); err != nil {
+		t.logger.Error("failed to send telegram message", zap.Error(err))
+	}
+}
+
+// Send queues the text with HTML parse mode to the configured chat, disabled client skips the message.
+// The message is delivered in background, see messageQueue.
+func (t *TelegramClient) Send(text string) error {
 	if !t.enabled {
 		t.logger.Debug("Telegram message (disabled)", zap.String("text", text))
-		return
+		return nil
 	}
 
 	if t.apiKey == "" || t.chatID == "" {
-		t.logger.Error("telegram configuration missing: API_KEY or CHAT_ID not set")
-		return
+		return fmt.Errorf("telegram configuration missing: API_KEY or CHAT_ID not set")
 	}
 
-	apiURL := t.apiBaseURL + t.apiKey + "/sendMessage"
+	return t.queue.enqueue(t.chatID, text)
+}
 
-	response, err := http.PostForm(
-		apiURL,
-		url.Values{
-			"chat_id":    {t.chatID},
-			"text":       {text},
-			"parse_mode": {"HTML"},
-		})
+// ReplayFailedMessages queues again messages which were not delivered, each marked as replayed.
+// The creation time is prepended, a message failing again is saved as a new one.
+func (t *TelegramClient) ReplayFailedMessages(limit int) (int, error) {
+	if t.failedMessageRepo == nil {
+		return 0, fmt.Errorf("failed messages are not stored")
+	}
 
+	messages, err := t.failedMessageRepo.FindNotReplayed(failedMessageChannel, limit)
 	if err != nil {
-		t.logger.Error("failed to send telegram message", zap.Error(err))
-		return
+		return 0, err
+	}
+
+	replayed := 0
+	for _, message := range messages {
+		text := fmt.Sprintf("<i>Delayed message of %s UTC</i>\n%s", message.CreatedAt.Format("2006-01-02 15:04:05"), message.Text)
+		if err := t.queue.enqueue(message.ChatId, text); err != nil {
+			return replayed, err
+		}
+		if err := t.failedMessageRepo.MarkReplayed(message.Id, time.Now().UTC()); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// Close delivers queued messages, commands have to call it before exit
+func (t *TelegramClient) Close() {
+	t.queue.stop(closeTimeout)
+}
+
+// postMessage sends the message synchronously. Text which Telegram can't parse as HTML,
+// e.g. split inside a tag, is sent again as plain text.
+func (t *TelegramClient) postMessage(chatID string, text string) error {
+	err := t.post(chatID, text, "HTML")
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.statusCode == http.StatusBadRequest && strings.Contains(apiErr.description, "can't parse entities") {
+		t.logger.Warn("Telegram message is sent as plain text", zap.String("description", apiErr.description))
+		return t.post(chatID, text, "")
+	}
+	return err
+}
+
+type sendMessageResponse struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+func (t *TelegramClient) post(chatID string, text string, parseMode string) error {
+	values := url.Values{
+		"chat_id": {chatID},
+		"text":    {text},
+	}
+	if parseMode != "" {
+		values.Set("parse_mode", parseMode)
+	}
+
+	response, err := httpClient.PostForm(t.apiBaseURL+t.apiKey+"/sendMessage", values)
+	if err != nil {
+		return err
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		t.logger.Error("failed to read telegram response", zap.Error(err))
-		return
+		return fmt.Errorf("failed to read telegram response: %w", err)
 	}
 
 	if response.StatusCode != http.StatusOK {
-		err = fmt.Errorf("telegram API error: status=%d, body=%s", response.StatusCode, string(body))
-		t.logger.Error("telegram API error", zap.Error(err))
-		return
+		apiErr := &apiError{statusCode: response.StatusCode, description: string(body)}
+		var parsed sendMessageResponse
+		if json.Unmarshal(body, &parsed) == nil {
+			apiErr.description = parsed.Description
+			apiErr.retryAfter = time.Duration(parsed.Parameters.RetryAfter) * time.Second
+		}
+		return apiErr
 	}
 
 	t.logger.Debug("Telegram message sent successfully",
 		zap.String("text", text),
 		zap.Int("status", response.StatusCode))
+	return nil
 }
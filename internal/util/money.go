@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"tradingViewWebhookBot/internal/constants/futureType"
 
 	"go.uber.org/zap"
@@ -79,3 +80,18 @@ func CalculateProfitByRation(openPrice float64, stopLossPrice float64, futuresTy
 
 	return CalculatePriceForTakeProfit(openPrice, takeProfitInPercent, futuresType)
 }
+
+// FormatQuantityByStep rounds the quantity down to the exchange qty step, so the order is never bigger than calculated.
+// Without the step the quantity is formatted with 3 decimals.
+func FormatQuantityByStep(quantity float64, qtyStep float64) string {
+	if qtyStep <= 0 {
+		return fmt.Sprintf("%.3f", quantity)
+	}
+	// epsilon protects from 0.3/0.1 = 2.9999999999999996
+	steps := math.Floor(quantity/qtyStep + 1e-9)
+	decimals := 0
+	if step := strconv.FormatFloat(qtyStep, 'f', -1, 64); strings.Contains(step, ".") {
+		decimals = len(step) - strings.Index(step, ".") - 1
+	}
+	return strconv.FormatFloat(steps*qtyStep, 'f', decimals, 64)
+}
@@ -0,0 +1,23 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// SignId HMAC-SHA256 of the id, read-only links opened by a browser can't send the API token, so they carry the signature instead
+func SignId(secret string, id int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(id, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsValidIdSignature signature of SignId, nothing is valid without the secret
+func IsValidIdSignature(secret string, id int64, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(SignId(secret, id)), []byte(signature))
+}
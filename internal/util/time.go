@@ -91,3 +91,12 @@ func IsTheSameDay(date1, date2 time.Time) bool {
 	y2, m2, d2 := date2.Date()
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
+
+// ParseKlineInterval Bybit interval in minutes, e.g. "1", "15", "60"
+func ParseKlineInterval(interval string) (time.Duration, error) {
+	minutes, err := strconv.Atoi(interval)
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("interval %q must be a number of minutes", interval)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
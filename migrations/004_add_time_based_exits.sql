@@ -0,0 +1,12 @@
+-- +migrate Up
+-- max_holding_minutes: 0 - without limit.
+-- session_end_times: comma separated UTC times (HH:MM) when positions must be flat, e.g. '07:55,15:55,23:55' before funding.
+-- session_end_days: comma separated week days (Mon,Tue,...) the session end applies to, empty - every day.
+ALTER TABLE trading_strategies
+    ADD COLUMN IF NOT EXISTS max_holding_minutes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS session_end_times   TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS session_end_days    TEXT    NOT NULL DEFAULT '';
+
+-- +migrate Up
+ALTER TABLE transaction_table
+    ADD COLUMN IF NOT EXISTS exit_reason VARCHAR(50);
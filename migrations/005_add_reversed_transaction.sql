@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Open transaction created by reversing a position refers to the close transaction of the reversed position.
+ALTER TABLE transaction_table
+    ADD COLUMN IF NOT EXISTS reversed_transaction_id BIGINT REFERENCES transaction_table(id);
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- requested_amount: amount sent to the exchange, amount contains executed quantity only.
+-- parent_transaction_id: open transaction of the remainder after a partially filled close refers to the original open transaction.
+ALTER TABLE transaction_table
+    ADD COLUMN IF NOT EXISTS order_status          VARCHAR(30),
+    ADD COLUMN IF NOT EXISTS requested_amount      DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS parent_transaction_id BIGINT REFERENCES transaction_table(id);
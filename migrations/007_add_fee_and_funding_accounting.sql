@@ -0,0 +1,17 @@
+-- +migrate Up
+-- exec_fee: real fee of the order executions in USD.
+-- funding_fee: funding settled while the position was opened in USD, set on the close transaction. Positive - paid, negative - received.
+-- gross_profit: profit in cents before fees and funding, profit = gross_profit - fees - funding.
+ALTER TABLE transaction_table
+    ADD COLUMN IF NOT EXISTS exec_fee     DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS funding_fee  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS gross_profit BIGINT;
+
+-- +migrate Up
+-- Profit of existing round trips was calculated from estimated commissions of both legs.
+UPDATE transaction_table close_transaction
+SET gross_profit = close_transaction.profit + round((close_transaction.commission + open_transaction.commission) * 100)
+FROM transaction_table open_transaction
+WHERE close_transaction.related_transaction_id = open_transaction.id
+  AND close_transaction.profit IS NOT NULL
+  AND close_transaction.gross_profit IS NULL;
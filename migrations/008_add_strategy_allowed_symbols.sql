@@ -0,0 +1,10 @@
+-- +migrate Up
+-- allowed_symbols: comma separated coin symbols the strategy may trade, empty - any coin.
+ALTER TABLE trading_strategies
+    ADD COLUMN IF NOT EXISTS allowed_symbols TEXT NOT NULL DEFAULT '';
+
+-- +migrate Up
+UPDATE trading_strategies SET description = '' WHERE description IS NULL;
+
+-- +migrate Up
+CREATE UNIQUE INDEX IF NOT EXISTS uidx_trading_strategies_tag ON trading_strategies (tag);
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- Trading rules of the exchange instrument, refreshed when the coin is added.
+-- Zero means unknown: qty is then formatted with 3 decimals as before.
+ALTER TABLE coins
+    ADD COLUMN IF NOT EXISTS enabled       BOOLEAN          NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS tick_size     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS qty_step      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS min_order_qty DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_order_qty DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS min_notional  DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+-- +migrate Up
+-- TradingView tickers which can't be normalised to the coin symbol by rules, e.g. 1000PEPEUSDT.P for PEPE.
+-- exchange: TradingView exchange prefix (BYBIT, BINANCE), empty - alias of any exchange.
+CREATE TABLE IF NOT EXISTS coin_aliases
+(
+    id       BIGSERIAL PRIMARY KEY,
+    exchange VARCHAR(50)  NOT NULL DEFAULT '',
+    alias    VARCHAR(100) NOT NULL,
+    coin_id  BIGINT       NOT NULL REFERENCES coins (id) ON DELETE CASCADE,
+    UNIQUE (exchange, alias)
+);
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Received TradingView alerts, transactions opened or closed by the alert refer to it.
+-- payload: raw request body as it was received.
+CREATE TABLE IF NOT EXISTS alerts
+(
+    id         BIGSERIAL PRIMARY KEY,
+    tag        VARCHAR(100) NOT NULL DEFAULT '',
+    ticker     VARCHAR(100) NOT NULL DEFAULT '',
+    payload    TEXT         NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE transaction_table
+    ADD COLUMN IF NOT EXISTS alert_id BIGINT REFERENCES alerts (id) ON DELETE SET NULL;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Notifications which were not delivered after all retries, kept for replay.
+-- replayed_at: the message was queued again, a new row is created if it fails again.
+CREATE TABLE IF NOT EXISTS failed_messages
+(
+    id          BIGSERIAL PRIMARY KEY,
+    channel     VARCHAR(50)  NOT NULL,
+    chat_id     VARCHAR(100) NOT NULL DEFAULT '',
+    text        TEXT         NOT NULL,
+    error       TEXT         NOT NULL DEFAULT '',
+    attempts    INT          NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    replayed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS failed_messages_not_replayed_idx ON failed_messages (id) WHERE replayed_at IS NULL;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Historical OHLCV of the exchange, only closed candles are stored.
+-- candle_interval: Bybit interval in minutes, e.g. 15 or 60.
+CREATE TABLE IF NOT EXISTS candles
+(
+    coin_id         BIGINT           NOT NULL REFERENCES coins (id) ON DELETE CASCADE,
+    candle_interval VARCHAR(10)      NOT NULL,
+    start_at        TIMESTAMP        NOT NULL,
+    open            DOUBLE PRECISION NOT NULL,
+    high            DOUBLE PRECISION NOT NULL,
+    low             DOUBLE PRECISION NOT NULL,
+    close           DOUBLE PRECISION NOT NULL,
+    volume          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    turnover        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (coin_id, candle_interval, start_at)
+);
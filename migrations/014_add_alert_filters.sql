@@ -0,0 +1,9 @@
+-- +migrate Up
+-- alert_filters: JSON array of the filters which must pass before an alert opens a position, empty - alerts are not filtered.
+ALTER TABLE trading_strategies
+    ADD COLUMN IF NOT EXISTS alert_filters TEXT NOT NULL DEFAULT '';
+
+-- +migrate Up
+-- filter_verdicts: JSON array of the verdicts of the strategy filters, empty if the alert was not filtered.
+ALTER TABLE alerts
+    ADD COLUMN IF NOT EXISTS filter_verdicts TEXT NOT NULL DEFAULT '';
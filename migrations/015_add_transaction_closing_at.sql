@@ -0,0 +1,4 @@
+-- +migrate Up
+-- closing_at: moment the close of the opened transaction was claimed, the claim prevents concurrent closes of the same position.
+ALTER TABLE transaction_table
+    ADD COLUMN IF NOT EXISTS closing_at TIMESTAMP;